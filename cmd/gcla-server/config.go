@@ -0,0 +1,117 @@
+// Copyright 2017 orijtech. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+
+	"github.com/orijtech/gcla/v3"
+)
+
+// ServerConfig declares how gcla-server receives and handles webhooks.
+type ServerConfig struct {
+	// Port is the port on which the server runs.
+	// If zero, the value of the "-port" flag is used.
+	Port int `json:"port,omitempty"`
+
+	// Secret is the secret configured on the hooks that deliver to
+	// this server. If set, deliveries whose signature doesn't match
+	// are rejected.
+	Secret string `json:"secret,omitempty"`
+
+	// Actions maps events to what the server does once they are received.
+	Actions []*EventAction `json:"actions,omitempty"`
+}
+
+// EventAction maps an event to the action taken when it is delivered.
+type EventAction struct {
+	Event  gcla.Event `json:"event"`
+	Action string     `json:"action"`
+
+	// ForwardURL is the URL to which the delivery's
+	// payload is POST-ed when Action is "forward".
+	ForwardURL string `json:"forward_url,omitempty"`
+}
+
+const (
+	// ActionLog logs the decoded event.
+	ActionLog = "log"
+	// ActionForward relays the delivery to ForwardURL.
+	ActionForward = "forward"
+)
+
+var errEmptyConfigPath = errors.New("expecting a non-empty config path")
+
+// LoadConfig reads and validates the JSON encoded ServerConfig at path.
+func LoadConfig(path string) (*ServerConfig, error) {
+	if path == "" {
+		return nil, errEmptyConfigPath
+	}
+	blob, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	cfg := new(ServerConfig)
+	if err := json.Unmarshal(blob, cfg); err != nil {
+		return nil, fmt.Errorf("parsing %q: %v", path, err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("validating %q: %v", path, err)
+	}
+	return cfg, nil
+}
+
+func (cfg *ServerConfig) Validate() error {
+	if cfg.Port < 0 || cfg.Port > 65535 {
+		return fmt.Errorf("port %d is out of range", cfg.Port)
+	}
+	for i, ea := range cfg.Actions {
+		if ea == nil {
+			return fmt.Errorf("actions[%d]: expecting a non-nil action", i)
+		}
+		if ea.Event == "" {
+			return fmt.Errorf("actions[%d]: expecting a non-empty event", i)
+		}
+		switch ea.Action {
+		case ActionLog:
+		case ActionForward:
+			u, err := url.Parse(ea.ForwardURL)
+			if err != nil {
+				return fmt.Errorf("actions[%d]: forward_url: %v", i, err)
+			}
+			if u.Scheme != "http" && u.Scheme != "https" || u.Host == "" {
+				return fmt.Errorf("actions[%d]: expecting an absolute http(s) forward_url, got %q", i, ea.ForwardURL)
+			}
+		default:
+			return fmt.Errorf("actions[%d]: unknown action %q, expecting either %q or %q", i, ea.Action, ActionLog, ActionForward)
+		}
+	}
+	return nil
+}
+
+// actionsFor returns the actions configured for event.
+func (cfg *ServerConfig) actionsFor(event gcla.Event) []*EventAction {
+	var actions []*EventAction
+	for _, ea := range cfg.Actions {
+		if ea.Event == event {
+			actions = append(actions, ea)
+		}
+	}
+	return actions
+}
@@ -0,0 +1,102 @@
+// Copyright 2017 orijtech. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/orijtech/gcla/v3"
+)
+
+func TestLoadConfig(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "gcla-server")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	tests := [...]struct {
+		body    string
+		wantErr bool
+	}{
+		0: {
+			body: `{
+  "port": 8080,
+  "secret": "s3cr3t",
+  "actions": [
+    {"event": "push", "action": "log"},
+    {"event": "pull_request", "action": "forward", "forward_url": "https://ci.orijtech.com/hooks"}
+  ]
+}`,
+		},
+		1: {body: `{"port": 70000}`, wantErr: true},
+		2: {body: `{"actions": [{"event": "push", "action": "explode"}]}`, wantErr: true},
+		3: {body: `{"actions": [{"event": "push", "action": "forward"}]}`, wantErr: true},
+		4: {body: `{"actions": [{"action": "log"}]}`, wantErr: true},
+		5: {body: `{"port": "8080"}`, wantErr: true},
+		6: {body: `{}`},
+	}
+
+	for i, tt := range tests {
+		path := filepath.Join(tmpDir, "config.json")
+		if err := ioutil.WriteFile(path, []byte(tt.body), 0600); err != nil {
+			t.Fatalf("#%d: %v", i, err)
+		}
+		cfg, err := LoadConfig(path)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("#%d: expected a non-nil error", i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("#%d: unexpected error: %v", i, err)
+			continue
+		}
+		if cfg == nil {
+			t.Errorf("#%d: expected a non-nil config", i)
+		}
+	}
+
+	if _, err := LoadConfig(filepath.Join(tmpDir, "non-existent.json")); err == nil {
+		t.Errorf("expected an error for a non-existent config")
+	}
+}
+
+func TestConfigActionsFor(t *testing.T) {
+	cfg := &ServerConfig{
+		Actions: []*EventAction{
+			{Event: gcla.EventPush, Action: ActionLog},
+			{Event: gcla.EventPullRequest, Action: ActionLog},
+			{Event: gcla.EventPush, Action: ActionForward, ForwardURL: "https://ci.orijtech.com/hooks"},
+		},
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	actions := cfg.actionsFor(gcla.EventPush)
+	if len(actions) != 2 {
+		t.Fatalf("got %d actions want 2", len(actions))
+	}
+	if g, w := actions[1].Action, ActionForward; g != w {
+		t.Errorf("action: got %q want %q", g, w)
+	}
+	if len(cfg.actionsFor(gcla.EventRelease)) != 0 {
+		t.Errorf("expected no actions for an unconfigured event")
+	}
+}
@@ -15,23 +15,42 @@
 package main
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"io/ioutil"
 	"log"
 	"net/http"
+	"time"
 
 	"github.com/orijtech/gcla/v3"
 )
 
 func main() {
 	var port int
+	var configPath string
 	flag.IntVar(&port, "port", 9889, "the port on which the server runs")
+	flag.StringVar(&configPath, "config", "", "the path to a JSON config file declaring the secret and event actions")
 	flag.Parse()
 
+	cfg := new(ServerConfig)
+	if configPath != "" {
+		var err error
+		if cfg, err = LoadConfig(configPath); err != nil {
+			log.Fatal(err)
+		}
+	}
+	// An explicit -port flag overrides the config file.
+	if cfg.Port != 0 && !isFlagSet("port") {
+		port = cfg.Port
+	}
+
 	addr := fmt.Sprintf(":%d", port)
-	http.HandleFunc("/", handleWebhooks)
+	srv := &server{cfg: cfg}
+	http.HandleFunc("/", srv.handleWebhooks)
 	http.HandleFunc("/ping", pong)
 
 	if err := http.ListenAndServe(addr, nil); err != nil {
@@ -39,7 +58,88 @@ func main() {
 	}
 }
 
-func handleWebhooks(w http.ResponseWriter, r *http.Request) {
+// isFlagSet reports whether the flag named name
+// was passed on the command line.
+func isFlagSet(name string) bool {
+	set := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
+
+type server struct {
+	cfg *ServerConfig
+}
+
+func (s *server) handleWebhooks(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+	blob, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if s.cfg.Secret != "" {
+		if err := gcla.VerifySignature(blob, r.Header.Get(gcla.HeaderSignature256), s.cfg.Secret); err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+	}
+
+	event := gcla.Event(r.Header.Get(gcla.HeaderEvent))
+	delivery := r.Header.Get(gcla.HeaderDelivery)
+	// The actions share a single deadline so that however
+	// many destinations there are, GitHub gets a timely reply.
+	ctx, cancel := context.WithTimeout(r.Context(), forwardTimeout)
+	defer cancel()
+	for _, ea := range s.cfg.actionsFor(event) {
+		switch ea.Action {
+		case ActionLog:
+			payload, err := gcla.ParseWebhook(event, blob)
+			if err != nil {
+				log.Printf("delivery %q: %v", delivery, err)
+				continue
+			}
+			log.Printf("delivery %q: %q event: %+v", delivery, event, payload)
+
+		case ActionForward:
+			if err := forward(ctx, ea.ForwardURL, r.Header, blob); err != nil {
+				log.Printf("delivery %q: forwarding to %q: %v", delivery, ea.ForwardURL, err)
+			}
+		}
+	}
+}
+
+// forwardTimeout bounds how long forwarding a delivery to all of its
+// destinations can hold up the reply to GitHub, which gives up on
+// deliveries after 10 seconds.
+var forwardTimeout = 8 * time.Second
+
+var forwardClient = &http.Client{Timeout: forwardTimeout}
+
+// forward POSTs the delivery to destURL, preserving GitHub's headers.
+func forward(ctx context.Context, destURL string, hdr http.Header, blob []byte) error {
+	req, err := http.NewRequest("POST", destURL, bytes.NewReader(blob))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	for _, key := range []string{"Content-Type", gcla.HeaderEvent, gcla.HeaderDelivery, gcla.HeaderSignature256} {
+		if value := hdr.Get(key); value != "" {
+			req.Header.Set(key, value)
+		}
+	}
+	res, err := forwardClient.Do(req)
+	if err != nil {
+		return err
+	}
+	res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return errors.New(res.Status)
+	}
+	return nil
 }
 
 func parseRequest(req *http.Request, savPtr interface{}) error {
@@ -52,15 +152,9 @@ func parseRequest(req *http.Request, savPtr interface{}) error {
 }
 
 func pong(w http.ResponseWriter, r *http.Request) {
-	pPayload := new(pingPayload)
+	pPayload := new(gcla.PingEvent)
 	if err := parseRequest(r, pPayload); err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 }
-
-type pingPayload struct {
-	Zen    string     `json:"zen,omitempty"`
-	HookID string     `json:"hook_id,omitempty"`
-	Hook   *gcla.Hook `json:"hook,omitempty"`
-}
@@ -0,0 +1,91 @@
+// Copyright 2017 orijtech. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/orijtech/gcla/v3"
+)
+
+func TestForward(t *testing.T) {
+	var got http.Header
+	dest := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header
+	}))
+	defer dest.Close()
+
+	hdr := make(http.Header)
+	hdr.Set(gcla.HeaderEvent, "push")
+	hdr.Set("Authorization", "token leaked")
+	if err := forward(context.Background(), dest.URL, hdr, []byte(`{}`)); err != nil {
+		t.Fatal(err)
+	}
+	if g, w := got.Get(gcla.HeaderEvent), "push"; g != w {
+		t.Errorf("event: got %q want %q", g, w)
+	}
+	if g := got.Get("Authorization"); g != "" {
+		t.Errorf("unexpected Authorization header %q", g)
+	}
+
+	// Forwarding stops with the request that it was made for.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := forward(ctx, dest.URL, hdr, []byte(`{}`)); err == nil {
+		t.Errorf("expected an error for a canceled context")
+	}
+}
+
+func TestHandleWebhooksForwardDeadline(t *testing.T) {
+	defer func(timeout time.Duration) { forwardTimeout = timeout }(forwardTimeout)
+	forwardTimeout = 50 * time.Millisecond
+
+	stalled := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer stalled.Close()
+	var reached int
+	next := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reached++
+	}))
+	defer next.Close()
+
+	srv := &server{cfg: &ServerConfig{Actions: []*EventAction{
+		{Event: gcla.EventPush, Action: ActionForward, ForwardURL: stalled.URL},
+		{Event: gcla.EventPush, Action: ActionForward, ForwardURL: next.URL},
+	}}}
+	req := httptest.NewRequest("POST", "/", strings.NewReader(`{}`))
+	req.Header.Set(gcla.HeaderEvent, string(gcla.EventPush))
+	rec := httptest.NewRecorder()
+
+	// The stalled destination uses up the deadline
+	// that the next destination would share.
+	start := time.Now()
+	srv.handleWebhooks(rec, req)
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("took %v to reply", elapsed)
+	}
+	if reached != 0 {
+		t.Errorf("the next destination was reached %d times after the deadline", reached)
+	}
+}
@@ -30,7 +30,6 @@ func Example_client_SubscribeToEvents() {
 		Repo:  "gcla",
 		Owner: "orijtech",
 		HookSubscription: &gcla.SubscribeRequest{
-			Name:   "test",
 			Active: true,
 			Events: []gcla.Event{
 				gcla.EventIssues,
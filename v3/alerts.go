@@ -0,0 +1,192 @@
+// Copyright 2017 orijtech. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gcla
+
+import (
+	"time"
+)
+
+// SecretScanningAlertEvent is the payload sent when webhook
+// "secret_scanning_alert" is fired. It is triggered when a secret
+// is found in a repository, or when such an alert is resolved,
+// reopened or revoked.
+type SecretScanningAlertEvent struct {
+	Action Action               `json:"action,omitempty"`
+	Alert  *SecretScanningAlert `json:"alert,omitempty"`
+
+	Repository   *Repository   `json:"repository,omitempty"`
+	Organization *Organization `json:"organization,omitempty"`
+	Sender       *User         `json:"sender,omitempty"`
+	Installation *Installation `json:"installation,omitempty"`
+}
+
+type SecretScanningAlert struct {
+	Number       uint64     `json:"number,omitempty"`
+	URL          string     `json:"url,omitempty"`
+	HTMLURL      string     `json:"html_url,omitempty"`
+	LocationsURL string     `json:"locations_url,omitempty"`
+	State        State      `json:"state,omitempty"`
+	CreatedAt    *time.Time `json:"created_at,omitempty"`
+	UpdatedAt    *time.Time `json:"updated_at,omitempty"`
+
+	// SecretType is the token's identifier e.g. "github_personal_access_token".
+	SecretType            string `json:"secret_type,omitempty"`
+	SecretTypeDisplayName string `json:"secret_type_display_name,omitempty"`
+
+	// Resolution is one of "false_positive", "wont_fix", "revoked",
+	// "used_in_tests" or empty if the alert is open.
+	Resolution string     `json:"resolution,omitempty"`
+	ResolvedAt *time.Time `json:"resolved_at,omitempty"`
+	ResolvedBy *User      `json:"resolved_by,omitempty"`
+
+	PushProtectionBypassed   bool       `json:"push_protection_bypassed,omitempty"`
+	PushProtectionBypassedBy *User      `json:"push_protection_bypassed_by,omitempty"`
+	PushProtectionBypassedAt *time.Time `json:"push_protection_bypassed_at,omitempty"`
+}
+
+// RepositoryVulnerabilityAlertEvent is the payload sent when webhook
+// "repository_vulnerability_alert" is fired. It is triggered when a
+// vulnerable dependency is found in a repository, and when that alert
+// is dismissed or resolved. Its actions are ActionCreate, ActionDismiss,
+// ActionReopen and ActionResolve.
+type RepositoryVulnerabilityAlertEvent struct {
+	Action Action              `json:"action,omitempty"`
+	Alert  *VulnerabilityAlert `json:"alert,omitempty"`
+
+	Repository   *Repository   `json:"repository,omitempty"`
+	Organization *Organization `json:"organization,omitempty"`
+	Sender       *User         `json:"sender,omitempty"`
+	Installation *Installation `json:"installation,omitempty"`
+}
+
+type VulnerabilityAlert struct {
+	ID     uint64 `json:"id,omitempty"`
+	Number uint64 `json:"number,omitempty"`
+	State  State  `json:"state,omitempty"`
+
+	AffectedRange       string `json:"affected_range,omitempty"`
+	AffectedPackageName string `json:"affected_package_name,omitempty"`
+	FixedIn             string `json:"fixed_in,omitempty"`
+
+	ExternalReference  string `json:"external_reference,omitempty"`
+	ExternalIdentifier string `json:"external_identifier,omitempty"`
+	GHSAID             string `json:"ghsa_id,omitempty"`
+	Severity           string `json:"severity,omitempty"`
+
+	CreatedAt     *time.Time `json:"created_at,omitempty"`
+	Dismisser     *User      `json:"dismisser,omitempty"`
+	DismissReason string     `json:"dismiss_reason,omitempty"`
+	DismissedAt   *time.Time `json:"dismissed_at,omitempty"`
+	FixedAt       *time.Time `json:"fixed_at,omitempty"`
+}
+
+// DependabotAlertEvent is the payload sent when webhook "dependabot_alert"
+// is fired. It is triggered when Dependabot finds a vulnerable dependency,
+// and when such an alert is dismissed, fixed, reopened or reintroduced.
+type DependabotAlertEvent struct {
+	Action Action           `json:"action,omitempty"`
+	Alert  *DependabotAlert `json:"alert,omitempty"`
+
+	Repository   *Repository   `json:"repository,omitempty"`
+	Organization *Organization `json:"organization,omitempty"`
+	Sender       *User         `json:"sender,omitempty"`
+	Installation *Installation `json:"installation,omitempty"`
+}
+
+type DependabotAlert struct {
+	Number  uint64 `json:"number,omitempty"`
+	State   State  `json:"state,omitempty"`
+	URL     string `json:"url,omitempty"`
+	HTMLURL string `json:"html_url,omitempty"`
+
+	Dependency            *Dependency            `json:"dependency,omitempty"`
+	SecurityAdvisory      *SecurityAdvisory      `json:"security_advisory,omitempty"`
+	SecurityVulnerability *SecurityVulnerability `json:"security_vulnerability,omitempty"`
+
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+
+	DismissedAt *time.Time `json:"dismissed_at,omitempty"`
+	DismissedBy *User      `json:"dismissed_by,omitempty"`
+	// DismissedReason is one of "fix_started", "inaccurate",
+	// "no_bandwidth", "not_used" or "tolerable_risk".
+	DismissedReason  string     `json:"dismissed_reason,omitempty"`
+	DismissedComment string     `json:"dismissed_comment,omitempty"`
+	FixedAt          *time.Time `json:"fixed_at,omitempty"`
+	AutoDismissedAt  *time.Time `json:"auto_dismissed_at,omitempty"`
+}
+
+// Dependency is the vulnerable dependency that a DependabotAlert is about.
+type Dependency struct {
+	Package      *Package `json:"package,omitempty"`
+	ManifestPath string   `json:"manifest_path,omitempty"`
+	// Scope is either "development" or "runtime".
+	Scope string `json:"scope,omitempty"`
+}
+
+type Package struct {
+	// Ecosystem is the package manager e.g. "go" or "npm".
+	Ecosystem string `json:"ecosystem,omitempty"`
+	Name      string `json:"name,omitempty"`
+}
+
+type SecurityAdvisory struct {
+	GHSAID      string `json:"ghsa_id,omitempty"`
+	CVEID       string `json:"cve_id,omitempty"`
+	Summary     string `json:"summary,omitempty"`
+	Description string `json:"description,omitempty"`
+	Severity    string `json:"severity,omitempty"`
+
+	Identifiers     []*AdvisoryIdentifier    `json:"identifiers,omitempty"`
+	References      []*AdvisoryReference     `json:"references,omitempty"`
+	Vulnerabilities []*SecurityVulnerability `json:"vulnerabilities,omitempty"`
+	CVSS            *CVSS                    `json:"cvss,omitempty"`
+	CWEs            []*CWE                   `json:"cwes,omitempty"`
+
+	PublishedAt *time.Time `json:"published_at,omitempty"`
+	UpdatedAt   *time.Time `json:"updated_at,omitempty"`
+	WithdrawnAt *time.Time `json:"withdrawn_at,omitempty"`
+}
+
+type AdvisoryIdentifier struct {
+	// Type is either "CVE" or "GHSA".
+	Type  string `json:"type,omitempty"`
+	Value string `json:"value,omitempty"`
+}
+
+type AdvisoryReference struct {
+	URL string `json:"url,omitempty"`
+}
+
+type CVSS struct {
+	VectorString string  `json:"vector_string,omitempty"`
+	Score        float64 `json:"score,omitempty"`
+}
+
+type CWE struct {
+	CWEID string `json:"cwe_id,omitempty"`
+	Name  string `json:"name,omitempty"`
+}
+
+type SecurityVulnerability struct {
+	Package                *Package        `json:"package,omitempty"`
+	Severity               string          `json:"severity,omitempty"`
+	VulnerableVersionRange string          `json:"vulnerable_version_range,omitempty"`
+	FirstPatchedVersion    *PatchedVersion `json:"first_patched_version,omitempty"`
+}
+
+type PatchedVersion struct {
+	Identifier string `json:"identifier,omitempty"`
+}
@@ -0,0 +1,231 @@
+// Copyright 2017 orijtech. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gcla
+
+import (
+	"testing"
+)
+
+const secretScanningAlertPayload = `{
+  "action": "created",
+  "alert": {
+    "number": 3,
+    "created_at": "2022-11-21T18:29:54Z",
+    "updated_at": "2022-11-21T18:29:54Z",
+    "url": "https://api.github.com/repos/orijtech/gcla/secret-scanning/alerts/3",
+    "html_url": "https://github.com/orijtech/gcla/security/secret-scanning/3",
+    "locations_url": "https://api.github.com/repos/orijtech/gcla/secret-scanning/alerts/3/locations",
+    "state": "open",
+    "resolution": null,
+    "resolved_at": null,
+    "resolved_by": null,
+    "secret_type": "github_personal_access_token",
+    "secret_type_display_name": "GitHub Personal Access Token",
+    "push_protection_bypassed": false,
+    "push_protection_bypassed_by": null,
+    "push_protection_bypassed_at": null
+  },
+  "repository": {"id": 35129377, "full_name": "orijtech/gcla"},
+  "organization": {"login": "orijtech", "id": 25489431},
+  "sender": {"login": "github", "id": 9919, "type": "Organization"}
+}`
+
+const repositoryVulnerabilityAlertPayload = `{
+  "action": "create",
+  "alert": {
+    "id": 91095730,
+    "number": 1,
+    "state": "open",
+    "affected_range": "< 0.17.0",
+    "affected_package_name": "golang.org/x/net",
+    "external_reference": "https://nvd.nist.gov/vuln/detail/CVE-2023-39325",
+    "external_identifier": "CVE-2023-39325",
+    "ghsa_id": "GHSA-4374-p667-p6c8",
+    "severity": "high",
+    "created_at": "2023-10-11T22:18:01Z",
+    "fixed_in": "0.17.0"
+  },
+  "repository": {"id": 35129377, "full_name": "orijtech/gcla"},
+  "sender": {"login": "github", "id": 9919, "type": "Organization"}
+}`
+
+func TestParseSecretScanningAlertEvent(t *testing.T) {
+	got, err := ParseWebhook(EventSecretScanningAlert, []byte(secretScanningAlertPayload), DisallowUnknownFields())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ev, ok := got.(*SecretScanningAlertEvent)
+	if !ok {
+		t.Fatalf("got %T want *SecretScanningAlertEvent", got)
+	}
+	if g, w := ev.Action, ActionCreated; g != w {
+		t.Errorf("action: got %q want %q", g, w)
+	}
+	alert := ev.Alert
+	if alert == nil {
+		t.Fatal("expected a non-nil alert")
+	}
+	if g, w := alert.Number, uint64(3); g != w {
+		t.Errorf("number: got %d want %d", g, w)
+	}
+	if g, w := alert.State, StateOpen; g != w {
+		t.Errorf("state: got %q want %q", g, w)
+	}
+	if g, w := alert.SecretType, "github_personal_access_token"; g != w {
+		t.Errorf("secret_type: got %q want %q", g, w)
+	}
+	if alert.CreatedAt == nil || alert.CreatedAt.Year() != 2022 {
+		t.Errorf("created_at: got %v", alert.CreatedAt)
+	}
+	if alert.ResolvedBy != nil || alert.ResolvedAt != nil {
+		t.Errorf("expected an unresolved alert, got %#v", alert)
+	}
+	if ev.Repository == nil || ev.Repository.FullName != "orijtech/gcla" {
+		t.Errorf("repository: got %#v", ev.Repository)
+	}
+	if ev.Sender == nil || ev.Sender.Username != "github" {
+		t.Errorf("sender: got %#v", ev.Sender)
+	}
+}
+
+func TestParseRepositoryVulnerabilityAlertEvent(t *testing.T) {
+	got, err := ParseWebhook(EventRepositoryVulnerabilityAlert, []byte(repositoryVulnerabilityAlertPayload), DisallowUnknownFields())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ev, ok := got.(*RepositoryVulnerabilityAlertEvent)
+	if !ok {
+		t.Fatalf("got %T want *RepositoryVulnerabilityAlertEvent", got)
+	}
+	if g, w := ev.Action, ActionCreate; g != w {
+		t.Errorf("action: got %q want %q", g, w)
+	}
+	alert := ev.Alert
+	if alert == nil {
+		t.Fatal("expected a non-nil alert")
+	}
+	if g, w := alert.AffectedPackageName, "golang.org/x/net"; g != w {
+		t.Errorf("affected_package_name: got %q want %q", g, w)
+	}
+	if g, w := alert.GHSAID, "GHSA-4374-p667-p6c8"; g != w {
+		t.Errorf("ghsa_id: got %q want %q", g, w)
+	}
+	if g, w := alert.FixedIn, "0.17.0"; g != w {
+		t.Errorf("fixed_in: got %q want %q", g, w)
+	}
+	if ev.Repository == nil || ev.Repository.FullName != "orijtech/gcla" {
+		t.Errorf("repository: got %#v", ev.Repository)
+	}
+}
+
+const dependabotAlertPayload = `{
+  "action": "created",
+  "alert": {
+    "number": 2,
+    "state": "open",
+    "dependency": {
+      "package": {"ecosystem": "go", "name": "golang.org/x/net"},
+      "manifest_path": "go.mod",
+      "scope": "runtime"
+    },
+    "security_advisory": {
+      "ghsa_id": "GHSA-4374-p667-p6c8",
+      "cve_id": "CVE-2023-39325",
+      "summary": "HTTP/2 rapid reset can cause excessive work in net/http",
+      "description": "A malicious HTTP/2 client which rapidly creates requests and immediately resets them can cause excessive server resource consumption.",
+      "severity": "high",
+      "identifiers": [
+        {"value": "GHSA-4374-p667-p6c8", "type": "GHSA"},
+        {"value": "CVE-2023-39325", "type": "CVE"}
+      ],
+      "references": [{"url": "https://nvd.nist.gov/vuln/detail/CVE-2023-39325"}],
+      "published_at": "2023-10-11T22:18:01Z",
+      "updated_at": "2023-10-20T17:52:07Z",
+      "withdrawn_at": null,
+      "vulnerabilities": [
+        {
+          "package": {"ecosystem": "go", "name": "golang.org/x/net"},
+          "severity": "high",
+          "vulnerable_version_range": "< 0.17.0",
+          "first_patched_version": {"identifier": "0.17.0"}
+        }
+      ],
+      "cvss": {"vector_string": "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:N/I:N/A:H", "score": 7.5},
+      "cwes": [{"cwe_id": "CWE-400", "name": "Uncontrolled Resource Consumption"}]
+    },
+    "security_vulnerability": {
+      "package": {"ecosystem": "go", "name": "golang.org/x/net"},
+      "severity": "high",
+      "vulnerable_version_range": "< 0.17.0",
+      "first_patched_version": {"identifier": "0.17.0"}
+    },
+    "url": "https://api.github.com/repos/orijtech/gcla/dependabot/alerts/2",
+    "html_url": "https://github.com/orijtech/gcla/security/dependabot/2",
+    "created_at": "2023-10-12T08:00:00Z",
+    "updated_at": "2023-10-12T08:00:00Z",
+    "dismissed_at": null,
+    "dismissed_by": null,
+    "dismissed_reason": null,
+    "dismissed_comment": null,
+    "fixed_at": null,
+    "auto_dismissed_at": null
+  },
+  "repository": {"id": 35129377, "full_name": "orijtech/gcla"},
+  "organization": {"login": "orijtech", "id": 25489431},
+  "sender": {"login": "dependabot[bot]", "id": 49699333, "type": "Bot"}
+}`
+
+func TestParseDependabotAlertEvent(t *testing.T) {
+	got, err := ParseWebhook(EventDependabotAlert, []byte(dependabotAlertPayload), DisallowUnknownFields())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ev, ok := got.(*DependabotAlertEvent)
+	if !ok {
+		t.Fatalf("got %T want *DependabotAlertEvent", got)
+	}
+	if g, w := ev.Action, ActionCreated; g != w {
+		t.Errorf("action: got %q want %q", g, w)
+	}
+	alert := ev.Alert
+	if alert == nil {
+		t.Fatal("expected a non-nil alert")
+	}
+	if g, w := alert.State, StateOpen; g != w {
+		t.Errorf("state: got %q want %q", g, w)
+	}
+	if alert.Dependency == nil || alert.Dependency.Package == nil {
+		t.Fatalf("dependency: got %#v", alert.Dependency)
+	}
+	if g, w := alert.Dependency.Package.Name, "golang.org/x/net"; g != w {
+		t.Errorf("dependency package: got %q want %q", g, w)
+	}
+	if g, w := alert.Dependency.ManifestPath, "go.mod"; g != w {
+		t.Errorf("manifest_path: got %q want %q", g, w)
+	}
+	if sa := alert.SecurityAdvisory; sa == nil || sa.CVEID != "CVE-2023-39325" || len(sa.Identifiers) != 2 || sa.CVSS == nil || sa.CVSS.Score != 7.5 {
+		t.Errorf("security_advisory: got %#v", sa)
+	}
+	sv := alert.SecurityVulnerability
+	if sv == nil || sv.FirstPatchedVersion == nil || sv.FirstPatchedVersion.Identifier != "0.17.0" {
+		t.Errorf("security_vulnerability: got %#v", sv)
+	}
+	if alert.DismissedAt != nil || alert.DismissedReason != "" {
+		t.Errorf("expected an undismissed alert, got %#v", alert)
+	}
+	if ev.Sender == nil || ev.Sender.Type != TypeBot {
+		t.Errorf("sender: got %#v", ev.Sender)
+	}
+}
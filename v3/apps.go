@@ -0,0 +1,127 @@
+// Copyright 2017 orijtech. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gcla
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// AppSigner signs the JSON Web Tokens, JWTs, that authenticate
+// requests as a GitHub App rather than as one of its installations.
+type AppSigner interface {
+	// SignAppJWT returns a JWT that is valid from issuedAt until expiresAt.
+	SignAppJWT(issuedAt, expiresAt time.Time) (string, error)
+}
+
+// WithAppSigner makes a Client authenticate as a GitHub App with the JWTs
+// that signer signs, for the endpoints that require it such as
+// ListInstallations. Other requests still use the Client's API key.
+func WithAppSigner(signer AppSigner) ClientOption {
+	return func(c *Client) {
+		c.appSigner = signer
+	}
+}
+
+// NewAppSigner returns an AppSigner for the GitHub App appID,
+// which signs with key, the private key generated for the App.
+func NewAppSigner(appID string, key *rsa.PrivateKey) AppSigner {
+	return &rsaAppSigner{appID: appID, key: key}
+}
+
+type rsaAppSigner struct {
+	appID string
+	key   *rsa.PrivateKey
+}
+
+var _ AppSigner = (*rsaAppSigner)(nil)
+
+// jwtHeader is the encoded header of the RS256 JWTs that GitHub expects.
+var jwtHeader = base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"RS256","typ":"JWT"}`))
+
+func (ras *rsaAppSigner) SignAppJWT(issuedAt, expiresAt time.Time) (string, error) {
+	claims, err := json.Marshal(struct {
+		IssuedAt  int64  `json:"iat"`
+		ExpiresAt int64  `json:"exp"`
+		Issuer    string `json:"iss"`
+	}{issuedAt.Unix(), expiresAt.Unix(), ras.appID})
+	if err != nil {
+		return "", err
+	}
+	signingInput := jwtHeader + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(signingInput))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, ras.key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", err
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(sig), nil
+}
+
+// GitHub rejects JWTs that expire more than 10 minutes after they
+// were issued. They are backdated to allow for clock drift.
+const (
+	appJWTBackdate = time.Minute
+	appJWTLifetime = 9 * time.Minute
+)
+
+var errNoAppSigner = errors.New("authenticating as a GitHub App requires an AppSigner, see WithAppSigner")
+
+// appAuthorization returns the "Authorization"
+// header that authenticates as the GitHub App.
+func (c *Client) appAuthorization() (string, error) {
+	c.mu.RLock()
+	signer := c.appSigner
+	c.mu.RUnlock()
+	if signer == nil {
+		return "", errNoAppSigner
+	}
+	now := c.now()
+	jwt, err := signer.SignAppJWT(now.Add(-appJWTBackdate), now.Add(appJWTLifetime))
+	if err != nil {
+		return "", fmt.Errorf("signing the GitHub App's JWT: %v", err)
+	}
+	return "Bearer " + jwt, nil
+}
+
+// ListInstallations returns the installations of the GitHub App that
+// the Client authenticates as, following pagination. It requires
+// WithAppSigner.
+func (c *Client) ListInstallations() ([]*Installation, error) {
+	authorization, err := c.appAuthorization()
+	if err != nil {
+		return nil, err
+	}
+	var installations []*Installation
+	err = c.getAllPagesAuthorized(context.Background(), baseURL+"/app/installations", authorization, func(blob []byte) error {
+		var page []*Installation
+		if err := json.Unmarshal(blob, &page); err != nil {
+			return err
+		}
+		installations = append(installations, page...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return installations, nil
+}
@@ -0,0 +1,85 @@
+// Copyright 2017 orijtech. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gcla
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+const (
+	defaultBackoffBase = 100 * time.Millisecond
+	defaultBackoffMax  = 30 * time.Second
+)
+
+// Backoff computes delays between successive attempts of an operation.
+// The ceiling for each delay doubles from Base on every attempt until it
+// reaches Max and the returned delay is drawn uniformly from [0, ceiling],
+// which is the "full jitter" strategy and keeps concurrent callers from
+// retrying in lockstep. The zero value is ready to use and is safe for
+// concurrent use. WaitForCheckRun and WaitForRateLimit retry with it.
+type Backoff struct {
+	// Base is the ceiling for the first delay.
+	// If zero, it defaults to 100ms.
+	Base time.Duration
+	// Max caps the ceiling of every delay.
+	// If zero, it defaults to 30s.
+	Max time.Duration
+
+	mu      sync.Mutex
+	attempt uint
+}
+
+// Next returns the delay to wait before the next attempt.
+func (b *Backoff) Next() time.Duration {
+	b.mu.Lock()
+	attempt := b.attempt
+	b.attempt++
+	b.mu.Unlock()
+
+	ceiling := b.ceiling(attempt)
+	return time.Duration(rand.Int63n(int64(ceiling) + 1))
+}
+
+// Reset restarts the backoff from Base, typically after a success.
+func (b *Backoff) Reset() {
+	b.mu.Lock()
+	b.attempt = 0
+	b.mu.Unlock()
+}
+
+func (b *Backoff) ceiling(attempt uint) time.Duration {
+	base, max := b.Base, b.Max
+	if base <= 0 {
+		base = defaultBackoffBase
+	}
+	if max <= 0 {
+		max = defaultBackoffMax
+	}
+	if base > max {
+		return max
+	}
+	// Shift one step at a time to catch the
+	// ceiling before it can overflow.
+	ceiling := base
+	for i := uint(0); i < attempt; i++ {
+		if ceiling > max/2 {
+			return max
+		}
+		ceiling *= 2
+	}
+	return ceiling
+}
@@ -0,0 +1,84 @@
+// Copyright 2017 orijtech. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gcla
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBackoffBounds(t *testing.T) {
+	b := &Backoff{Base: 10 * time.Millisecond, Max: 200 * time.Millisecond}
+	wantCeilings := []time.Duration{
+		10 * time.Millisecond,
+		20 * time.Millisecond,
+		40 * time.Millisecond,
+		80 * time.Millisecond,
+		160 * time.Millisecond,
+		200 * time.Millisecond,
+		200 * time.Millisecond,
+	}
+	for i, ceiling := range wantCeilings {
+		if got := b.Next(); got < 0 || got > ceiling {
+			t.Errorf("#%d: delay %v is outside of [0, %v]", i, got, ceiling)
+		}
+	}
+
+	b.Reset()
+	if got := b.Next(); got > 10*time.Millisecond {
+		t.Errorf("after Reset: delay %v exceeds Base", got)
+	}
+
+	// Many attempts must neither overflow nor exceed Max.
+	for i := 0; i < 200; i++ {
+		if got := b.Next(); got < 0 || got > b.Max {
+			t.Fatalf("attempt #%d: delay %v is outside of [0, %v]", i, got, b.Max)
+		}
+	}
+}
+
+func TestBackoffZeroValueDefaults(t *testing.T) {
+	var b Backoff
+	if got := b.Next(); got > defaultBackoffBase {
+		t.Errorf("first delay %v exceeds the default base %v", got, defaultBackoffBase)
+	}
+}
+
+func TestBackoffJitterDistribution(t *testing.T) {
+	const n = 5000
+	ceiling := time.Second
+
+	var sum time.Duration
+	var belowHalf int
+	for i := 0; i < n; i++ {
+		// Base == Max keeps the ceiling fixed across draws.
+		b := &Backoff{Base: ceiling, Max: ceiling}
+		d := b.Next()
+		sum += d
+		if d < ceiling/2 {
+			belowHalf++
+		}
+	}
+
+	// Full jitter draws uniformly from [0, ceiling] so the mean should
+	// sit near ceiling/2 and about half the draws should fall below it.
+	mean := sum / n
+	if mean < 400*time.Millisecond || mean > 600*time.Millisecond {
+		t.Errorf("mean delay %v is too far from %v", mean, ceiling/2)
+	}
+	if ratio := float64(belowHalf) / n; ratio < 0.4 || ratio > 0.6 {
+		t.Errorf("%.2f of delays fell below half the ceiling, expected roughly 0.5", ratio)
+	}
+}
@@ -0,0 +1,205 @@
+// Copyright 2017 orijtech. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gcla
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+var (
+	errEmptyCheckSuiteID = errors.New("expecting a non-zero check suite ID")
+	errEmptyCheckRunID   = errors.New("expecting a non-zero check run ID")
+	errEmptyCheckRunName = errors.New("expecting a non-empty check run name")
+	errEmptyRef          = errors.New("expecting a non-empty ref")
+)
+
+// CheckStatus is the status of a check run or check suite.
+type CheckStatus string
+
+const (
+	CheckStatusQueued     CheckStatus = "queued"
+	CheckStatusInProgress CheckStatus = "in_progress"
+	CheckStatusCompleted  CheckStatus = "completed"
+)
+
+// CheckRun is a single check, such as a CI job,
+// that a GitHub App ran against a commit.
+type CheckRun struct {
+	ID         uint64      `json:"id,omitempty"`
+	HeadSHA    string      `json:"head_sha,omitempty"`
+	ExternalID string      `json:"external_id,omitempty"`
+	Name       string      `json:"name,omitempty"`
+	Status     CheckStatus `json:"status,omitempty"`
+	// Conclusion is only set once Status is CheckStatusCompleted and is
+	// one of "success", "failure", "neutral", "cancelled", "skipped",
+	// "timed_out" or "action_required".
+	Conclusion  string     `json:"conclusion,omitempty"`
+	URL         string     `json:"url,omitempty"`
+	HTMLURL     string     `json:"html_url,omitempty"`
+	DetailsURL  string     `json:"details_url,omitempty"`
+	StartedAt   *time.Time `json:"started_at,omitempty"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+// checkRunList is the object that wraps
+// the check runs listed by GitHub.
+type checkRunList struct {
+	TotalCount uint64      `json:"total_count"`
+	CheckRuns  []*CheckRun `json:"check_runs"`
+}
+
+// ListCheckRunsForRef returns the check runs of ref, a SHA,
+// branch or tag name, of owner/repo, following pagination.
+func (c *Client) ListCheckRunsForRef(owner, repo, ref string) ([]*CheckRun, error) {
+	return c.listCheckRunsForRef(context.Background(), owner, repo, ref, "")
+}
+
+// ListCheckRunsByName is like ListCheckRunsForRef but
+// only returns the check runs named name.
+func (c *Client) ListCheckRunsByName(owner, repo, ref, name string) ([]*CheckRun, error) {
+	if name == "" {
+		return nil, errEmptyCheckRunName
+	}
+	return c.listCheckRunsForRef(context.Background(), owner, repo, ref, name)
+}
+
+func (c *Client) listCheckRunsForRef(ctx context.Context, owner, repo, ref, name string) ([]*CheckRun, error) {
+	if owner == "" {
+		return nil, errEmptyOwner
+	}
+	if repo == "" {
+		return nil, errEmptyRepo
+	}
+	if ref == "" {
+		return nil, errEmptyRef
+	}
+	fullURL := fmt.Sprintf("%s/repos/%s/%s/commits/%s/check-runs", baseURL, owner, repo, ref)
+	if name != "" {
+		fullURL += "?" + url.Values{"check_name": {name}}.Encode()
+	}
+
+	var runs []*CheckRun
+	err := c.getAllPagesContext(ctx, fullURL, func(blob []byte) error {
+		page := new(checkRunList)
+		if err := json.Unmarshal(blob, page); err != nil {
+			return err
+		}
+		runs = append(runs, page.CheckRuns...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return runs, nil
+}
+
+// DefaultCheckRunPoll is how often WaitForCheckRun
+// polls GitHub if it is given a non-positive interval.
+const DefaultCheckRunPoll = 10 * time.Second
+
+// WaitForCheckRun polls the check runs of sha every poll until the one
+// named name is completed, and then returns it. It keeps polling while
+// no such check run exists yet, since CI usually creates it a while
+// after a push. 5XX responses are retried with a jittered Backoff that
+// never waits longer than poll, other errors are returned right away.
+// It returns ctx's error as soon as ctx is done, even mid-request.
+func (c *Client) WaitForCheckRun(ctx context.Context, owner, repo, sha, name string, poll time.Duration) (*CheckRun, error) {
+	if sha == "" {
+		return nil, errEmptySHA
+	}
+	if name == "" {
+		return nil, errEmptyCheckRunName
+	}
+	if poll <= 0 {
+		poll = DefaultCheckRunPoll
+	}
+
+	backoff := &Backoff{Max: poll}
+	for {
+		wait := poll
+		runs, err := c.listCheckRunsForRef(ctx, owner, repo, sha, name)
+		switch {
+		case isServerError(err):
+			wait = backoff.Next()
+		case err != nil:
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			return nil, err
+		default:
+			backoff.Reset()
+			// GitHub lists the most recent check run first.
+			for _, run := range runs {
+				if run.Name == name {
+					if run.Status == CheckStatusCompleted {
+						return run, nil
+					}
+					break
+				}
+			}
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// RerequestCheckSuite makes GitHub send a "check_suite" event with
+// action "rerequested" to the GitHub App that created the check suite,
+// which is how CI is re-run through the Checks API. It requires the
+// token of a GitHub App with the "checks:write" permission.
+func (c *Client) RerequestCheckSuite(owner, repo string, checkSuiteID uint64) error {
+	if checkSuiteID == 0 {
+		return errEmptyCheckSuiteID
+	}
+	return c.rerequest(owner, repo, "check-suites", checkSuiteID)
+}
+
+// RerequestCheckRun is like RerequestCheckSuite but for a single check run,
+// for which GitHub sends a "check_run" event with action "rerequested".
+func (c *Client) RerequestCheckRun(owner, repo string, checkRunID uint64) error {
+	if checkRunID == 0 {
+		return errEmptyCheckRunID
+	}
+	return c.rerequest(owner, repo, "check-runs", checkRunID)
+}
+
+func (c *Client) rerequest(owner, repo, kind string, id uint64) error {
+	if owner == "" {
+		return errEmptyOwner
+	}
+	if repo == "" {
+		return errEmptyRepo
+	}
+	fullURL := fmt.Sprintf("%s/repos/%s/%s/%s/%d/rerequest", baseURL, owner, repo, kind, id)
+	req, err := http.NewRequest("POST", fullURL, nil)
+	if err != nil {
+		return err
+	}
+	// GitHub responds with "201 Created" and an empty body.
+	_, _, err = c.doHTTPReq(req)
+	return err
+}
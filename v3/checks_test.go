@@ -0,0 +1,247 @@
+// Copyright 2017 orijtech. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gcla
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRerequestChecks(t *testing.T) {
+	var got []string
+	client := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		got = append(got, r.Method+" "+r.URL.Path)
+		if r.URL.Path == "/repos/orijtech/gcla/check-runs/404/rerequest" {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			fmt.Fprint(w, `{"message":"This check run is not rerequestable"}`)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{}`)
+	})
+
+	if err := client.RerequestCheckSuite("orijtech", "gcla", 5040436049); err != nil {
+		t.Errorf("RerequestCheckSuite: %v", err)
+	}
+	if err := client.RerequestCheckRun("orijtech", "gcla", 4); err != nil {
+		t.Errorf("RerequestCheckRun: %v", err)
+	}
+	err := client.RerequestCheckRun("orijtech", "gcla", 404)
+	if !isStatusCode(err, http.StatusUnprocessableEntity) {
+		t.Errorf("got err=%v want a 422 *APIError", err)
+	}
+
+	want := []string{
+		"POST /repos/orijtech/gcla/check-suites/5040436049/rerequest",
+		"POST /repos/orijtech/gcla/check-runs/4/rerequest",
+		"POST /repos/orijtech/gcla/check-runs/404/rerequest",
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("requests:\ngot:  %q\nwant: %q", got, want)
+	}
+
+	for i, err := range []error{
+		client.RerequestCheckSuite("orijtech", "gcla", 0),
+		client.RerequestCheckRun("orijtech", "gcla", 0),
+		client.RerequestCheckSuite("", "gcla", 1),
+		client.RerequestCheckRun("orijtech", "", 1),
+	} {
+		if err == nil {
+			t.Errorf("#%d: expected a non-nil error", i)
+		}
+	}
+	if len(got) != len(want) {
+		t.Errorf("invalid arguments still sent %d requests", len(got)-len(want))
+	}
+}
+
+func TestWaitForCheckRun(t *testing.T) {
+	var polls int
+	client := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		if g, w := r.URL.Path, "/repos/orijtech/gcla/commits/aa218f56b14c9653891f9e74264a383fa43fefbd/check-runs"; g != w {
+			t.Errorf("path: got %q want %q", g, w)
+		}
+		if g, w := r.URL.Query().Get("check_name"), "test"; g != w {
+			t.Errorf("check_name: got %q want %q", g, w)
+		}
+		polls++
+		status, conclusion := "in_progress", ""
+		if polls > 1 {
+			status, conclusion = "completed", "success"
+		}
+		fmt.Fprintf(w, `{"total_count": 1, "check_runs": [{"id": 4, "name": "test", "status": %q, "conclusion": %q}]}`, status, conclusion)
+	})
+
+	run, err := client.WaitForCheckRun(context.Background(), "orijtech", "gcla", "aa218f56b14c9653891f9e74264a383fa43fefbd", "test", time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g, w := polls, 2; g != w {
+		t.Errorf("polls: got %d want %d", g, w)
+	}
+	if g, w := run.Status, CheckStatusCompleted; g != w {
+		t.Errorf("status: got %q want %q", g, w)
+	}
+	if g, w := run.Conclusion, "success"; g != w {
+		t.Errorf("conclusion: got %q want %q", g, w)
+	}
+}
+
+func TestWaitForCheckRunContextDone(t *testing.T) {
+	client := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		// The check run hasn't been created yet.
+		fmt.Fprint(w, `{"total_count": 0, "check_runs": []}`)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	run, err := client.WaitForCheckRun(ctx, "orijtech", "gcla", "aa218f5", "test", time.Millisecond)
+	if err != context.DeadlineExceeded {
+		t.Errorf("got err=%v want %v", err, context.DeadlineExceeded)
+	}
+	if run != nil {
+		t.Errorf("unexpected check run %#v", run)
+	}
+
+	if _, err := client.WaitForCheckRun(ctx, "orijtech", "gcla", "", "test", 0); err == nil {
+		t.Errorf("expected an error for an empty SHA")
+	}
+	if _, err := client.WaitForCheckRun(ctx, "orijtech", "gcla", "aa218f5", "", 0); err == nil {
+		t.Errorf("expected an error for an empty check run name")
+	}
+}
+
+func TestWaitForCheckRunStalled(t *testing.T) {
+	client := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		// GitHub hangs until the request is abandoned.
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+			t.Error("the request outlived its context")
+		}
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := client.WaitForCheckRun(ctx, "orijtech", "gcla", "aa218f5", "test", time.Millisecond)
+	if err != context.DeadlineExceeded {
+		t.Errorf("got err=%v want %v", err, context.DeadlineExceeded)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("took %v to return after the deadline", elapsed)
+	}
+}
+
+func TestWaitForCheckRunRetries(t *testing.T) {
+	tests := [...]struct {
+		statuses  []int
+		wantPolls int
+		wantErr   bool
+	}{
+		// 5XX responses are retried.
+		0: {statuses: []int{http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusOK}, wantPolls: 3},
+		// Others end the wait.
+		1: {statuses: []int{http.StatusNotFound, http.StatusOK}, wantPolls: 1, wantErr: true},
+	}
+
+	for i, tt := range tests {
+		polls := 0
+		client := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+			status := tt.statuses[polls]
+			polls++
+			if status != http.StatusOK {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(status)
+				fmt.Fprint(w, `{"message": "try again"}`)
+				return
+			}
+			fmt.Fprint(w, `{"total_count": 1, "check_runs": [{"id": 4, "name": "test", "status": "completed"}]}`)
+		})
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		// Waiting out the poll after a 5XX response
+		// instead of backing off would time out.
+		run, err := client.WaitForCheckRun(ctx, "orijtech", "gcla", "aa218f5", "test", time.Hour)
+		cancel()
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("#%d: expected an error", i)
+			}
+		} else if err != nil {
+			t.Errorf("#%d: unexpected error: %v", i, err)
+		} else if run.ID != 4 {
+			t.Errorf("#%d: got check run %#v", i, run)
+		}
+		if g, w := polls, tt.wantPolls; g != w {
+			t.Errorf("#%d: polls: got %d want %d", i, g, w)
+		}
+	}
+}
+
+func TestListCheckRunsForRef(t *testing.T) {
+	var queries []string
+	client := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		if g, w := r.URL.Path, "/repos/orijtech/gcla/commits/master/check-runs"; g != w {
+			t.Errorf("path: got %q want %q", g, w)
+		}
+		queries = append(queries, r.URL.RawQuery)
+		fmt.Fprint(w, `{
+  "total_count": 2,
+  "check_runs": [
+    {"id": 4, "head_sha": "aa218f5", "name": "test", "status": "completed", "conclusion": "failure"},
+    {"id": 5, "head_sha": "aa218f5", "name": "lint", "status": "queued"}
+  ]
+}`)
+	})
+
+	runs, err := client.ListCheckRunsForRef("orijtech", "gcla", "master")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g, w := len(runs), 2; g != w {
+		t.Fatalf("got %d check runs, want total_count=%d", g, w)
+	}
+	if g, w := runs[1].Status, CheckStatusQueued; g != w {
+		t.Errorf("status: got %q want %q", g, w)
+	}
+	if g, w := runs[0].Conclusion, "failure"; g != w {
+		t.Errorf("conclusion: got %q want %q", g, w)
+	}
+
+	if _, err := client.ListCheckRunsByName("orijtech", "gcla", "master", "lint"); err != nil {
+		t.Fatal(err)
+	}
+	if g, w := fmt.Sprint(queries), "[ check_name=lint]"; g != w {
+		t.Errorf("queries: got %q want %q", g, w)
+	}
+
+	for i, args := range [][4]string{
+		{"", "gcla", "master", "lint"},
+		{"orijtech", "", "master", "lint"},
+		{"orijtech", "gcla", "", "lint"},
+		{"orijtech", "gcla", "master", ""},
+	} {
+		if _, err := client.ListCheckRunsByName(args[0], args[1], args[2], args[3]); err == nil {
+			t.Errorf("#%d: %q: expected a non-nil error", i, args)
+		}
+	}
+	if g, w := len(queries), 2; g != w {
+		t.Errorf("invalid arguments still sent %d requests", g-w)
+	}
+}
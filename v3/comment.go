@@ -0,0 +1,290 @@
+// Copyright 2017 orijtech. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gcla
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// LineInFile returns the line number that the comment refers to in the
+// version of Path at CommitID, preferring Line as reported by GitHub.
+//
+// Otherwise the line is derived from DiffHunk, which GitHub truncates
+// right after the commented line; it is the last line of the last hunk.
+// Position can't be used as an index into DiffHunk since it counts the
+// lines of the whole file's diff, starting from its first hunk. It returns
+// false for outdated comments, whose Position is 0, if the hunk can't be
+// parsed or if the comment is on a line that was removed.
+func (c *Comment) LineInFile() (int, bool) {
+	if c == nil {
+		return 0, false
+	}
+	if c.Line != 0 {
+		if c.Side == "LEFT" {
+			return 0, false
+		}
+		return int(c.Line), true
+	}
+	if c.Position == 0 || c.DiffHunk == "" {
+		return 0, false
+	}
+
+	lines := strings.Split(strings.TrimRight(c.DiffHunk, "\n"), "\n")
+	// "\ No newline at end of file" markers can't be commented on.
+	for len(lines) > 0 && strings.HasPrefix(lines[len(lines)-1], `\`) {
+		lines = lines[:len(lines)-1]
+	}
+	header := -1
+	for i := len(lines) - 1; i >= 0; i-- {
+		if strings.HasPrefix(lines[i], "@@") {
+			header = i
+			break
+		}
+	}
+	if header < 0 || header == len(lines)-1 {
+		return 0, false
+	}
+	newLine, ok := parseHunkHeader(lines[header])
+	if !ok {
+		return 0, false
+	}
+
+	body := lines[header+1:]
+	for _, line := range body[:len(body)-1] {
+		// Removed lines and "\ No newline at end of file"
+		// markers have no counterpart in the new file.
+		if !strings.HasPrefix(line, "-") && !strings.HasPrefix(line, `\`) {
+			newLine++
+		}
+	}
+	if strings.HasPrefix(body[len(body)-1], "-") {
+		return 0, false
+	}
+	return newLine, true
+}
+
+// parseHunkHeader extracts the starting line number of the new
+// file from a unified diff header such as "@@ -10,7 +12,8 @@ func".
+func parseHunkHeader(header string) (int, bool) {
+	fields := strings.Fields(header)
+	if len(fields) < 4 || fields[0] != "@@" || fields[3] != "@@" {
+		return 0, false
+	}
+	newRange := fields[2]
+	if !strings.HasPrefix(newRange, "+") {
+		return 0, false
+	}
+	start := strings.SplitN(newRange[1:], ",", 2)[0]
+	n, err := strconv.Atoi(start)
+	if err != nil || n < 0 {
+		return 0, false
+	}
+	return n, true
+}
+
+// PermalinkURL returns a link to the line that the comment refers to,
+// pinned to CommitID, in the repository whose web page is repoHTMLURL
+// e.g. "https://github.com/orijtech/gcla". It falls back to HTMLURL
+// if any of Path, CommitID or the line from LineInFile is unavailable.
+func (c *Comment) PermalinkURL(repoHTMLURL string) string {
+	if c == nil {
+		return ""
+	}
+	line, ok := c.LineInFile()
+	if !ok || repoHTMLURL == "" || c.CommitID == "" || c.Path == "" {
+		return c.HTMLURL
+	}
+	return strings.TrimSuffix(repoHTMLURL, "/") + "/blob/" + c.CommitID + "/" +
+		strings.TrimPrefix(c.Path, "/") + "#L" + strconv.Itoa(line)
+}
+
+// GroupThreads groups review comments into threads by following each
+// comment's InReplyToID back to the comment that started its thread.
+// Every thread starts with that comment, followed by its replies in the
+// order that they appear in comments; threads are ordered by where their
+// first comment appears. Replies to comments that aren't in comments
+// start threads of their own.
+func GroupThreads(comments []*Comment) [][]*Comment {
+	byID := make(map[uint64]*Comment, len(comments))
+	for _, c := range comments {
+		if c != nil && c.ID != 0 {
+			byID[c.ID] = c
+		}
+	}
+
+	rootOf := func(c *Comment) *Comment {
+		chain := []*Comment{c}
+		index := map[uint64]int{c.ID: 0}
+		for c.InReplyToID != 0 {
+			if i, ok := index[c.InReplyToID]; ok {
+				// A malformed reply chain that loops: pick the same
+				// comment of the loop no matter where the walk started.
+				root := chain[i]
+				for _, cc := range chain[i:] {
+					if cc.ID < root.ID {
+						root = cc
+					}
+				}
+				return root
+			}
+			parent, ok := byID[c.InReplyToID]
+			if !ok {
+				break
+			}
+			index[parent.ID] = len(chain)
+			chain = append(chain, parent)
+			c = parent
+		}
+		return c
+	}
+
+	var threads [][]*Comment
+	threadIndex := make(map[*Comment]int)
+	for _, c := range comments {
+		if c == nil {
+			continue
+		}
+		root := rootOf(c)
+		i, ok := threadIndex[root]
+		if !ok {
+			i = len(threads)
+			threadIndex[root] = i
+			threads = append(threads, []*Comment{root})
+		}
+		if c != root {
+			threads[i] = append(threads[i], c)
+		}
+	}
+	return threads
+}
+
+var (
+	errEmptyPullNumber  = errors.New("expecting a non-zero pull request number")
+	errEmptyCommentID   = errors.New("expecting a non-zero comment ID")
+	errEmptyCommentBody = errors.New("expecting a non-empty comment body")
+)
+
+// MaxBodyLength is the most characters that GitHub accepts in the body
+// of a comment, review or pull request. Longer bodies are rejected with
+// "422 Unprocessable Entity".
+const MaxBodyLength = 65536
+
+// BodyTooLongError is returned, before contacting GitHub, for bodies
+// that are longer than MaxBodyLength characters.
+type BodyTooLongError struct {
+	Length int
+}
+
+func (btle *BodyTooLongError) Error() string {
+	return fmt.Sprintf("body is %d characters long, GitHub accepts at most %d", btle.Length, MaxBodyLength)
+}
+
+// ValidateBody returns a *BodyTooLongError if body is longer than
+// MaxBodyLength characters. Bots can use it to check bodies before
+// posting them in ways that this package doesn't, such as GraphQL.
+// Empty bodies are valid since pull request bodies can be cleared.
+func ValidateBody(body string) error {
+	// Short-circuit bodies that can't be too long whatever their runes.
+	if len(body) <= MaxBodyLength {
+		return nil
+	}
+	if n := utf8.RuneCountInString(body); n > MaxBodyLength {
+		return &BodyTooLongError{Length: n}
+	}
+	return nil
+}
+
+type commentReplyRequest struct {
+	Body string `json:"body"`
+}
+
+// ReplyToReviewComment replies with body to the review comment commentID
+// on the pull request number. Replies to replies aren't supported by
+// GitHub so commentID has to be that of the comment that started the
+// thread. The returned comment's InReplyToID is set to commentID.
+func (c *Client) ReplyToReviewComment(owner, repo string, number, commentID uint64, body string) (*Comment, error) {
+	if owner == "" {
+		return nil, errEmptyOwner
+	}
+	if repo == "" {
+		return nil, errEmptyRepo
+	}
+	if number == 0 {
+		return nil, errEmptyPullNumber
+	}
+	if commentID == 0 {
+		return nil, errEmptyCommentID
+	}
+	if body == "" {
+		return nil, errEmptyCommentBody
+	}
+	if err := ValidateBody(body); err != nil {
+		return nil, err
+	}
+	blob, err := json.Marshal(&commentReplyRequest{Body: body})
+	if err != nil {
+		return nil, err
+	}
+	fullURL := fmt.Sprintf("%s/repos/%s/%s/pulls/%d/comments/%d/replies", baseURL, owner, repo, number, commentID)
+	req, err := http.NewRequest("POST", fullURL, bytes.NewReader(blob))
+	if err != nil {
+		return nil, err
+	}
+	blob, _, err = c.doHTTPReq(req)
+	if err != nil {
+		return nil, err
+	}
+	reply := new(Comment)
+	if err := json.Unmarshal(blob, reply); err != nil {
+		return nil, err
+	}
+	return reply, nil
+}
+
+// ListReviewComments returns the review comments on the diff of the pull
+// request number, following pagination. Unlike the pull request's issue
+// comments, review comments are attached to a Path and DiffHunk.
+func (c *Client) ListReviewComments(owner, repo string, number uint64) ([]*Comment, error) {
+	if owner == "" {
+		return nil, errEmptyOwner
+	}
+	if repo == "" {
+		return nil, errEmptyRepo
+	}
+	if number == 0 {
+		return nil, errEmptyPullNumber
+	}
+	fullURL := fmt.Sprintf("%s/repos/%s/%s/pulls/%d/comments", baseURL, owner, repo, number)
+	var comments []*Comment
+	err := c.getAllPages(fullURL, func(blob []byte) error {
+		var page []*Comment
+		if err := json.Unmarshal(blob, &page); err != nil {
+			return err
+		}
+		comments = append(comments, page...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return comments, nil
+}
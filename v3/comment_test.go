@@ -0,0 +1,395 @@
+// Copyright 2017 orijtech. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gcla
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const multiHunkDiff = `@@ -1,4 +1,5 @@
+ package main
++
+ import "fmt"
+-import "os"
+
+@@ -20,3 +21,4 @@ func main() {
+ 	fmt.Println("a")
++	fmt.Println("b")
+ }
+\ No newline at end of file`
+
+// The DiffHunk of a review comment is truncated at the commented line
+// and only holds the hunk that it is in. These are the hunks for comments
+// on lines of multiHunkDiff, whose position is noted in parentheses.
+const (
+	// Added blank line (2).
+	addedLineHunk = "@@ -1,4 +1,5 @@\n package main\n+"
+	// `import "os"` (4).
+	removedLineHunk = "@@ -1,4 +1,5 @@\n package main\n+\n import \"fmt\"\n-import \"os\""
+	// Blank line after the removed one (5).
+	afterRemovedHunk = removedLineHunk + "\n "
+	// `fmt.Println("b")` in the second hunk (8).
+	laterHunk = "@@ -20,3 +21,4 @@ func main() {\n \tfmt.Println(\"a\")\n+\tfmt.Println(\"b\")"
+)
+
+func TestCommentLineInFile(t *testing.T) {
+	tests := [...]struct {
+		hunk     string
+		position uint64
+		line     uint64
+		side     string
+		wantLine int
+		wantOK   bool
+	}{
+		0: {hunk: "@@ -1,4 +1,5 @@\n package main", position: 1, wantLine: 1, wantOK: true},
+		1: {hunk: addedLineHunk, position: 2, wantLine: 2, wantOK: true},
+		// Removed lines don't exist in the new file.
+		2: {hunk: removedLineHunk, position: 4, wantOK: false},
+		3: {hunk: afterRemovedHunk, position: 5, wantLine: 4, wantOK: true},
+		// Position counts from the first hunk of the file's diff,
+		// so it is past the end of a later hunk's DiffHunk.
+		4: {hunk: laterHunk, position: 8, wantLine: 22, wantOK: true},
+		// An untruncated diff ends at its last line, skipping the
+		// "\ No newline at end of file" marker.
+		5: {hunk: multiHunkDiff, position: 9, wantLine: 23, wantOK: true},
+		6: {hunk: "@@ -0,0 +1,2 @@\n+first\n+second", position: 2, wantLine: 2, wantOK: true},
+		7: {hunk: "@@ -3 +3 @@\n-old\n+new", position: 2, wantLine: 3, wantOK: true},
+		// Line as reported by GitHub is preferred.
+		8:  {hunk: laterHunk, position: 8, line: 30, side: "RIGHT", wantLine: 30, wantOK: true},
+		9:  {line: 30, wantLine: 30, wantOK: true},
+		10: {hunk: laterHunk, position: 8, line: 30, side: "LEFT", wantOK: false},
+		// Outdated comments have no position.
+		11: {hunk: laterHunk, position: 0, wantOK: false},
+		12: {hunk: "not a hunk", position: 1, wantOK: false},
+		13: {hunk: "@@ -1,2 +x,2 @@\n a", position: 1, wantOK: false},
+		14: {hunk: "@@ -1,2 +1,2 @@", position: 1, wantOK: false},
+		15: {hunk: "", position: 1, wantOK: false},
+	}
+
+	for i, tt := range tests {
+		c := &Comment{DiffHunk: tt.hunk, Position: tt.position, Line: tt.line, Side: tt.side}
+		line, ok := c.LineInFile()
+		if ok != tt.wantOK {
+			t.Errorf("#%d: ok: got %v want %v", i, ok, tt.wantOK)
+			continue
+		}
+		if line != tt.wantLine {
+			t.Errorf("#%d: line: got %d want %d", i, line, tt.wantLine)
+		}
+	}
+}
+
+func TestCommentPermalinkURL(t *testing.T) {
+	const (
+		repoURL = "https://github.com/orijtech/gcla"
+		htmlURL = "https://github.com/orijtech/gcla/pull/1#discussion_r10"
+		sha     = "6dcb09b5b57875f334f61aebed695e2e4193db5e"
+	)
+	tests := [...]struct {
+		comment *Comment
+		repoURL string
+		want    string
+	}{
+		0: {
+			comment: &Comment{Path: "cmd/main.go", CommitID: sha, DiffHunk: laterHunk, Position: 8, HTMLURL: htmlURL},
+			repoURL: repoURL,
+			want:    repoURL + "/blob/" + sha + "/cmd/main.go#L22",
+		},
+		1: {
+			// A trailing slash on the repository URL is tolerated.
+			comment: &Comment{Path: "main.go", CommitID: sha, Line: 1, HTMLURL: htmlURL},
+			repoURL: repoURL + "/",
+			want:    repoURL + "/blob/" + sha + "/main.go#L1",
+		},
+		2: {
+			// Removed lines can't be resolved.
+			comment: &Comment{Path: "main.go", CommitID: sha, DiffHunk: removedLineHunk, Position: 4, HTMLURL: htmlURL},
+			repoURL: repoURL,
+			want:    htmlURL,
+		},
+		3: {
+			comment: &Comment{Path: "main.go", Line: 1, HTMLURL: htmlURL},
+			repoURL: repoURL,
+			want:    htmlURL,
+		},
+		4: {
+			comment: &Comment{CommitID: sha, Line: 1, HTMLURL: htmlURL},
+			repoURL: repoURL,
+			want:    htmlURL,
+		},
+		5: {
+			comment: &Comment{Path: "main.go", CommitID: sha, Line: 1, HTMLURL: htmlURL},
+			want:    htmlURL,
+		},
+		6: {comment: nil, repoURL: repoURL, want: ""},
+	}
+
+	for i, tt := range tests {
+		if got := tt.comment.PermalinkURL(tt.repoURL); got != tt.want {
+			t.Errorf("#%d: got %q want %q", i, got, tt.want)
+		}
+	}
+}
+
+func TestCommentInReplyToIDDecoding(t *testing.T) {
+	const payload = `{
+  "id": 11,
+  "in_reply_to_id": 10,
+  "path": "gcla.go",
+  "body": "Done, thanks!",
+  "user": {"login": "odeke-em", "id": 1}
+}`
+	c := new(Comment)
+	if err := json.Unmarshal([]byte(payload), c); err != nil {
+		t.Fatal(err)
+	}
+	if g, w := c.InReplyToID, uint64(10); g != w {
+		t.Errorf("InReplyToID: got %d want %d", g, w)
+	}
+}
+
+func TestGroupThreads(t *testing.T) {
+	tests := [...]struct {
+		comments []*Comment
+		want     [][]uint64
+	}{
+		0: {comments: nil, want: nil},
+		1: {
+			comments: []*Comment{
+				{ID: 1},
+				{ID: 2},
+				{ID: 3, InReplyToID: 1},
+				{ID: 4, InReplyToID: 3},
+				{ID: 5, InReplyToID: 2},
+			},
+			want: [][]uint64{{1, 3, 4}, {2, 5}},
+		},
+		// Replies listed before the comment that they reply to.
+		2: {
+			comments: []*Comment{
+				{ID: 3, InReplyToID: 1},
+				{ID: 1},
+			},
+			want: [][]uint64{{1, 3}},
+		},
+		// A reply whose parent isn't in the list starts its own thread.
+		3: {
+			comments: []*Comment{
+				{ID: 7, InReplyToID: 6},
+				{ID: 8, InReplyToID: 7},
+			},
+			want: [][]uint64{{7, 8}},
+		},
+		// Malformed chains that loop don't hang.
+		4: {
+			comments: []*Comment{
+				{ID: 1, InReplyToID: 2},
+				{ID: 2, InReplyToID: 1},
+			},
+			want: [][]uint64{{1, 2}},
+		},
+	}
+
+	for i, tt := range tests {
+		var got [][]uint64
+		for _, thread := range GroupThreads(tt.comments) {
+			var ids []uint64
+			for _, c := range thread {
+				ids = append(ids, c.ID)
+			}
+			got = append(got, ids)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("#%d: got %v want %v", i, got, tt.want)
+		}
+	}
+}
+
+func TestReplyToReviewComment(t *testing.T) {
+	client := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		if g, w := r.Method, "POST"; g != w {
+			t.Errorf("method: got %q want %q", g, w)
+		}
+		if g, w := r.URL.Path, "/repos/orijtech/gcla/pulls/12/comments/10/replies"; g != w {
+			t.Errorf("path: got %q want %q", g, w)
+		}
+		blob, _ := io.ReadAll(r.Body)
+		if g, w := string(blob), `{"body":"Done, thanks!"}`; g != w {
+			t.Errorf("body: got %s want %s", g, w)
+		}
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"id":11,"in_reply_to_id":10,"body":"Done, thanks!","path":"gcla.go"}`)
+	})
+
+	reply, err := client.ReplyToReviewComment("orijtech", "gcla", 12, 10, "Done, thanks!")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g, w := reply.ID, uint64(11); g != w {
+		t.Errorf("ID: got %d want %d", g, w)
+	}
+	if g, w := reply.InReplyToID, uint64(10); g != w {
+		t.Errorf("InReplyToID: got %d want %d", g, w)
+	}
+}
+
+func TestReplyToReviewCommentInvalidArgs(t *testing.T) {
+	client := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request to %q", r.URL)
+	})
+	tests := [...]struct {
+		owner, repo       string
+		number, commentID uint64
+		body              string
+	}{
+		0: {repo: "gcla", number: 12, commentID: 10, body: "ok"},
+		1: {owner: "orijtech", number: 12, commentID: 10, body: "ok"},
+		2: {owner: "orijtech", repo: "gcla", commentID: 10, body: "ok"},
+		3: {owner: "orijtech", repo: "gcla", number: 12, body: "ok"},
+		4: {owner: "orijtech", repo: "gcla", number: 12, commentID: 10},
+	}
+	for i, tt := range tests {
+		if _, err := client.ReplyToReviewComment(tt.owner, tt.repo, tt.number, tt.commentID, tt.body); err == nil {
+			t.Errorf("#%d: expected an error", i)
+		}
+	}
+}
+
+func TestListReviewComments(t *testing.T) {
+	client := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		if g, w := r.URL.Path, "/repos/orijtech/gcla/pulls/12/comments"; g != w {
+			t.Errorf("path: got %q want %q", g, w)
+		}
+		switch r.URL.Query().Get("page") {
+		case "":
+			w.Header().Set("Link", `<https://api.github.com/repos/orijtech/gcla/pulls/12/comments?page=2>; rel="next"`)
+			fmt.Fprint(w, `[{
+  "id": 10,
+  "path": "gcla.go",
+  "position": 1,
+  "diff_hunk": "@@ -16,33 +16,40 @@ package gcla\n import (",
+  "commit_id": "6dcb09b5b57875f334f61aebed695e2e4193db5e",
+  "body": "Please document this.",
+  "_links": {"self": {"href": "https://api.github.com/repos/orijtech/gcla/pulls/comments/10"}}
+}]`)
+		case "2":
+			fmt.Fprint(w, `[{"id": 11, "in_reply_to_id": 10, "path": "gcla.go", "position": 1, "diff_hunk": "@@ -16,33 +16,40 @@ package gcla\n import (", "body": "Done."}]`)
+		}
+	})
+
+	comments, err := client.ListReviewComments("orijtech", "gcla", 12)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g, w := len(comments), 2; g != w {
+		t.Fatalf("got %d comments want %d", g, w)
+	}
+	for i, c := range comments {
+		if g, w := c.Path, "gcla.go"; g != w {
+			t.Errorf("#%d: path: got %q want %q", i, g, w)
+		}
+		if !strings.HasPrefix(c.DiffHunk, "@@ -16,33 +16,40 @@") {
+			t.Errorf("#%d: diff hunk: got %q", i, c.DiffHunk)
+		}
+	}
+	if c := comments[0]; c.Links == nil || c.Links.Self == nil || c.Links.Self.Href != "https://api.github.com/repos/orijtech/gcla/pulls/comments/10" {
+		t.Errorf("links: got %#v", c.Links)
+	}
+	if g, w := comments[1].InReplyToID, uint64(10); g != w {
+		t.Errorf("InReplyToID: got %d want %d", g, w)
+	}
+}
+
+func TestReplyToReviewCommentBodyLength(t *testing.T) {
+	var requests int
+	client := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"id":11,"in_reply_to_id":10}`)
+	})
+
+	tests := [...]struct {
+		body       string
+		wantLength int
+	}{
+		0: {body: strings.Repeat("a", MaxBodyLength)},
+		// Characters are counted, not bytes.
+		1: {body: strings.Repeat("é", MaxBodyLength)},
+		2: {body: strings.Repeat("a", MaxBodyLength+1), wantLength: MaxBodyLength + 1},
+		3: {body: strings.Repeat("é", MaxBodyLength+2), wantLength: MaxBodyLength + 2},
+	}
+
+	for i, tt := range tests {
+		requests = 0
+		_, err := client.ReplyToReviewComment("orijtech", "gcla", 12, 10, tt.body)
+		if tt.wantLength == 0 {
+			if err != nil {
+				t.Errorf("#%d: unexpected error: %v", i, err)
+			}
+			continue
+		}
+		btle, ok := err.(*BodyTooLongError)
+		if !ok {
+			t.Errorf("#%d: got %T (%v) want *BodyTooLongError", i, err, err)
+			continue
+		}
+		if g, w := btle.Length, tt.wantLength; g != w {
+			t.Errorf("#%d: length: got %d want %d", i, g, w)
+		}
+		if !strings.Contains(btle.Error(), "65536") {
+			t.Errorf("#%d: error %q doesn't name the limit", i, btle)
+		}
+		if requests != 0 {
+			t.Errorf("#%d: got %d requests want none", i, requests)
+		}
+	}
+}
+
+func TestValidateBody(t *testing.T) {
+	tests := [...]struct {
+		body       string
+		wantLength int
+	}{
+		0: {body: ""},
+		1: {body: strings.Repeat("a", MaxBodyLength)},
+		2: {body: strings.Repeat("é", MaxBodyLength)},
+		3: {body: strings.Repeat("a", MaxBodyLength+1), wantLength: MaxBodyLength + 1},
+		4: {body: strings.Repeat("é", MaxBodyLength+2), wantLength: MaxBodyLength + 2},
+	}
+
+	for i, tt := range tests {
+		err := ValidateBody(tt.body)
+		if tt.wantLength == 0 {
+			if err != nil {
+				t.Errorf("#%d: unexpected error: %v", i, err)
+			}
+			continue
+		}
+		btle, ok := err.(*BodyTooLongError)
+		if !ok {
+			t.Errorf("#%d: got %T (%v) want *BodyTooLongError", i, err, err)
+			continue
+		}
+		if g, w := btle.Length, tt.wantLength; g != w {
+			t.Errorf("#%d: length: got %d want %d", i, g, w)
+		}
+	}
+}
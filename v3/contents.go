@@ -0,0 +1,258 @@
+// Copyright 2017 orijtech. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gcla
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+const (
+	ContentTypeFile      = "file"
+	ContentTypeDir       = "dir"
+	ContentTypeSymlink   = "symlink"
+	ContentTypeSubmodule = "submodule"
+)
+
+// RepoContent is a file, directory, symlink or submodule in a repository.
+type RepoContent struct {
+	Type        string `json:"type,omitempty"`
+	Encoding    string `json:"encoding,omitempty"`
+	Size        uint64 `json:"size,omitempty"`
+	Name        string `json:"name,omitempty"`
+	Path        string `json:"path,omitempty"`
+	Content     string `json:"content,omitempty"`
+	Target      string `json:"target,omitempty"`
+	SHA         string `json:"sha,omitempty"`
+	URL         string `json:"url,omitempty"`
+	GitURL      string `json:"git_url,omitempty"`
+	HTMLURL     string `json:"html_url,omitempty"`
+	DownloadURL string `json:"download_url,omitempty"`
+
+	// Entries lists the directory's immediate children, without their
+	// content, when Type is ContentTypeDir. Directories have no Content.
+	Entries []*RepoContent `json:"-"`
+}
+
+func (rc *RepoContent) IsDir() bool {
+	return rc != nil && rc.Type == ContentTypeDir
+}
+
+var errDirHasNoContent = errors.New("directories have no content, use Entries instead")
+
+// DecodedContent returns the raw bytes of a file's Content.
+func (rc *RepoContent) DecodedContent() ([]byte, error) {
+	if rc.IsDir() {
+		return nil, errDirHasNoContent
+	}
+	return decodeContent(rc.Encoding, rc.Content)
+}
+
+func decodeContent(encoding, content string) ([]byte, error) {
+	switch encoding {
+	case "base64":
+		// GitHub wraps the encoded content at 60 characters.
+		stripped := strings.NewReplacer("\n", "", "\r", "").Replace(content)
+		return base64.StdEncoding.DecodeString(stripped)
+	case "", "utf-8":
+		return []byte(content), nil
+	default:
+		return nil, fmt.Errorf("unsupported content encoding %q", encoding)
+	}
+}
+
+// GetContents retrieves the file or directory at path in owner/repo.
+// ref is the name of a commit, branch or tag and if empty,
+// the repository's default branch is used. Directories are returned
+// as a RepoContent whose Entries are set.
+func (c *Client) GetContents(owner, repo, path, ref string) (*RepoContent, error) {
+	if owner == "" {
+		return nil, errEmptyOwner
+	}
+	if repo == "" {
+		return nil, errEmptyRepo
+	}
+	fullURL := fmt.Sprintf("%s/repos/%s/%s/contents/%s", baseURL, owner, repo, escapePath(path))
+	if ref != "" {
+		fullURL += "?" + url.Values{"ref": {ref}}.Encode()
+	}
+	req, err := http.NewRequest("GET", fullURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	blob, _, err := c.doHTTPReq(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Directories are listed as a JSON array while everything else is an object.
+	if trimmed := bytes.TrimSpace(blob); len(trimmed) > 0 && trimmed[0] == '[' {
+		var entries []*RepoContent
+		if err := json.Unmarshal(trimmed, &entries); err != nil {
+			return nil, err
+		}
+		dir := &RepoContent{
+			Type:    ContentTypeDir,
+			Path:    strings.Trim(path, "/"),
+			Entries: entries,
+		}
+		if i := strings.LastIndex(dir.Path, "/"); i >= 0 {
+			dir.Name = dir.Path[i+1:]
+		} else {
+			dir.Name = dir.Path
+		}
+		return dir, nil
+	}
+
+	rc := new(RepoContent)
+	if err := json.Unmarshal(blob, rc); err != nil {
+		return nil, err
+	}
+	return rc, nil
+}
+
+// escapePath escapes each segment of a slash separated repository path.
+func escapePath(path string) string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// FileCommitRequest describes a commit that creates,
+// updates or deletes a single file in a repository.
+type FileCommitRequest struct {
+	// Message is the commit message and is required.
+	Message string `json:"message"`
+
+	// Content is the new content of the file. It
+	// is base64 encoded when sent to GitHub.
+	Content []byte `json:"content"`
+
+	// SHA is the blob SHA of the file being replaced or deleted,
+	// as returned by GetContents. It must be set when updating or
+	// deleting a file and left empty when creating one.
+	SHA string `json:"sha,omitempty"`
+
+	// Branch defaults to the repository's default branch.
+	Branch string `json:"branch,omitempty"`
+
+	Committer *Author `json:"committer,omitempty"`
+	Author    *Author `json:"author,omitempty"`
+}
+
+// FileCommitResult is the outcome of a file commit. Content
+// describes the file that was written and is nil for deletions.
+type FileCommitResult struct {
+	Content *RepoContent `json:"content,omitempty"`
+	Commit  *Commit      `json:"commit,omitempty"`
+}
+
+var (
+	errEmptyPath          = errors.New("expecting a non-empty path")
+	errEmptyCommitMessage = errors.New("expecting a non-empty commit message")
+	errDeleteRequiresSHA  = errors.New("expecting the SHA of the file being deleted")
+
+	shaRegexp = regexp.MustCompile("^[0-9a-fA-F]{40}$")
+)
+
+func (fcr *FileCommitRequest) validate() error {
+	if fcr == nil || fcr.Message == "" {
+		return errEmptyCommitMessage
+	}
+	if fcr.SHA != "" && !shaRegexp.MatchString(fcr.SHA) {
+		return fmt.Errorf("invalid SHA %q, expecting 40 hexadecimal characters", fcr.SHA)
+	}
+	return nil
+}
+
+// CreateOrUpdateFile commits Content to path in owner/repo. Updating an
+// existing file requires setting SHA to the file's current blob SHA.
+// If that SHA is stale because the file changed since it was read,
+// GitHub rejects the commit with a "409 Conflict" *APIError.
+func (c *Client) CreateOrUpdateFile(owner, repo, path string, fcr *FileCommitRequest) (*FileCommitResult, error) {
+	if err := fcr.validate(); err != nil {
+		return nil, err
+	}
+	result, err := c.doFileCommit("PUT", owner, repo, path, fcr)
+	if err != nil && fcr.SHA == "" && isStatusCode(err, http.StatusUnprocessableEntity) {
+		return nil, fmt.Errorf("%q might already exist, updates must set the SHA of its current content: %v", path, err)
+	}
+	return result, err
+}
+
+// DeleteFile commits the deletion of path from owner/repo.
+// SHA must be set to the file's current blob SHA.
+func (c *Client) DeleteFile(owner, repo, path string, fcr *FileCommitRequest) (*FileCommitResult, error) {
+	if err := fcr.validate(); err != nil {
+		return nil, err
+	}
+	if fcr.SHA == "" {
+		return nil, errDeleteRequiresSHA
+	}
+	return c.doFileCommit("DELETE", owner, repo, path, &fileDeleteRequest{
+		Message:   fcr.Message,
+		SHA:       fcr.SHA,
+		Branch:    fcr.Branch,
+		Committer: fcr.Committer,
+		Author:    fcr.Author,
+	})
+}
+
+type fileDeleteRequest struct {
+	Message   string  `json:"message"`
+	SHA       string  `json:"sha"`
+	Branch    string  `json:"branch,omitempty"`
+	Committer *Author `json:"committer,omitempty"`
+	Author    *Author `json:"author,omitempty"`
+}
+
+func (c *Client) doFileCommit(method, owner, repo, path string, body interface{}) (*FileCommitResult, error) {
+	if owner == "" {
+		return nil, errEmptyOwner
+	}
+	if repo == "" {
+		return nil, errEmptyRepo
+	}
+	if strings.Trim(path, "/") == "" {
+		return nil, errEmptyPath
+	}
+	blob, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	fullURL := fmt.Sprintf("%s/repos/%s/%s/contents/%s", baseURL, owner, repo, escapePath(path))
+	req, err := http.NewRequest(method, fullURL, bytes.NewReader(blob))
+	if err != nil {
+		return nil, err
+	}
+	blob, _, err = c.doHTTPReq(req)
+	if err != nil {
+		return nil, err
+	}
+	result := new(FileCommitResult)
+	if err := json.Unmarshal(blob, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,210 @@
+// Copyright 2017 orijtech. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gcla
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestGetContentsFile(t *testing.T) {
+	client := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		if g, w := r.URL.Path, "/repos/orijtech/gcla/contents/.github/policy.yml"; g != w {
+			t.Errorf("path: got %q want %q", g, w)
+		}
+		if g, w := r.URL.Query().Get("ref"), "v3"; g != w {
+			t.Errorf("ref: got %q want %q", g, w)
+		}
+		// "require_cla: true\nowners:\n  - odeke-em\n" wrapped like GitHub does.
+		fmt.Fprint(w, `{
+  "type": "file",
+  "encoding": "base64",
+  "size": 40,
+  "name": "policy.yml",
+  "path": ".github/policy.yml",
+  "content": "cmVxdWlyZV9jbGE6IHRydWUKb3duZXJzOgogIC0gb2Rla2UtZW0K\n",
+  "sha": "3d21ec53a331a6f037a91c368710b99387d012c1"
+}`)
+	})
+
+	rc, err := client.GetContents("orijtech", "gcla", "/.github/policy.yml", "v3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rc.IsDir() {
+		t.Fatalf("expected a file")
+	}
+	content, err := rc.DecodedContent()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g, w := string(content), "require_cla: true\nowners:\n  - odeke-em\n"; g != w {
+		t.Errorf("content:\ngot:  %q\nwant: %q", g, w)
+	}
+}
+
+func TestGetContentsDirectory(t *testing.T) {
+	client := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		if g, w := r.URL.Path, "/repos/orijtech/gcla/contents/cmd/gcla-server"; g != w {
+			t.Errorf("path: got %q want %q", g, w)
+		}
+		if r.URL.RawQuery != "" {
+			t.Errorf("expected no query string, got %q", r.URL.RawQuery)
+		}
+		fmt.Fprint(w, `[
+  {"type":"file","name":"main.go","path":"cmd/gcla-server/main.go","size":1024},
+  {"type":"file","name":"config.go","path":"cmd/gcla-server/config.go","size":2048}
+]`)
+	})
+
+	rc, err := client.GetContents("orijtech", "gcla", "cmd/gcla-server", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !rc.IsDir() {
+		t.Fatalf("expected a directory, got type %q", rc.Type)
+	}
+	if g, w := rc.Name, "gcla-server"; g != w {
+		t.Errorf("name: got %q want %q", g, w)
+	}
+	if g, w := len(rc.Entries), 2; g != w {
+		t.Fatalf("entries: got %d want %d", g, w)
+	}
+	if g, w := rc.Entries[1].Path, "cmd/gcla-server/config.go"; g != w {
+		t.Errorf("entry path: got %q want %q", g, w)
+	}
+	if _, err := rc.DecodedContent(); err == nil {
+		t.Errorf("expected an error decoding a directory's content")
+	}
+}
+
+func TestCreateOrUpdateFile(t *testing.T) {
+	const currentSHA = "3d21ec53a331a6f037a91c368710b99387d012c1"
+	const staleSHA = "0000000000000000000000000000000000000001"
+
+	client := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		if g, w := r.Method, "PUT"; g != w {
+			t.Errorf("method: got %q want %q", g, w)
+		}
+		if g, w := r.URL.Path, "/repos/orijtech/gcla/contents/docs/README.md"; g != w {
+			t.Errorf("path: got %q want %q", g, w)
+		}
+		var fcr struct {
+			Message string `json:"message"`
+			Content string `json:"content"`
+			SHA     string `json:"sha"`
+			Branch  string `json:"branch"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&fcr); err != nil {
+			t.Errorf("decoding body: %v", err)
+		}
+		if g, w := fcr.Content, "IyBnY2xhCg=="; g != w {
+			t.Errorf("content: got %q want %q", g, w)
+		}
+		switch fcr.SHA {
+		case "":
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{"content":{"name":"README.md","path":"docs/README.md","sha":"95b966ae1c166bd92f8ae7d1c313e738c731dfc3"},"commit":{"sha":"7638417db6d59f3c431d3e1f261cc637155684cd","message":"Add docs"}}`)
+		case currentSHA:
+			fmt.Fprint(w, `{"content":{"name":"README.md","path":"docs/README.md","sha":"95b966ae1c166bd92f8ae7d1c313e738c731dfc3"},"commit":{"sha":"aa218f56b14c9653891f9e74264a383fa43fefbd","message":"Update docs"}}`)
+		default:
+			w.WriteHeader(http.StatusConflict)
+			fmt.Fprintf(w, `{"message":"docs/README.md does not match %s"}`, fcr.SHA)
+		}
+	})
+
+	tests := [...]struct {
+		sha        string
+		wantCommit string
+		wantStatus int
+	}{
+		0: {sha: "", wantCommit: "7638417db6d59f3c431d3e1f261cc637155684cd"},
+		1: {sha: currentSHA, wantCommit: "aa218f56b14c9653891f9e74264a383fa43fefbd"},
+		2: {sha: staleSHA, wantStatus: http.StatusConflict},
+	}
+
+	for i, tt := range tests {
+		result, err := client.CreateOrUpdateFile("orijtech", "gcla", "docs/README.md", &FileCommitRequest{
+			Message:   "Add docs",
+			Content:   []byte("# gcla\n"),
+			SHA:       tt.sha,
+			Committer: &Author{Name: "Emmanuel T Odeke", Email: "emm.odeke@gmail.com"},
+		})
+		if tt.wantStatus != 0 {
+			if !isStatusCode(err, tt.wantStatus) {
+				t.Errorf("#%d: got err=%v want a %d *APIError", i, err, tt.wantStatus)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("#%d: unexpected error: %v", i, err)
+			continue
+		}
+		if g, w := result.Commit.SHA, tt.wantCommit; g != w {
+			t.Errorf("#%d: commit: got %q want %q", i, g, w)
+		}
+		if result.Content == nil || result.Content.Path != "docs/README.md" {
+			t.Errorf("#%d: unexpected content: %#v", i, result.Content)
+		}
+	}
+}
+
+func TestFileCommitValidation(t *testing.T) {
+	client := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request to %q", r.URL)
+	})
+
+	if _, err := client.CreateOrUpdateFile("orijtech", "gcla", "a.txt", &FileCommitRequest{}); err == nil {
+		t.Errorf("expected an error for a missing message")
+	}
+	if _, err := client.CreateOrUpdateFile("orijtech", "gcla", "a.txt", &FileCommitRequest{Message: "m", SHA: "abc"}); err == nil {
+		t.Errorf("expected an error for a malformed SHA")
+	}
+	if _, err := client.DeleteFile("orijtech", "gcla", "a.txt", &FileCommitRequest{Message: "m"}); err == nil {
+		t.Errorf("expected an error for a deletion without a SHA")
+	}
+}
+
+func TestDeleteFile(t *testing.T) {
+	client := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		if g, w := r.Method, "DELETE"; g != w {
+			t.Errorf("method: got %q want %q", g, w)
+		}
+		body := make(map[string]interface{})
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decoding body: %v", err)
+		}
+		if _, ok := body["content"]; ok {
+			t.Errorf("expected no content in a deletion")
+		}
+		fmt.Fprint(w, `{"content":null,"commit":{"sha":"7638417db6d59f3c431d3e1f261cc637155684cd","message":"Remove docs"}}`)
+	})
+
+	result, err := client.DeleteFile("orijtech", "gcla", "docs/README.md", &FileCommitRequest{
+		Message: "Remove docs",
+		SHA:     "95b966ae1c166bd92f8ae7d1c313e738c731dfc3",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Content != nil {
+		t.Errorf("expected no content, got %#v", result.Content)
+	}
+	if g, w := result.Commit.Message, "Remove docs"; g != w {
+		t.Errorf("message: got %q want %q", g, w)
+	}
+}
@@ -0,0 +1,106 @@
+// Copyright 2017 orijtech. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gcla
+
+import (
+	"time"
+)
+
+// DiscussionEvent is the payload sent when webhook "discussion" is fired.
+// It is triggered when a discussion is created, edited, answered or
+// otherwise changed. For ActionAnswered, Answer is the chosen comment.
+type DiscussionEvent struct {
+	Action     Action             `json:"action,omitempty"`
+	Discussion *Discussion        `json:"discussion,omitempty"`
+	Answer     *DiscussionComment `json:"answer,omitempty"`
+	Changes    *Change            `json:"changes,omitempty"`
+
+	Repository   *Repository   `json:"repository,omitempty"`
+	Organization *Organization `json:"organization,omitempty"`
+	Sender       *User         `json:"sender,omitempty"`
+	Installation *Installation `json:"installation,omitempty"`
+}
+
+// DiscussionCommentEvent is the payload sent when webhook
+// "discussion_comment" is fired. It is triggered when a comment
+// on a discussion is created, edited or deleted.
+type DiscussionCommentEvent struct {
+	Action     Action             `json:"action,omitempty"`
+	Comment    *DiscussionComment `json:"comment,omitempty"`
+	Discussion *Discussion        `json:"discussion,omitempty"`
+	Changes    *Change            `json:"changes,omitempty"`
+
+	Repository   *Repository   `json:"repository,omitempty"`
+	Organization *Organization `json:"organization,omitempty"`
+	Sender       *User         `json:"sender,omitempty"`
+	Installation *Installation `json:"installation,omitempty"`
+}
+
+type Discussion struct {
+	ID            uint64              `json:"id,omitempty"`
+	Number        uint64              `json:"number,omitempty"`
+	HTMLURL       string              `json:"html_url,omitempty"`
+	RepositoryURL string              `json:"repository_url,omitempty"`
+	Title         string              `json:"title,omitempty"`
+	Body          string              `json:"body,omitempty"`
+	User          *User               `json:"user,omitempty"`
+	Category      *DiscussionCategory `json:"category,omitempty"`
+	State         State               `json:"state,omitempty"`
+	Locked        bool                `json:"locked,omitempty"`
+	Comments      uint64              `json:"comments,omitempty"`
+
+	// AnswerHTMLURL is the URL of the comment that was chosen as
+	// the answer. It is empty until the discussion is answered.
+	AnswerHTMLURL  string     `json:"answer_html_url,omitempty"`
+	AnswerChosenAt *time.Time `json:"answer_chosen_at,omitempty"`
+	AnswerChosenBy *User      `json:"answer_chosen_by,omitempty"`
+
+	AuthorAssociation string     `json:"author_association,omitempty"`
+	CreatedAt         *time.Time `json:"created_at,omitempty"`
+	UpdatedAt         *time.Time `json:"updated_at,omitempty"`
+}
+
+// IsAnswered reports whether a comment was chosen as the discussion's answer.
+func (d *Discussion) IsAnswered() bool {
+	return d != nil && d.AnswerHTMLURL != ""
+}
+
+type DiscussionCategory struct {
+	ID          uint64 `json:"id,omitempty"`
+	Name        string `json:"name,omitempty"`
+	Slug        string `json:"slug,omitempty"`
+	Emoji       string `json:"emoji,omitempty"`
+	Description string `json:"description,omitempty"`
+	// IsAnswerable is set for categories, such as "Q&A",
+	// whose discussions can have an answer chosen.
+	IsAnswerable bool       `json:"is_answerable,omitempty"`
+	CreatedAt    *time.Time `json:"created_at,omitempty"`
+	UpdatedAt    *time.Time `json:"updated_at,omitempty"`
+}
+
+type DiscussionComment struct {
+	ID            uint64 `json:"id,omitempty"`
+	HTMLURL       string `json:"html_url,omitempty"`
+	RepositoryURL string `json:"repository_url,omitempty"`
+	// ParentID is the ID of the comment that this one
+	// replies to, or 0 for top-level comments.
+	ParentID          uint64     `json:"parent_id,omitempty"`
+	ChildCommentCount uint64     `json:"child_comment_count,omitempty"`
+	Body              string     `json:"body,omitempty"`
+	User              *User      `json:"user,omitempty"`
+	AuthorAssociation string     `json:"author_association,omitempty"`
+	CreatedAt         *time.Time `json:"created_at,omitempty"`
+	UpdatedAt         *time.Time `json:"updated_at,omitempty"`
+}
@@ -0,0 +1,156 @@
+// Copyright 2017 orijtech. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gcla
+
+import (
+	"fmt"
+	"testing"
+)
+
+const discussionJSON = `{
+    "id": 4470734,
+    "number": 90,
+    "html_url": "https://github.com/orijtech/gcla/discussions/90",
+    "repository_url": "https://api.github.com/repos/orijtech/gcla",
+    "title": "How do I verify deliveries?",
+    "body": "Is there a helper for X-Hub-Signature-256?",
+    "user": {"login": "odeke-em", "id": 7},
+    "category": {
+      "id": 35729128,
+      "name": "Q&A",
+      "slug": "q-a",
+      "emoji": ":pray:",
+      "description": "Ask the community for help",
+      "is_answerable": true,
+      "created_at": "2021-03-01T18:24:05Z",
+      "updated_at": "2021-03-01T18:24:05Z"
+    },
+    "state": "open",
+    "locked": false,
+    "comments": 1,
+    "answer_html_url": %s,
+    "answer_chosen_at": %s,
+    "answer_chosen_by": %s,
+    "author_association": "OWNER",
+    "created_at": "2022-09-21T11:02:09Z",
+    "updated_at": "2022-09-21T11:02:09Z"
+  }`
+
+func TestParseDiscussionEvent(t *testing.T) {
+	created := `{
+  "action": "created",
+  "discussion": ` + fmt.Sprintf(discussionJSON, "null", "null", "null") + `,
+  "repository": {"id": 35129377, "full_name": "orijtech/gcla"},
+  "organization": {"login": "orijtech", "id": 25489431},
+  "sender": {"login": "odeke-em", "id": 7}
+}`
+	answered := `{
+  "action": "answered",
+  "discussion": ` + fmt.Sprintf(discussionJSON,
+		`"https://github.com/orijtech/gcla/discussions/90#discussioncomment-3707826"`,
+		`"2022-09-22T09:15:47Z"`,
+		`{"login": "odeke-em", "id": 7}`) + `,
+  "answer": {
+    "id": 3707826,
+    "html_url": "https://github.com/orijtech/gcla/discussions/90#discussioncomment-3707826",
+    "parent_id": null,
+    "child_comment_count": 0,
+    "repository_url": "https://api.github.com/repos/orijtech/gcla",
+    "body": "Use gcla.VerifySignature.",
+    "user": {"login": "jadekler", "id": 2},
+    "author_association": "MEMBER",
+    "created_at": "2022-09-22T09:14:02Z",
+    "updated_at": "2022-09-22T09:14:02Z"
+  },
+  "repository": {"id": 35129377, "full_name": "orijtech/gcla"},
+  "organization": {"login": "orijtech", "id": 25489431},
+  "sender": {"login": "odeke-em", "id": 7}
+}`
+
+	tests := [...]struct {
+		payload      string
+		wantAction   Action
+		wantAnswered bool
+	}{
+		0: {payload: created, wantAction: ActionCreated},
+		1: {payload: answered, wantAction: ActionAnswered, wantAnswered: true},
+	}
+
+	for i, tt := range tests {
+		got, err := ParseWebhook(EventDiscussion, []byte(tt.payload), DisallowUnknownFields())
+		if err != nil {
+			t.Errorf("#%d: %v", i, err)
+			continue
+		}
+		ev, ok := got.(*DiscussionEvent)
+		if !ok {
+			t.Errorf("#%d: got %T want *DiscussionEvent", i, got)
+			continue
+		}
+		if g, w := ev.Action, tt.wantAction; g != w {
+			t.Errorf("#%d: action: got %q want %q", i, g, w)
+		}
+		d := ev.Discussion
+		if d == nil || d.Title != "How do I verify deliveries?" || d.State != StateOpen {
+			t.Errorf("#%d: discussion: got %#v", i, d)
+			continue
+		}
+		if d.Category == nil || d.Category.Slug != "q-a" || !d.Category.IsAnswerable {
+			t.Errorf("#%d: category: got %#v", i, d.Category)
+		}
+		if g, w := d.IsAnswered(), tt.wantAnswered; g != w {
+			t.Errorf("#%d: answered: got %v want %v", i, g, w)
+		}
+		if ev.Organization == nil || ev.Organization.Login != "orijtech" {
+			t.Errorf("#%d: organization: got %#v", i, ev.Organization)
+		}
+		if !tt.wantAnswered {
+			if ev.Answer != nil {
+				t.Errorf("#%d: unexpected answer %#v", i, ev.Answer)
+			}
+			continue
+		}
+		if ev.Answer == nil || ev.Answer.ID != 3707826 || ev.Answer.User == nil || ev.Answer.User.Username != "jadekler" {
+			t.Errorf("#%d: answer: got %#v", i, ev.Answer)
+		}
+		if d.AnswerChosenBy == nil || d.AnswerChosenBy.Username != "odeke-em" || d.AnswerChosenAt == nil {
+			t.Errorf("#%d: answer chosen by: got %#v at %v", i, d.AnswerChosenBy, d.AnswerChosenAt)
+		}
+	}
+}
+
+func TestParseDiscussionCommentEvent(t *testing.T) {
+	payload := `{
+  "action": "created",
+  "comment": {"id": 3707827, "parent_id": 3707826, "body": "Thanks!", "user": {"login": "odeke-em", "id": 7}},
+  "discussion": ` + fmt.Sprintf(discussionJSON, "null", "null", "null") + `,
+  "repository": {"id": 35129377, "full_name": "orijtech/gcla"},
+  "sender": {"login": "odeke-em", "id": 7}
+}`
+	got, err := ParseWebhook(EventDiscussionComment, []byte(payload), DisallowUnknownFields())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ev, ok := got.(*DiscussionCommentEvent)
+	if !ok {
+		t.Fatalf("got %T want *DiscussionCommentEvent", got)
+	}
+	if ev.Comment == nil || ev.Comment.ParentID != 3707826 || ev.Comment.Body != "Thanks!" {
+		t.Errorf("comment: got %#v", ev.Comment)
+	}
+	if ev.Discussion == nil || ev.Discussion.Number != 90 {
+		t.Errorf("discussion: got %#v", ev.Discussion)
+	}
+}
@@ -0,0 +1,150 @@
+// Copyright 2017 orijtech. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gcla
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// GitHubEvent is an entry of the events API, which can be polled as
+// an alternative to webhooks. Its Type names such as "PushEvent"
+// differ from the names of webhook events such as "push".
+type GitHubEvent struct {
+	ID        string          `json:"id,omitempty"`
+	Type      string          `json:"type,omitempty"`
+	Actor     *User           `json:"actor,omitempty"`
+	Repo      *EventRepo      `json:"repo,omitempty"`
+	Org       *Organization   `json:"org,omitempty"`
+	Payload   json.RawMessage `json:"payload,omitempty"`
+	Public    bool            `json:"public,omitempty"`
+	CreatedAt *time.Time      `json:"created_at,omitempty"`
+}
+
+// EventRepo is the abbreviated repository of a GitHubEvent.
+type EventRepo struct {
+	ID int64 `json:"id,omitempty"`
+	// Name is the full name of the repository e.g. "orijtech/gcla".
+	Name string `json:"name,omitempty"`
+	URL  string `json:"url,omitempty"`
+}
+
+// EventListOptions configures ListRepositoryEvents.
+type EventListOptions struct {
+	// ETag is the EventList.ETag of a previous call. If no events happened
+	// since, GitHub responds with "304 Not Modified" which doesn't count
+	// against the rate limit, and EventList.NotModified is set.
+	ETag string
+
+	Page    int
+	PerPage int
+}
+
+// EventList is a page of events as returned by ListRepositoryEvents.
+type EventList struct {
+	// Events is empty if NotModified is set.
+	Events      []*GitHubEvent
+	NotModified bool
+
+	// ETag is to be passed in EventListOptions.ETag when polling next.
+	ETag string
+	// PollInterval is how long GitHub asks clients to wait before polling
+	// again, from the "X-Poll-Interval" header. It is 0 if unset.
+	PollInterval time.Duration
+}
+
+// ListRepositoryEvents returns a page of the events of owner/repo,
+// most recent first. Only events from the past 90 days are listed.
+func (c *Client) ListRepositoryEvents(owner, repo string, opts *EventListOptions) (*EventList, error) {
+	if owner == "" {
+		return nil, errEmptyOwner
+	}
+	if repo == "" {
+		return nil, errEmptyRepo
+	}
+	if opts == nil {
+		opts = new(EventListOptions)
+	}
+
+	qv := make(url.Values)
+	if opts.Page > 0 {
+		qv.Set("page", strconv.Itoa(opts.Page))
+	}
+	if opts.PerPage > 0 {
+		qv.Set("per_page", strconv.Itoa(opts.PerPage))
+	}
+	fullURL := fmt.Sprintf("%s/repos/%s/%s/events", baseURL, owner, repo)
+	if len(qv) > 0 {
+		fullURL += "?" + qv.Encode()
+	}
+	req, err := http.NewRequest("GET", fullURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if opts.ETag != "" {
+		req.Header.Set("If-None-Match", opts.ETag)
+	}
+
+	blob, hdr, err := c.doHTTPReq(req)
+	el := new(EventList)
+	if hdr != nil {
+		el.ETag = hdr.Get("ETag")
+		if secs, err := strconv.Atoi(hdr.Get("X-Poll-Interval")); err == nil && secs > 0 {
+			el.PollInterval = time.Duration(secs) * time.Second
+		}
+	}
+	switch {
+	case isStatusCode(err, http.StatusNotModified):
+		el.NotModified = true
+		if el.ETag == "" {
+			el.ETag = opts.ETag
+		}
+		return el, nil
+	case err != nil:
+		return nil, err
+	}
+	if err := json.Unmarshal(blob, &el.Events); err != nil {
+		return nil, err
+	}
+	return el, nil
+}
+
+// apiEventTypes maps the types of GitHubEvent
+// to their equivalent webhook events.
+var apiEventTypes = map[string]Event{
+	"IssueCommentEvent":             EventIssueComment,
+	"IssuesEvent":                   EventIssues,
+	"PullRequestEvent":              EventPullRequest,
+	"PullRequestReviewCommentEvent": EventPullRequestReviewComment,
+	"PullRequestReviewEvent":        EventPullRequestReview,
+	"PushEvent":                     EventPush,
+	"ReleaseEvent":                  EventRelease,
+	"WatchEvent":                    EventWatch,
+}
+
+// Decode unmarshals e.Payload into the struct that corresponds to e.Type,
+// like ParseWebhook does for the equivalent webhook event. For example
+// the payload of a "PushEvent" is decoded into a *PushEvent.
+func (e *GitHubEvent) Decode() (interface{}, error) {
+	event, ok := apiEventTypes[e.Type]
+	if !ok {
+		return nil, fmt.Errorf("unhandled event type %q", e.Type)
+	}
+	return ParseWebhook(event, e.Payload)
+}
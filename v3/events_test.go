@@ -0,0 +1,194 @@
+// Copyright 2017 orijtech. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gcla
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+const repoEventsPayload = `[
+  {
+    "id": "22249084947",
+    "type": "PushEvent",
+    "actor": {"id": 7, "login": "odeke-em"},
+    "repo": {"id": 35129377, "name": "orijtech/gcla", "url": "https://api.github.com/repos/orijtech/gcla"},
+    "payload": {"push_id": 10115855396, "size": 1, "distinct_size": 1, "ref": "refs/heads/master", "head": "7a8f3ac80e2ad2f6842cb86f576d4bfe2c03e300", "before": "883efe034920928c47fe18598c01249d1a9fdabd"},
+    "public": true,
+    "created_at": "2022-06-09T12:47:28Z"
+  },
+  {
+    "id": "22237752260",
+    "type": "WatchEvent",
+    "actor": {"id": 2, "login": "jadekler"},
+    "repo": {"id": 35129377, "name": "orijtech/gcla", "url": "https://api.github.com/repos/orijtech/gcla"},
+    "org": {"id": 25489431, "login": "orijtech"},
+    "payload": {"action": "started"},
+    "public": true,
+    "created_at": "2022-06-08T23:29:25Z"
+  }
+]`
+
+func TestListRepositoryEvents(t *testing.T) {
+	const etag = `W/"a18c3bded88eb5dbb5c849a489412bf3"`
+	client := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		if g, w := r.URL.Path, "/repos/orijtech/gcla/events"; g != w {
+			t.Errorf("path: got %q want %q", g, w)
+		}
+		w.Header().Set("ETag", etag)
+		w.Header().Set("X-Poll-Interval", "60")
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		fmt.Fprint(w, repoEventsPayload)
+	})
+
+	el, err := client.ListRepositoryEvents("orijtech", "gcla", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if el.NotModified {
+		t.Error("unexpected NotModified")
+	}
+	if g, w := len(el.Events), 2; g != w {
+		t.Fatalf("got %d events want %d", g, w)
+	}
+	push := el.Events[0]
+	if push.Type != "PushEvent" || push.ID != "22249084947" {
+		t.Errorf("event #0: got type=%q id=%q", push.Type, push.ID)
+	}
+	if push.Actor == nil || push.Actor.Username != "odeke-em" {
+		t.Errorf("actor: got %#v", push.Actor)
+	}
+	if push.Repo == nil || push.Repo.Name != "orijtech/gcla" {
+		t.Errorf("repo: got %#v", push.Repo)
+	}
+	if push.CreatedAt == nil || !push.CreatedAt.Equal(time.Date(2022, 6, 9, 12, 47, 28, 0, time.UTC)) {
+		t.Errorf("created_at: got %v", push.CreatedAt)
+	}
+	if len(push.Payload) == 0 {
+		t.Error("expected the raw payload to be kept")
+	}
+	if el.Events[1].Org == nil || el.Events[1].Org.Login != "orijtech" {
+		t.Errorf("org: got %#v", el.Events[1].Org)
+	}
+	if g, w := el.ETag, etag; g != w {
+		t.Errorf("etag: got %q want %q", g, w)
+	}
+	if g, w := el.PollInterval, time.Minute; g != w {
+		t.Errorf("poll interval: got %v want %v", g, w)
+	}
+
+	// Polling again with the ETag is cheap when nothing changed.
+	el, err = client.ListRepositoryEvents("orijtech", "gcla", &EventListOptions{ETag: el.ETag})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !el.NotModified {
+		t.Error("expected NotModified")
+	}
+	if len(el.Events) != 0 {
+		t.Errorf("got %d events want none", len(el.Events))
+	}
+	if g, w := el.ETag, etag; g != w {
+		t.Errorf("etag: got %q want %q", g, w)
+	}
+}
+
+func TestGitHubEventDecode(t *testing.T) {
+	tests := [...]struct {
+		event   *GitHubEvent
+		check   func(interface{}) error
+		wantErr bool
+	}{
+		0: {
+			event: &GitHubEvent{
+				Type:    "PushEvent",
+				Payload: []byte(`{"push_id": 10115855396, "size": 1, "ref": "refs/heads/master", "head": "7a8f3ac80e2ad2f6842cb86f576d4bfe2c03e300"}`),
+			},
+			check: func(v interface{}) error {
+				pe, ok := v.(*PushEvent)
+				if !ok {
+					return fmt.Errorf("got %T want *PushEvent", v)
+				}
+				if pe.Ref != "refs/heads/master" || pe.Head != "7a8f3ac80e2ad2f6842cb86f576d4bfe2c03e300" || pe.CommitCount != 1 {
+					return fmt.Errorf("unexpected push: %#v", pe)
+				}
+				return nil
+			},
+		},
+		1: {
+			event: &GitHubEvent{
+				Type:    "PullRequestEvent",
+				Payload: []byte(`{"action": "opened", "number": 42, "pull_request": {"number": 42, "title": "Add GitHubEvent.Decode", "state": "open"}}`),
+			},
+			check: func(v interface{}) error {
+				pre, ok := v.(*PullRequestEvent)
+				if !ok {
+					return fmt.Errorf("got %T want *PullRequestEvent", v)
+				}
+				if pre.Action != ActionOpened || pre.Number != 42 {
+					return fmt.Errorf("unexpected event: %#v", pre)
+				}
+				if pr := pre.PullRequest; pr == nil || pr.Title != "Add GitHubEvent.Decode" || pr.State != StateOpen {
+					return fmt.Errorf("unexpected pull request: %#v", pr)
+				}
+				return nil
+			},
+		},
+		// The webhook event names aren't API event types.
+		2: {event: &GitHubEvent{Type: "push", Payload: []byte(`{}`)}, wantErr: true},
+		3: {event: &GitHubEvent{Type: "PushEvent", Payload: []byte(`{"ref": 1}`)}, wantErr: true},
+		4: {
+			event: &GitHubEvent{
+				Type:    "IssuesEvent",
+				Payload: []byte(`{"action": "closed", "issue": {"number": 7, "title": "Decode IssuesEvent"}}`),
+			},
+			check: func(v interface{}) error {
+				ie, ok := v.(*IssuesEvent)
+				if !ok {
+					return fmt.Errorf("got %T want *IssuesEvent", v)
+				}
+				if ie.Action != ActionClosed {
+					return fmt.Errorf("unexpected event: %#v", ie)
+				}
+				if issue := ie.Issue; issue == nil || issue.Number != 7 || issue.Title != "Decode IssuesEvent" {
+					return fmt.Errorf("unexpected issue: %#v", issue)
+				}
+				return nil
+			},
+		},
+	}
+
+	for i, tt := range tests {
+		got, err := tt.event.Decode()
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("#%d: expected a non-nil error", i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("#%d: %v", i, err)
+			continue
+		}
+		if err := tt.check(got); err != nil {
+			t.Errorf("#%d: %v", i, err)
+		}
+	}
+}
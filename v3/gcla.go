@@ -16,12 +16,17 @@ package gcla
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
+	"mime"
 	"net/http"
+	"net/url"
 	"reflect"
+	"strings"
 	"sync"
 	"time"
 
@@ -31,22 +36,122 @@ import (
 type Event string
 
 const (
-	EventIssues      Event = "issues"
-	EventPush        Event = "push"
-	EventPullRequest Event = "pull_request"
+	EventDependabotAlert              Event = "dependabot_alert"
+	EventDiscussion                   Event = "discussion"
+	EventDiscussionComment            Event = "discussion_comment"
+	EventGitHubAppAuthorization       Event = "github_app_authorization"
+	EventInstallationTarget           Event = "installation_target"
+	EventIssueComment                 Event = "issue_comment"
+	EventIssues                       Event = "issues"
+	EventMergeGroup                   Event = "merge_group"
+	EventOrganization                 Event = "organization"
+	EventPing                         Event = "ping"
+	EventPush                         Event = "push"
+	EventPullRequest                  Event = "pull_request"
+	EventPullRequestReview            Event = "pull_request_review"
+	EventPullRequestReviewComment     Event = "pull_request_review_comment"
+	EventRelease                      Event = "release"
+	EventRepository                   Event = "repository"
+	EventRepositoryVulnerabilityAlert Event = "repository_vulnerability_alert"
+	EventSecretScanningAlert          Event = "secret_scanning_alert"
+	EventStar                         Event = "star"
+	EventStatus                       Event = "status"
+	EventTeam                         Event = "team"
+	EventTeamAdd                      Event = "team_add"
+	EventWatch                        Event = "watch"
 )
 
 type Client struct {
 	mu sync.RWMutex
 	rt http.RoundTripper
 
-	apiKey string
+	apiKey     string
+	appSigner  AppSigner
+	userAgent  string
+	logger     Logger
+	logQueries bool
+
+	hookConfig *PayloadConfig
+
+	// defaultBranches is only set by WithDefaultBranchCache.
+	defaultBranches map[string]string
+	repoCache       RepoCache
+
+	rateLimit    RateLimit
+	hasRateLimit bool
+	requestID    string
+
+	transportCfg transportConfig
+	transport    *http.Transport
+	maxRedirects int
+
+	// clock is only set by WithClock.
+	clock func() time.Time
+}
+
+// ClientOption configures a Client when it is created.
+type ClientOption func(*Client)
+
+// WithClock makes a Client tell the time with now instead of time.Now,
+// for example to test the logic that depends on rate limit resets or
+// the deadline of RepoSubscribeRequest.VerifyTimeout.
+func WithClock(now func() time.Time) ClientOption {
+	return func(c *Client) {
+		c.clock = now
+	}
+}
+
+func (c *Client) now() time.Time {
+	c.mu.RLock()
+	clock := c.clock
+	c.mu.RUnlock()
+	if clock == nil {
+		return time.Now()
+	}
+	return clock()
+}
+
+// WithUserAgent sets the "User-Agent" header sent with every request.
+// GitHub requires one and recommends that it identifies your app.
+// It defaults to DefaultUserAgent.
+func WithUserAgent(userAgent string) ClientOption {
+	return func(c *Client) {
+		c.userAgent = userAgent
+	}
 }
 
+// WithDefaultHookConfig sets the config of the hooks that SubscribeToRepo
+// creates from a SubscribeRequest whose Config is nil. It saves repeating
+// the payload URL and secret when provisioning many hooks.
+func WithDefaultHookConfig(cfg *PayloadConfig) ClientOption {
+	return func(c *Client) {
+		c.hookConfig = cfg
+	}
+}
+
+func (c *Client) defaultHookConfig() *PayloadConfig {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.hookConfig == nil {
+		return nil
+	}
+	cfg := *c.hookConfig
+	return &cfg
+}
+
+// DefaultUserAgent is the "User-Agent" header sent unless WithUserAgent is used.
+const DefaultUserAgent = "gcla/v3"
+
 type PullRequestEvent struct {
-	Action  Action  `json:"action,omitempty"`
-	Number  uint64  `json:"number,omitempty"`
-	Changes *Change `json:"changes,omitempty"`
+	Action      Action       `json:"action,omitempty"`
+	Number      uint64       `json:"number,omitempty"`
+	Changes     *Change      `json:"changes,omitempty"`
+	PullRequest *PullRequest `json:"pull_request,omitempty"`
+
+	// Only one of RequestedReviewer and RequestedTeam is set, and only
+	// for ActionReviewRequested and ActionReviewRequestRemoved.
+	RequestedReviewer *User `json:"requested_reviewer,omitempty"`
+	RequestedTeam     *Team `json:"requested_team,omitempty"`
 
 	Repository   *Repository   `json:"repository,omitempty"`
 	Sender       *User         `json:"sender,omitempty"`
@@ -78,7 +183,10 @@ type PushEvent struct {
 	// Ref is the full Git ref that was pushed. Example: "refs/heads/master".
 	Ref string `json:"ref,omitempty"`
 	// Head is the SHA of the most recent commit on ref after the push.
+	// It is only set by the Events API, webhook deliveries set After.
 	Head string `json:"head,omitempty"`
+	// After is the SHA of the most recent commit on ref after the push.
+	After string `json:"after,omitempty"`
 	// Before is the SHA of the most recent commit on ref before the push.
 	Before              string `json:"before,omitempty"`
 	CommitCount         uint64 `json:"size,omitempty"`
@@ -102,6 +210,7 @@ type PushEvent struct {
 type ReleaseEvent struct {
 	Action     Action      `json:"action,omitempty"`
 	Release    *Release    `json:"release,omitempty"`
+	Changes    *Change     `json:"changes,omitempty"`
 	Repository *Repository `json:"repository,omitempty"`
 	Sender     *User       `json:"sender,omitempty"`
 }
@@ -131,16 +240,23 @@ type Release struct {
 // + created
 // + made public
 // + made private
+// + edited
+// + renamed
+// + transferred
+// + archived or unarchived
 //
 // Organization hooks are also triggered
 // when a repository is deleted.
+// Changes is only set for the "edited", "renamed" and "transferred" actions.
 // Events of this type are not visible in timelines. These events are only
 // used to trigger hooks.
 type RepositoryEvent struct {
 	Action       Action        `json:"action,omitempty"`
+	Changes      *Change       `json:"changes,omitempty"`
 	Repository   *Repository   `json:"repository,omitempty"`
 	Organization *Organization `json:"organization,omitempty"`
 	Sender       *User         `json:"sender,omitempty"`
+	Installation *Installation `json:"installation,omitempty"`
 }
 
 type Organization struct {
@@ -161,6 +277,7 @@ type Organization struct {
 type StatusEvent struct {
 	SHA         string    `json:"sha,omitempty"`
 	State       State     `json:"state,omitempty"`
+	Context     string    `json:"context,omitempty"`
 	Description string    `json:"description,omitempty"`
 	TargetURL   string    `json:"target_url,omitempty"`
 	Branches    []*Branch `json:"branches,omitempty"`
@@ -176,11 +293,12 @@ type Branch struct {
 type Commit struct {
 	ID        string     `json:"id,omitempty"`
 	TreeID    string     `json:"tree_id,omitempty"`
-	Timestamp *time.Time `json:"timestamp,omitempty"`
+	Timestamp *Timestamp `json:"timestamp,omitempty"`
 	SHA       string     `json:"sha,omitempty"`
 	Commit    *Commit    `json:"commit,omitempty"`
 	Message   string     `json:"message,omitempty"`
 	Author    *Author    `json:"author,omitempty"`
+	Committer *Author    `json:"committer,omitempty"`
 	URL       string     `json:"url,omitempty"`
 	HTMLURL   string     `json:"html_url,omitempty"`
 	Distinct  bool       `json:"distinct,omitempty"`
@@ -199,6 +317,10 @@ type TeamEvent struct {
 	Team       *Team       `json:"team,omitempty"`
 	Changes    *Change     `json:"changes,omitempty"`
 	Repository *Repository `json:"repository,omitempty"`
+
+	Organization *Organization `json:"organization,omitempty"`
+	Sender       *User         `json:"sender,omitempty"`
+	Installation *Installation `json:"installation,omitempty"`
 }
 
 // TeamAddEvent is the payload sent when webhook "team_add" is fired.
@@ -206,6 +328,10 @@ type TeamEvent struct {
 type TeamAddEvent struct {
 	Team       *Team       `json:"team,omitempty"`
 	Repository *Repository `json:"repository,omitempty"`
+
+	Organization *Organization `json:"organization,omitempty"`
+	Sender       *User         `json:"sender,omitempty"`
+	Installation *Installation `json:"installation,omitempty"`
 }
 
 // WatchEvent is the payload sent related to starring a repository, not watching.
@@ -221,6 +347,49 @@ type WatchEvent struct {
 	Sender     *User       `json:"sender,omitempty"`
 }
 
+// PingEvent is the payload sent when webhook "ping" is fired.
+// GitHub sends it right after a hook is created, or when it is pinged
+// explicitly, to confirm that the payload URL is live.
+type PingEvent struct {
+	Zen          string        `json:"zen,omitempty"`
+	HookID       uint64        `json:"hook_id,omitempty"`
+	Hook         *Hook         `json:"hook,omitempty"`
+	Repository   *Repository   `json:"repository,omitempty"`
+	Organization *Organization `json:"organization,omitempty"`
+	Sender       *User         `json:"sender,omitempty"`
+}
+
+// InstallationTargetEvent is the payload sent when webhook
+// "installation_target" is fired. It is triggered when the account
+// that a GitHub App is installed on is renamed.
+type InstallationTargetEvent struct {
+	Action       Action                    `json:"action,omitempty"`
+	Account      *User                     `json:"account,omitempty"`
+	Changes      *InstallationTargetChange `json:"changes,omitempty"`
+	TargetType   Type                      `json:"target_type,omitempty"`
+	Installation *Installation             `json:"installation,omitempty"`
+	Sender       *User                     `json:"sender,omitempty"`
+}
+
+type InstallationTargetChange struct {
+	Login *ChangeFrom `json:"login,omitempty"`
+	Slug  *ChangeFrom `json:"slug,omitempty"`
+}
+
+// ChangeFrom holds the value that an attribute had before it was changed.
+type ChangeFrom struct {
+	From string `json:"from,omitempty"`
+}
+
+// GitHubAppAuthorizationEvent is the payload sent when webhook
+// "github_app_authorization" is fired. It is triggered when
+// a user revokes their authorization of a GitHub App, after which
+// any user-to-server tokens stored for Sender are no longer valid.
+type GitHubAppAuthorizationEvent struct {
+	Action Action `json:"action,omitempty"`
+	Sender *User  `json:"sender,omitempty"`
+}
+
 type Author struct {
 	Name  string `json:"name,omitempty"`
 	Email string `json:"email,omitempty"`
@@ -241,23 +410,77 @@ type Review struct {
 type State string
 
 const (
-	StateActive   State = "active"
-	StateApproved State = "approved"
-	StateOpen     State = "open"
-	StateSuccess  State = "success"
+	StateActive    State = "active"
+	StateApproved  State = "approved"
+	StateClosed    State = "closed"
+	StateDismissed State = "dismissed"
+	StateError     State = "error"
+	StateFailure   State = "failure"
+	StateFixed     State = "fixed"
+	StateLocked    State = "locked"
+	StateOpen      State = "open"
+	StatePending   State = "pending"
+	StateResolved  State = "resolved"
+	StateSuccess   State = "success"
 )
 
+// Change holds the previous values of the attributes that were
+// changed by an "edited" action. Unchanged attributes are nil.
 type Change struct {
-	Title       string  `json:"title,omitempty"`
-	Body        string  `json:"body,omitempty"`
-	Description string  `json:"description,omitempty"`
-	Name        string  `json:"name,omitempty"`
-	Privacy     Privacy `json:"privacy,omitempty"`
+	Title       *ChangeFrom `json:"title,omitempty"`
+	Body        *ChangeFrom `json:"body,omitempty"`
+	Description *ChangeFrom `json:"description,omitempty"`
+	Name        *ChangeFrom `json:"name,omitempty"`
+	Privacy     *ChangeFrom `json:"privacy,omitempty"`
+	TagName     *ChangeFrom `json:"tag_name,omitempty"`
+
+	DefaultBranch *ChangeFrom `json:"default_branch,omitempty"`
+	Homepage      *ChangeFrom `json:"homepage,omitempty"`
+
+	// Repository is set when a team's permissions on the
+	// event's repository were changed, or when a repository
+	// was renamed.
+	Repository *RepositoryChange `json:"repository,omitempty"`
+
+	// Owner is set when a repository was transferred.
+	Owner *OwnerChange `json:"owner,omitempty"`
+}
+
+type RepositoryChange struct {
+	Permissions *PermissionsChange `json:"permissions,omitempty"`
+	// Name holds the repository's name before it was renamed.
+	Name *ChangeFrom `json:"name,omitempty"`
+}
+
+// OwnerChange holds the account that owned a repository
+// before it was transferred. Only one of User and Organization is set.
+type OwnerChange struct {
+	From *OwnerFrom `json:"from,omitempty"`
+}
+
+type OwnerFrom struct {
+	User         *User         `json:"user,omitempty"`
+	Organization *Organization `json:"organization,omitempty"`
+}
+
+// PermissionsChange holds the permissions that a team had before they were changed.
+type PermissionsChange struct {
+	From *Permissions `json:"from,omitempty"`
+}
+
+// Permissions are the permissions of a user or team on a repository.
+type Permissions struct {
+	Admin    bool `json:"admin"`
+	Maintain bool `json:"maintain"`
+	Push     bool `json:"push"`
+	Triage   bool `json:"triage"`
+	Pull     bool `json:"pull"`
 }
 
 type Privacy string
 
 const (
+	PrivacyClosed Privacy = "closed"
 	PrivacyEdited Privacy = "edited"
 	PrivacyPublic Privacy = "public"
 	PrivacySecret Privacy = "secret"
@@ -266,6 +489,7 @@ const (
 type PullRequest struct {
 	URL            string               `json:"url,omitempty"`
 	ID             uint64               `json:"id,omitempty"`
+	NodeID         string               `json:"node_id,omitempty"`
 	HTMLURL        string               `json:"html_url,omitempty"`
 	DiffURL        string               `json:"diff_url,omitempty"`
 	PatchURL       string               `json:"patch_url,omitempty"`
@@ -273,6 +497,7 @@ type PullRequest struct {
 	Number         uint64               `json:"number,omitempty"`
 	State          State                `json:"state,omitempty"`
 	Locked         bool                 `json:"locked,omitempty"`
+	Draft          bool                 `json:"draft,omitempty"`
 	Title          string               `json:"title,omitempty"`
 	User           *User                `json:"user,omitempty"`
 	Body           string               `json:"body,omitempty"`
@@ -281,16 +506,26 @@ type PullRequest struct {
 	ClosedAt       *time.Time           `json:"closed_at,omitempty"`
 	MergedAt       *time.Time           `json:"merged_at,omitempty"`
 	MergeCommitSHA otils.NullableString `json:"merge_commit_sha,omitempty"`
-	Assignee       *User                `json:"assignee,omitempty"`
 	Milestone      *Milestone           `json:"milestone,omitempty"`
 	CommitsURL     string               `json:"commits_url,omitempty"`
 
+	// Assignee is the first of Assignees.
+	//
+	// Deprecated: pull requests can have multiple assignees, use Assignees.
+	Assignee  *User   `json:"assignee,omitempty"`
+	Assignees []*User `json:"assignees,omitempty"`
+
+	// RequestedReviewers and RequestedTeams are those whose
+	// review was requested but who haven't submitted one yet.
+	RequestedReviewers []*User `json:"requested_reviewers,omitempty"`
+	RequestedTeams     []*Team `json:"requested_teams,omitempty"`
+
 	ReviewCommentURL  otils.NullableString `json:"review_comment_url,omitempty"`
 	ReviewCommentsURL otils.NullableString `json:"review_comments_url,omitempty"`
 	CommentsURL       otils.NullableString `json:"comments_url,omitempty"`
 	StatusesURL       otils.NullableString `json:"statuses_url,omitempty"`
 
-	Head *Head `json:"base,omitempty"`
+	Head *Head `json:"head,omitempty"`
 	Base *Head `json:"base,omitempty"`
 
 	Links          *Links               `json:"_links,omitempty"`
@@ -319,8 +554,21 @@ const (
 	TypeUser         Type = "User"
 	TypeOrganization Type = "Organization"
 	TypeApp          Type = "App"
+	TypeBot          Type = "Bot"
 )
 
+// Valid reports whether t is one of the known Type values.
+// Decoding never fails on an unknown type, such as one that GitHub
+// introduces later, because the raw value is preserved as is.
+func (t Type) Valid() bool {
+	switch t {
+	case TypeUser, TypeOrganization, TypeApp, TypeBot:
+		return true
+	default:
+		return false
+	}
+}
+
 type User struct {
 	Username          string `json:"login,omitempty"`
 	ID                int64  `json:"id,omitempty"`
@@ -346,9 +594,12 @@ type Repository struct {
 	FullName         string               `json:"full_name,omitempty"`
 	Owner            *User                `json:"owner,omitempty"`
 	Private          bool                 `json:"private,omitempty"`
+	Visibility       Visibility           `json:"visibility,omitempty"`
 	HTMLURL          string               `json:"html_url,omitempty"`
 	Description      string               `json:"description,omitempty"`
 	Fork             bool                 `json:"fork,omitempty"`
+	Archived         bool                 `json:"archived,omitempty"`
+	Disabled         bool                 `json:"disabled,omitempty"`
 	URL              string               `json:"url,omitempty"`
 	ForksURL         string               `json:"forks_url,omitempty"`
 	KeysURL          string               `json:"keys_url,omitempty"`
@@ -385,9 +636,9 @@ type Repository struct {
 	NotificationsURL string               `json:"notifications_url,omitempty"`
 	LabelsURL        string               `json:"labels_url,omitempty"`
 	ReleasesURL      string               `json:"releases_url,omitempty"`
-	CreatedAt        *time.Time           `json:"created_at,omitempty"`
-	UpdatedAt        *time.Time           `json:"updated_at,omitempty"`
-	PushedAt         *time.Time           `json:"pushed_at,omitempty"`
+	CreatedAt        *Timestamp           `json:"created_at,omitempty"`
+	UpdatedAt        *Timestamp           `json:"updated_at,omitempty"`
+	PushedAt         *Timestamp           `json:"pushed_at,omitempty"`
 	GitURL           string               `json:"git_url,omitempty"`
 	SSHURL           string               `json:"ssh_url,omitempty"`
 	CloneURL         string               `json:"clone_url,omitempty"`
@@ -408,21 +659,65 @@ type Repository struct {
 	OpenIssueCount   uint64               `json:"open_issues,omitempty"`
 	Watchers         uint64               `json:"watchers,omitempty"`
 	DefaultBranch    string               `json:"default_branch,omitempty"`
+	License          *License             `json:"license,omitempty"`
+
+	// Parent and Source are only set for forks, and only when the
+	// repository is retrieved on its own e.g. with GetRepository.
+	// Parent is the repository that this one was forked from and
+	// Source is the root of the network of forks, which is the same
+	// as Parent unless Parent is itself a fork.
+	Parent *Repository `json:"parent,omitempty"`
+	Source *Repository `json:"source,omitempty"`
 }
 
+// License is the license that GitHub detected for a repository.
+type License struct {
+	// Key is GitHub's identifier e.g. "apache-2.0".
+	Key  string `json:"key,omitempty"`
+	Name string `json:"name,omitempty"`
+	// SPDXID is the SPDX identifier e.g. "Apache-2.0", or "NOASSERTION"
+	// if GitHub detected a license file that it couldn't identify.
+	SPDXID string `json:"spdx_id,omitempty"`
+	URL    string `json:"url,omitempty"`
+	NodeID string `json:"node_id,omitempty"`
+}
+
+// Links holds the hypermedia links that GitHub returns under "_links"
+// for pull requests, reviews and review comments. Which of them are set
+// depends on the kind of object.
 type Links struct {
-	Self           *Links `json:"self,omitempty"`
-	HTML           *Links `json:"html,omitempty"`
-	Issue          *Links `json:"issue,omitempty"`
-	Comments       *Links `json:"comments,omitempty"`
-	ReviewComments *Links `json:"review_comments,omitempty"`
-	ReviewComment  *Links `json:"review_comment,omitempty"`
-	Commits        *Links `json:"commits,omitempty"`
-	Statuses       *Links `json:"statuses,omitempty"`
+	Self           *Link `json:"self,omitempty"`
+	HTML           *Link `json:"html,omitempty"`
+	Issue          *Link `json:"issue,omitempty"`
+	Comments       *Link `json:"comments,omitempty"`
+	ReviewComments *Link `json:"review_comments,omitempty"`
+	ReviewComment  *Link `json:"review_comment,omitempty"`
+	Commits        *Link `json:"commits,omitempty"`
+	Statuses       *Link `json:"statuses,omitempty"`
+	PullRequest    *Link `json:"pull_request,omitempty"`
+}
+
+// Link is a single entry of Links.
+type Link struct {
+	Href string `json:"href,omitempty"`
 }
 
 type Installation struct {
 	ID uint64 `json:"id,omitempty"`
+
+	// The fields below are only set when listing
+	// installations with ListInstallations.
+
+	// Account is the user or organization
+	// that the GitHub App is installed on.
+	Account *User  `json:"account,omitempty"`
+	AppID   uint64 `json:"app_id,omitempty"`
+	AppSlug string `json:"app_slug,omitempty"`
+	// TargetType is the Type of Account, TypeUser or TypeOrganization.
+	TargetType Type       `json:"target_type,omitempty"`
+	TargetID   uint64     `json:"target_id,omitempty"`
+	CreatedAt  *time.Time `json:"created_at,omitempty"`
+	UpdatedAt  *time.Time `json:"updated_at,omitempty"`
 }
 
 type Comment struct {
@@ -440,22 +735,60 @@ type Comment struct {
 	HTMLURL          string     `json:"html_url,omitempty"`
 	PullRequestURL   string     `json:"pull_request_url,omitempty"`
 	Links            *Links     `json:"_links,omitempty"`
+	// InReplyToID is the ID of the review comment that this one
+	// replies to, or 0 for the comment that starts a thread.
+	InReplyToID uint64 `json:"in_reply_to_id,omitempty"`
+
+	// Line is the line of Path that the comment refers to, in CommitID
+	// if Side is "RIGHT" or in the base if it is "LEFT". OriginalLine
+	// is its counterpart in OriginalCommitID. GitHub leaves them out
+	// for outdated comments and for comments made before it had them.
+	Line         uint64 `json:"line,omitempty"`
+	OriginalLine uint64 `json:"original_line,omitempty"`
+	Side         string `json:"side,omitempty"`
 }
 
 type Action string
 
 const (
-	ActionAdded         Action = "added"
-	ActionBlocked       Action = "blocked"
-	ActionChanged       Action = "changed"
-	ActionCreated       Action = "created"
-	ActionDeleted       Action = "deleted"
-	ActionMemberInvited Action = "member_invited"
-	ActionOpened        Action = "opened"
-	ActionPublished     Action = "published"
-	ActionRemoved       Action = "removed"
-	ActionStarted       Action = "started"
-	ActionSubmitted     Action = "submitted"
+	ActionAdded                Action = "added"
+	ActionAnswered             Action = "answered"
+	ActionArchived             Action = "archived"
+	ActionAssigned             Action = "assigned"
+	ActionBlocked              Action = "blocked"
+	ActionChanged              Action = "changed"
+	ActionChecksRequested      Action = "checks_requested"
+	ActionClosed               Action = "closed"
+	ActionConvertedToDraft     Action = "converted_to_draft"
+	ActionCreate               Action = "create"
+	ActionCreated              Action = "created"
+	ActionDeleted              Action = "deleted"
+	ActionDestroyed            Action = "destroyed"
+	ActionDismiss              Action = "dismiss"
+	ActionDismissed            Action = "dismissed"
+	ActionEdited               Action = "edited"
+	ActionFixed                Action = "fixed"
+	ActionLabeled              Action = "labeled"
+	ActionMemberInvited        Action = "member_invited"
+	ActionOpened               Action = "opened"
+	ActionPublished            Action = "published"
+	ActionReadyForReview       Action = "ready_for_review"
+	ActionRemoved              Action = "removed"
+	ActionRenamed              Action = "renamed"
+	ActionReopen               Action = "reopen"
+	ActionReopened             Action = "reopened"
+	ActionResolve              Action = "resolve"
+	ActionResolved             Action = "resolved"
+	ActionReviewRequested      Action = "review_requested"
+	ActionReviewRequestRemoved Action = "review_request_removed"
+	ActionRevoked              Action = "revoked"
+	ActionStarted              Action = "started"
+	ActionSubmitted            Action = "submitted"
+	ActionTransferred          Action = "transferred"
+	ActionUnanswered           Action = "unanswered"
+	ActionUnarchived           Action = "unarchived"
+	ActionUnassigned           Action = "unassigned"
+	ActionUnlabeled            Action = "unlabeled"
 )
 
 type Milestone struct {
@@ -478,9 +811,17 @@ type Milestone struct {
 	Sender       *User                `json:"sender,omitempty"`
 }
 
+// OrganizationEvent is the payload sent when webhook "organization" is fired.
+// This event is triggered when an organization is renamed or deleted, or
+// when a user is invited to, added to or removed from an organization.
 type OrganizationEvent struct {
 	Action     Action      `json:"action,omitempty"`
 	Invitation *Invitation `json:"invitation,omitempty"`
+	Membership *Membership `json:"membership,omitempty"`
+
+	Organization *Organization `json:"organization,omitempty"`
+	Sender       *User         `json:"sender,omitempty"`
+	Installation *Installation `json:"installation,omitempty"`
 }
 
 type Invitation struct {
@@ -521,7 +862,14 @@ type Hook struct {
 	UpdatedAt *time.Time     `json:"updated_at,omitempty"`
 	CreatedAt *time.Time     `json:"created_at,omitempty"`
 	Type      Type           `json:"type,omitempty"`
-	AppID     string         `json:"app_id,omitempty"`
+
+	// The fields below are only set for
+	// hooks of Type TypeApp, identifying
+	// the GitHub App that owns the hook.
+	AppID    string `json:"app_id,omitempty"`
+	AppSlug  string `json:"app_slug,omitempty"`
+	AppName  string `json:"app_name,omitempty"`
+	AppOwner *User  `json:"app_owner,omitempty"`
 }
 
 type RepoSubscribeRequest struct {
@@ -529,14 +877,78 @@ type RepoSubscribeRequest struct {
 	Repo  string
 
 	HookSubscription *SubscribeRequest
+
+	// VerifyActive if set, pings the hook right after it is created
+	// and then checks that GitHub reports it as active and that the
+	// ping was answered with a 2XX status code. GitHub delivers the
+	// ping asynchronously, so its LastResponse is polled for up to
+	// VerifyTimeout. This catches misconfigured payload URLs early.
+	// An inactive hook is reported as an *InactiveHookError and a
+	// failed or unanswered ping as a *HookPingError, both leaving the
+	// hook for the caller to clean up.
+	VerifyActive bool
+
+	// VerifyTimeout bounds how long VerifyActive waits for
+	// GitHub to deliver the ping. If zero, it defaults to 10s.
+	VerifyTimeout time.Duration
+
+	// VerifyReachable if set, sends a HEAD request to the payload URL
+	// before creating the hook, which isn't created if the request fails
+	// or is answered with a 5XX status code. The failure is reported as
+	// an *UnreachablePayloadURLError. It is opt-in since some endpoints
+	// reject HEAD requests or are only reachable from GitHub.
+	VerifyReachable bool
+
+	// AllowHTTP if set, accepts http and loopback payload URLs,
+	// which ValidatePayloadURL rejects. It is only useful for tests.
+	AllowHTTP bool
 }
 
+// HookNameWeb is the name that GitHub expects for webhooks.
+const HookNameWeb = "web"
+
 type SubscribeRequest struct {
+	// Name defaults to HookNameWeb, which is what GitHub
+	// expects for webhooks. Other names only apply to the
+	// legacy service hooks and GitHub ignores them otherwise.
 	Name   string  `json:"name,omitempty"`
 	Active bool    `json:"active,omitempty"`
 	Events []Event `json:"events,omitempty"`
 
 	Config *PayloadConfig `json:"config,omitempty"`
+
+	// Secret if set, is serialized as Config.Secret, creating
+	// Config if it is nil, and takes precedence over Config.Secret.
+	// It is a shortcut to avoid having to reach into Config.
+	Secret string `json:"-"`
+
+	// InsecureSSL if set, is serialized as Config.InsecureSSL "1",
+	// creating Config if it is nil. It makes GitHub deliver payloads
+	// without verifying the TLS certificate of Config.URL, for example
+	// for internal endpoints with self-signed certificates. That exposes
+	// deliveries to interception, so only use it for trusted networks.
+	InsecureSSL bool `json:"-"`
+}
+
+func (sr SubscribeRequest) MarshalJSON() ([]byte, error) {
+	// Marshal through an alias type that lacks
+	// this method to avoid infinite recursion.
+	type subscribeRequest SubscribeRequest
+	if sr.Secret != "" || sr.InsecureSSL {
+		// Copy Config so that the caller's isn't modified.
+		cfg := new(PayloadConfig)
+		if sr.Config != nil {
+			*cfg = *sr.Config
+		}
+		if sr.Secret != "" {
+			cfg.Secret = sr.Secret
+		}
+		if sr.InsecureSSL {
+			cfg.InsecureSSL = InsecureSSLEnabled
+		}
+		sr.Config = cfg
+	}
+	return json.Marshal(subscribeRequest(sr))
 }
 
 type Subscription struct {
@@ -549,10 +961,31 @@ type Subscription struct {
 	Active  bool           `json:"active,omitempty"`
 	Config  *PayloadConfig `json:"config,omitempty"`
 
+	// LastResponse is how the payload URL
+	// answered the latest delivery to the hook.
+	LastResponse *HookResponse `json:"last_response,omitempty"`
+
 	UpdatedAt *time.Time `json:"updated_at,omitempty"`
 	CreatedAt *time.Time `json:"created_at,omitempty"`
 }
 
+// HookStatusUnused is the Status of a HookResponse
+// for a hook that nothing was delivered to yet.
+const HookStatusUnused = "unused"
+
+// HookResponse is how a payload URL answered a delivery.
+type HookResponse struct {
+	// Code is the HTTP status code, or 0 if
+	// nothing was delivered or it failed.
+	Code    int    `json:"code,omitempty"`
+	Status  string `json:"status,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+func (hr *HookResponse) delivered() bool {
+	return hr != nil && hr.Status != "" && hr.Status != HookStatusUnused
+}
+
 type ContentType string
 
 const (
@@ -565,6 +998,29 @@ type PayloadConfig struct {
 	URL string `json:"url,omitempty"`
 
 	ContentType ContentType `json:"content_type,omitempty"`
+
+	// Secret is used by GitHub to sign each delivery
+	// with the "X-Hub-Signature-256" header.
+	Secret string `json:"secret,omitempty"`
+
+	// InsecureSSL is either InsecureSSLEnabled or InsecureSSLDisabled,
+	// GitHub's default, see SubscribeRequest.InsecureSSL.
+	InsecureSSL string `json:"insecure_ssl,omitempty"`
+}
+
+// The values of PayloadConfig.InsecureSSL, which GitHub represents as strings.
+const (
+	InsecureSSLDisabled = "0"
+	InsecureSSLEnabled  = "1"
+)
+
+func validateInsecureSSL(value string) error {
+	switch value {
+	case "", InsecureSSLDisabled, InsecureSSLEnabled:
+		return nil
+	default:
+		return fmt.Errorf("invalid insecure_ssl %q, expecting either %q or %q", value, InsecureSSLDisabled, InsecureSSLEnabled)
+	}
 }
 
 const baseURL = "https://api.github.com"
@@ -575,8 +1031,79 @@ var (
 	errBlankSubscription = errors.New("no subscription could be parsed")
 )
 
-func (c *Client) SubscribeToRepo(rsr *RepoSubscribeRequest) (*Subscription, error) {
-	blob, err := json.Marshal(rsr.HookSubscription)
+// decodeSubscription decodes the single subscription in blob. Some
+// proxies wrap GitHub's responses in an array so in that case, the
+// first element of the array is decoded instead.
+func decodeSubscription(blob []byte) (*Subscription, error) {
+	if trimmed := bytes.TrimSpace(blob); len(trimmed) > 0 && trimmed[0] == '[' {
+		var subs []*Subscription
+		if err := json.Unmarshal(trimmed, &subs); err != nil {
+			return nil, err
+		}
+		if len(subs) == 0 || subs[0] == nil {
+			return nil, errBlankSubscription
+		}
+		return subs[0], nil
+	}
+	subs := new(Subscription)
+	if err := json.Unmarshal(blob, subs); err != nil {
+		return nil, err
+	}
+	return subs, nil
+}
+
+var (
+	errNilRepoSubscribeRequest = errors.New("expecting a non-nil RepoSubscribeRequest")
+	errNilHookSubscription     = errors.New("expecting a non-nil HookSubscription")
+)
+
+func (rsr *RepoSubscribeRequest) validate() error {
+	if rsr == nil {
+		return errNilRepoSubscribeRequest
+	}
+	if rsr.Owner == "" {
+		return errEmptyOwner
+	}
+	if rsr.Repo == "" {
+		return errEmptyRepo
+	}
+	if rsr.HookSubscription == nil {
+		return errNilHookSubscription
+	}
+	return nil
+}
+
+// SubscribeToRepoRequest validates rsr and builds the request that
+// SubscribeToRepo would send to create the hook, without sending it.
+// It is useful to preview the URL and body before creating hooks in bulk.
+// The authentication headers are only added once the request is sent.
+func (c *Client) SubscribeToRepoRequest(rsr *RepoSubscribeRequest) (*http.Request, error) {
+	if err := rsr.validate(); err != nil {
+		return nil, err
+	}
+	// Work on a copy so that the defaults aren't stored in the caller's request.
+	sr := *rsr.HookSubscription
+	if sr.Name == "" {
+		sr.Name = HookNameWeb
+	}
+	if sr.Config == nil {
+		sr.Config = c.defaultHookConfig()
+	}
+	// A missing URL is left for GitHub to report.
+	if sr.Config != nil && sr.Config.URL != "" {
+		if err := validatePayloadURL(sr.Config.URL, rsr.AllowHTTP); err != nil {
+			return nil, err
+		}
+	}
+	if sr.Config != nil {
+		if err := validateInsecureSSL(sr.Config.InsecureSSL); err != nil {
+			return nil, err
+		}
+	}
+	if sr.InsecureSSL || (sr.Config != nil && sr.Config.InsecureSSL == InsecureSSLEnabled) {
+		c.log().Printf("warning: the hook for %s/%s won't verify TLS certificates", rsr.Owner, rsr.Repo)
+	}
+	blob, err := json.Marshal(sr)
 	if err != nil {
 		return nil, err
 	}
@@ -585,46 +1112,510 @@ func (c *Client) SubscribeToRepo(rsr *RepoSubscribeRequest) (*Subscription, erro
 	if err != nil {
 		return nil, err
 	}
-	blob, _, err = c.doHTTPReq(req)
+	return req, nil
+}
+
+func (c *Client) SubscribeToRepo(rsr *RepoSubscribeRequest) (*Subscription, error) {
+	req, err := c.SubscribeToRepoRequest(rsr)
 	if err != nil {
 		return nil, err
 	}
-	subs := new(Subscription)
-	if err := json.Unmarshal(blob, subs); err != nil {
+	if rsr.VerifyReachable {
+		cfg := rsr.HookSubscription.Config
+		if cfg == nil {
+			cfg = c.defaultHookConfig()
+		}
+		if cfg != nil && cfg.URL != "" {
+			if err := c.probePayloadURL(cfg.URL); err != nil {
+				return nil, err
+			}
+		}
+	}
+	blob, _, err := c.doHTTPReq(req)
+	if err != nil {
+		return nil, err
+	}
+	subs, err := decodeSubscription(blob)
+	if err != nil {
 		return nil, err
 	}
 	if reflect.DeepEqual(subs, blankSubscription) {
 		return nil, errBlankSubscription
 	}
-	return subs, nil
+	if !rsr.VerifyActive {
+		return subs, nil
+	}
+
+	if err := c.PingHook(rsr.Owner, rsr.Repo, subs.ID); err != nil {
+		return subs, err
+	}
+	latest, err := c.awaitPing(rsr.Owner, rsr.Repo, subs.ID, rsr.VerifyTimeout)
+	if err != nil {
+		return subs, err
+	}
+	if !latest.Active {
+		return latest, &InactiveHookError{Hook: latest}
+	}
+	if lr := latest.LastResponse; !lr.delivered() || lr.Code < 200 || lr.Code > 299 {
+		return latest, &HookPingError{Hook: latest}
+	}
+	return latest, nil
+}
+
+const defaultVerifyTimeout = 10 * time.Second
+
+// awaitPing polls the hook until GitHub reports a response to the
+// ping that was just sent to it, or until timeout runs out according
+// to the client's clock. In either case, it returns the latest state
+// of the hook.
+func (c *Client) awaitPing(owner, repo string, hookID uint64, timeout time.Duration) (*Subscription, error) {
+	if timeout <= 0 {
+		timeout = defaultVerifyTimeout
+	}
+	deadline := c.now().Add(timeout)
+	backoff := &Backoff{Base: 250 * time.Millisecond, Max: 2 * time.Second}
+	for {
+		hook, err := c.GetHook(owner, repo, hookID)
+		if err != nil {
+			return nil, err
+		}
+		left := deadline.Sub(c.now())
+		if hook.LastResponse.delivered() || left <= 0 {
+			return hook, nil
+		}
+		d := backoff.Next()
+		if d > left {
+			d = left
+		}
+		time.Sleep(d)
+	}
+}
+
+// InactiveHookError is returned when a hook that was
+// expected to be active is reported as inactive by GitHub.
+type InactiveHookError struct {
+	Hook *Subscription
+}
+
+func (ihe *InactiveHookError) Error() string {
+	var payloadURL string
+	if cfg := ihe.Hook.Config; cfg != nil {
+		payloadURL = cfg.URL
+	}
+	return fmt.Sprintf("hook %d was created but is inactive, check its config and payload URL %q", ihe.Hook.ID, payloadURL)
+}
+
+// HookPingError is returned when the payload URL of a hook that was just
+// created either answered its ping with a non-2XX status code or didn't
+// answer it in time, as reported by the hook's LastResponse.
+type HookPingError struct {
+	Hook *Subscription
+}
+
+func (hpe *HookPingError) Error() string {
+	var payloadURL string
+	if cfg := hpe.Hook.Config; cfg != nil {
+		payloadURL = cfg.URL
+	}
+	lr := hpe.Hook.LastResponse
+	if !lr.delivered() {
+		return fmt.Sprintf("hook %d was created but its ping to payload URL %q wasn't delivered in time", hpe.Hook.ID, payloadURL)
+	}
+	return fmt.Sprintf("hook %d was created but its ping to payload URL %q failed: %d %s: %s", hpe.Hook.ID, payloadURL, lr.Code, lr.Status, lr.Message)
 }
 
 func (c *Client) doHTTPReq(req *http.Request) ([]byte, http.Header, error) {
+	res, err := c.sendHTTPReq(req)
+	if err != nil {
+		if res != nil {
+			return nil, res.Header, err
+		}
+		return nil, nil, err
+	}
+	defer res.Body.Close()
+	blob, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, res.Header, err
+	}
+	return blob, res.Header, nil
+}
+
+// boolRequest sends a request without a body to path, relative to
+// baseURL, of the endpoints that answer a yes-or-no question with
+// "204 No Content" for yes and "404 Not Found" for no. Any other
+// error, for example "500 Internal Server Error", is returned as is.
+func (c *Client) boolRequest(method, path string) (bool, error) {
+	req, err := http.NewRequest(method, baseURL+path, nil)
+	if err != nil {
+		return false, err
+	}
+	_, _, err = c.doHTTPReq(req)
+	switch {
+	case err == nil:
+		return true, nil
+	case isStatusCode(err, http.StatusNotFound):
+		return false, nil
+	default:
+		return false, err
+	}
+}
+
+// streamDecoder is implemented by the types of large responses
+// that can be decoded piecemeal, for example one array element at
+// a time, so that the whole response is never held in memory.
+type streamDecoder interface {
+	decodeStream(dec *json.Decoder) error
+}
+
+// doHTTPReqJSON is like doHTTPReq but decodes the response body into recv
+// as it is read. Unless recv is a streamDecoder, json.Decoder still buffers
+// the whole body so it only pays off for the types that implement it.
+// Webhook payloads can't be decoded this way since they must be verified
+// before they are trusted.
+func (c *Client) doHTTPReqJSON(req *http.Request, recv interface{}) (http.Header, error) {
+	res, err := c.sendHTTPReq(req)
+	if err != nil {
+		if res != nil {
+			return res.Header, err
+		}
+		return nil, err
+	}
+	defer res.Body.Close()
+	dec := json.NewDecoder(res.Body)
+	if sd, ok := recv.(streamDecoder); ok {
+		err = sd.decodeStream(dec)
+	} else {
+		err = dec.Decode(recv)
+	}
+	if err != nil {
+		return res.Header, err
+	}
+	// Drain any trailing whitespace so that the connection can be reused.
+	io.Copy(ioutil.Discard, res.Body)
+	return res.Header, nil
+}
+
+// expectDelim reads the next token from dec and checks that it is delim.
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if got, ok := tok.(json.Delim); !ok || got != delim {
+		return fmt.Errorf("expecting %q, got %v", delim, tok)
+	}
+	return nil
+}
+
+// sendHTTPReq sends req, authenticated, and returns GitHub's response if
+// its status code is in the 2XX range. Otherwise the response, whose body
+// is then closed, is returned alongside the error that describes it.
+func (c *Client) sendHTTPReq(req *http.Request) (*http.Response, error) {
 	// Ensure that we set the header version in the request
 	// as recommended at https://developer.github.com/v3/#current-version
 	req.Header.Add("Accept", "application/vnd.github.v3+json")
 
 	c.mu.RLock()
-	if apiKey := c.apiKey; apiKey != "" {
+	// Requests that authenticate as a GitHub App set their own.
+	if apiKey := c.apiKey; apiKey != "" && req.Header.Get("Authorization") == "" {
 		req.Header.Add("Authorization", fmt.Sprintf("token %s", apiKey))
 	}
+	userAgent := c.userAgent
 	c.mu.RUnlock()
 
+	if userAgent == "" {
+		userAgent = DefaultUserAgent
+	}
+	req.Header.Set("User-Agent", userAgent)
+
 	res, err := c.httpClient().Do(req)
 	if err != nil {
-		return nil, nil, err
+		// Log the cause alone since *url.Error repeats the unsanitized URL.
+		cause := err
+		if ue, ok := err.(*url.Error); ok {
+			cause = ue.Err
+		}
+		c.log().Printf("%s %s: %v", req.Method, c.logURL(req.URL), cause)
+		if tmre, ok := cause.(*TooManyRedirectsError); ok {
+			return nil, tmre
+		}
+		return nil, err
 	}
-	if res.Body != nil {
-		defer res.Body.Close()
+	if res.Body == nil {
+		res.Body = http.NoBody
 	}
+	rl, hasRateLimit := c.recordRateLimit(res.Header)
+	c.recordRequestID(res.Header)
 	if !otils.StatusOK(res.StatusCode) {
-		return nil, res.Header, errors.New(res.Status)
+		defer res.Body.Close()
+		c.log().Printf("%s %s: %s", req.Method, c.logURL(req.URL), res.Status)
+		if isRedirect(res.StatusCode) {
+			if rre := c.repoRenamedError(req, res); rre != nil {
+				return res, rre
+			}
+		}
+		blob, _ := ioutil.ReadAll(res.Body)
+		ae := newAPIError(res, blob)
+		if hasRateLimit && rl.Remaining == 0 && (res.StatusCode == http.StatusForbidden || res.StatusCode == http.StatusTooManyRequests) {
+			return res, &RateLimitError{RateLimit: rl, Err: ae}
+		}
+		if ure := unexpectedResponseError(res, blob); ure != nil {
+			return res, ure
+		}
+		return res, ae
 	}
-	blob, err := ioutil.ReadAll(res.Body)
-	if err != nil {
-		return nil, res.Header, err
+	if isHTML(res.Header) {
+		// Proxies and GitHub's own error pages aren't
+		// always served with an error status code.
+		defer res.Body.Close()
+		c.log().Printf("%s %s: %s with an HTML body", req.Method, c.logURL(req.URL), res.Status)
+		// Leave room for the whitespace that the snippet collapses.
+		blob, _ := ioutil.ReadAll(io.LimitReader(res.Body, 4*maxSnippetLen))
+		return res, newUnexpectedResponseError(res, blob)
 	}
-	return blob, res.Header, nil
+	return res, nil
+}
+
+// APIError is returned whenever GitHub responds
+// with a status code outside of the 2XX range.
+type APIError struct {
+	StatusCode int    `json:"-"`
+	Status     string `json:"-"`
+	// RequestID is the "X-GitHub-Request-Id" header, to quote
+	// when contacting GitHub support about the failure.
+	RequestID string `json:"-"`
+
+	Message          string `json:"message,omitempty"`
+	DocumentationURL string `json:"documentation_url,omitempty"`
+
+	// Errors pinpoints what GitHub rejected, typically
+	// with a "422 Unprocessable Entity" status.
+	Errors []*FieldError `json:"errors,omitempty"`
+}
+
+var _ error = (*APIError)(nil)
+
+func (ae *APIError) Error() string {
+	msg := ae.Status
+	if ae.Message != "" {
+		msg = fmt.Sprintf("%s: %s", msg, ae.Message)
+	}
+	if len(ae.Errors) > 0 {
+		fieldErrs := make([]string, 0, len(ae.Errors))
+		for _, fe := range ae.Errors {
+			fieldErrs = append(fieldErrs, fe.Error())
+		}
+		msg = fmt.Sprintf("%s (%s)", msg, strings.Join(fieldErrs, "; "))
+	}
+	if ae.RequestID != "" {
+		msg = fmt.Sprintf("%s [request ID %s]", msg, ae.RequestID)
+	}
+	return msg
+}
+
+// FieldError describes why GitHub rejected a field of a request.
+// Code is one of "missing", "missing_field", "invalid", "already_exists",
+// "unprocessable" or "custom", in which case Message explains it.
+type FieldError struct {
+	Resource string `json:"resource,omitempty"`
+	Field    string `json:"field,omitempty"`
+	Code     string `json:"code,omitempty"`
+	Message  string `json:"message,omitempty"`
+}
+
+func (fe *FieldError) Error() string {
+	var parts []string
+	if fe.Resource != "" || fe.Field != "" {
+		parts = append(parts, strings.Trim(fe.Resource+"."+fe.Field, "."))
+	}
+	for _, part := range []string{fe.Code, fe.Message} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, ": ")
+}
+
+func (fe *FieldError) UnmarshalJSON(b []byte) error {
+	// Some endpoints report errors as plain strings instead of objects.
+	var msg string
+	if err := json.Unmarshal(b, &msg); err == nil {
+		*fe = FieldError{Message: msg}
+		return nil
+	}
+	type fieldError FieldError
+	return json.Unmarshal(b, (*fieldError)(fe))
+}
+
+func newAPIError(res *http.Response, body []byte) *APIError {
+	ae := &APIError{
+		StatusCode: res.StatusCode,
+		Status:     res.Status,
+		RequestID:  res.Header.Get(HeaderRequestID),
+	}
+	// The body is only informational, so decoding it is best effort.
+	_ = json.Unmarshal(body, ae)
+	return ae
+}
+
+// UnexpectedResponseError is returned instead of an *APIError, or of a
+// cryptic JSON syntax error, when GitHub responds with something other
+// than JSON, typically an HTML error page served during an incident.
+type UnexpectedResponseError struct {
+	StatusCode  int
+	Status      string
+	ContentType string
+	RequestID   string
+	// Snippet is the start of the body, truncated
+	// to maxSnippetLen bytes with "..." appended.
+	Snippet string
+}
+
+func (ure *UnexpectedResponseError) Error() string {
+	msg := fmt.Sprintf("%s: unexpected %q response: %q", ure.Status, ure.ContentType, ure.Snippet)
+	if ure.RequestID != "" {
+		msg = fmt.Sprintf("%s [request ID %s]", msg, ure.RequestID)
+	}
+	return msg
+}
+
+const maxSnippetLen = 256
+
+// unexpectedResponseError returns the *UnexpectedResponseError
+// for body, the body of the non-2XX res, or nil if body is empty
+// or is JSON, even if it was served with another content type.
+func unexpectedResponseError(res *http.Response, body []byte) *UnexpectedResponseError {
+	if len(bytes.TrimSpace(body)) == 0 || json.Valid(body) {
+		return nil
+	}
+	return newUnexpectedResponseError(res, body)
+}
+
+func newUnexpectedResponseError(res *http.Response, body []byte) *UnexpectedResponseError {
+	snippet := strings.Join(strings.Fields(string(body)), " ")
+	if len(snippet) > maxSnippetLen {
+		snippet = snippet[:maxSnippetLen] + "..."
+	}
+	return &UnexpectedResponseError{
+		StatusCode:  res.StatusCode,
+		Status:      res.Status,
+		ContentType: res.Header.Get("Content-Type"),
+		RequestID:   res.Header.Get(HeaderRequestID),
+		Snippet:     snippet,
+	}
+}
+
+func isHTML(hdr http.Header) bool {
+	mediaType, _, _ := mime.ParseMediaType(hdr.Get("Content-Type"))
+	return mediaType == "text/html"
+}
+
+// HeaderRequestID is the header that GitHub identifies each request with.
+const HeaderRequestID = "X-GitHub-Request-Id"
+
+// LastRequestID returns the ID that GitHub assigned to the latest request,
+// which GitHub support asks for when investigating a failure.
+func (c *Client) LastRequestID() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.requestID
+}
+
+func (c *Client) recordRequestID(hdr http.Header) {
+	if id := hdr.Get(HeaderRequestID); id != "" {
+		c.mu.Lock()
+		c.requestID = id
+		c.mu.Unlock()
+	}
+}
+
+func isStatusCode(err error, code int) bool {
+	return code != 0 && statusCode(err) == code
+}
+
+// isServerError reports whether err is a 5XX response,
+// which is usually transient and worth retrying.
+func isServerError(err error) bool {
+	return statusCode(err) >= 500
+}
+
+// statusCode returns the HTTP status code of the response that
+// caused err, or 0 if err didn't come from an HTTP response.
+func statusCode(err error) int {
+	switch err := err.(type) {
+	case *RateLimitError:
+		if err.Err != nil {
+			return err.Err.StatusCode
+		}
+	case *APIError:
+		return err.StatusCode
+	case *UnexpectedResponseError:
+		return err.StatusCode
+	}
+	return 0
+}
+
+// getAllPages GETs fullURL and then every subsequent page that the "Link"
+// response header points to, handing the body of each page to save.
+func (c *Client) getAllPages(fullURL string, save func(blob []byte) error) error {
+	return c.getAllPagesAuthorized(context.Background(), fullURL, "", save)
+}
+
+// getAllPagesContext is like getAllPages but binds
+// every page request to ctx.
+func (c *Client) getAllPagesContext(ctx context.Context, fullURL string, save func(blob []byte) error) error {
+	return c.getAllPagesAuthorized(ctx, fullURL, "", save)
+}
+
+// getAllPagesAuthorized is like getAllPagesContext but sends authorization
+// as the "Authorization" header of every request, instead of the API key.
+func (c *Client) getAllPagesAuthorized(ctx context.Context, fullURL, authorization string, save func(blob []byte) error) error {
+	for fullURL != "" {
+		req, err := http.NewRequest("GET", fullURL, nil)
+		if err != nil {
+			return err
+		}
+		req = req.WithContext(ctx)
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		blob, hdr, err := c.doHTTPReq(req)
+		if err != nil {
+			return err
+		}
+		if err := save(blob); err != nil {
+			return err
+		}
+		fullURL = parseLinkHeader(hdr.Get("Link"))["next"]
+	}
+	return nil
+}
+
+// parseLinkHeader parses a pagination header such as
+// `<https://api.github.com/...?page=2>; rel="next"` into
+// a map of each relation to its URL.
+func parseLinkHeader(link string) map[string]string {
+	rels := make(map[string]string)
+	for _, part := range strings.Split(link, ",") {
+		segments := strings.Split(part, ";")
+		if len(segments) < 2 {
+			continue
+		}
+		linkURL := strings.TrimSpace(segments[0])
+		if !strings.HasPrefix(linkURL, "<") || !strings.HasSuffix(linkURL, ">") {
+			continue
+		}
+		linkURL = linkURL[1 : len(linkURL)-1]
+		for _, param := range segments[1:] {
+			param = strings.TrimSpace(param)
+			if !strings.HasPrefix(param, "rel=") {
+				continue
+			}
+			rel := strings.Trim(strings.TrimPrefix(param, "rel="), `"`)
+			rels[rel] = linkURL
+		}
+	}
+	return rels
 }
 
 func (c *Client) SetHTTPRoundTripper(rt http.RoundTripper) {
@@ -635,18 +1626,35 @@ func (c *Client) SetHTTPRoundTripper(rt http.RoundTripper) {
 
 func (c *Client) httpClient() *http.Client {
 	c.mu.RLock()
-	var rt http.RoundTripper = c.rt
+	rt := c.rt
 	c.mu.RUnlock()
 
-	return &http.Client{Transport: rt}
+	if rt == nil {
+		rt = c.defaultTransport()
+	}
+	return &http.Client{Transport: rt, CheckRedirect: c.checkRedirect}
 }
 
 const gclaEnvKey = "GCLA_GITHUB_API_KEY"
 
-func NewClientFromEnv() (*Client, error) {
+// NewClient returns a Client that authenticates with apiKey, a personal
+// access or installation token, configured by opts. apiKey may be empty
+// for clients that only authenticate as a GitHub App, see WithAppSigner,
+// or that only access public resources.
+func NewClient(apiKey string, opts ...ClientOption) *Client {
+	c := &Client{apiKey: apiKey}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// NewClientFromEnv is like NewClient with the API key
+// from the "GCLA_GITHUB_API_KEY" environment variable.
+func NewClientFromEnv(opts ...ClientOption) (*Client, error) {
 	apiKey := otils.EnvOrAlternates(gclaEnvKey)
 	if apiKey == "" {
 		return nil, fmt.Errorf("expecting %q to have been set in your environment", gclaEnvKey)
 	}
-	return &Client{apiKey: apiKey}, nil
+	return NewClient(apiKey, opts...), nil
 }
@@ -0,0 +1,392 @@
+// Copyright 2017 orijtech. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gcla
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// backend serves requests made by a Client from an
+// in-process http.Handler instead of the network.
+type backend http.HandlerFunc
+
+var _ http.RoundTripper = (backend)(nil)
+
+func (b backend) RoundTrip(req *http.Request) (*http.Response, error) {
+	rec := httptest.NewRecorder()
+	b(rec, req)
+	res := rec.Result()
+	res.Request = req
+	return res, nil
+}
+
+func newTestClient(h http.HandlerFunc) *Client {
+	client := &Client{apiKey: "test-key"}
+	client.SetHTTPRoundTripper(backend(h))
+	return client
+}
+
+func TestParseLinkHeader(t *testing.T) {
+	link := `<https://api.github.com/orgs/o/members?page=2>; rel="next", <https://api.github.com/orgs/o/members?page=5>; rel="last"`
+	rels := parseLinkHeader(link)
+	if g, w := rels["next"], "https://api.github.com/orgs/o/members?page=2"; g != w {
+		t.Errorf("next: got %q want %q", g, w)
+	}
+	if g, w := rels["last"], "https://api.github.com/orgs/o/members?page=5"; g != w {
+		t.Errorf("last: got %q want %q", g, w)
+	}
+	if len(parseLinkHeader("")) != 0 {
+		t.Errorf("expected no relations from an empty header")
+	}
+}
+
+func TestUserTypeDecoding(t *testing.T) {
+	tests := [...]struct {
+		event     Event
+		payload   string
+		wantType  Type
+		wantValid bool
+	}{
+		0: {
+			event:     EventPush,
+			payload:   `{"ref":"refs/heads/master","sender":{"login":"dependabot[bot]","type":"Bot"}}`,
+			wantType:  TypeBot,
+			wantValid: true,
+		},
+		1: {
+			event:     EventWatch,
+			payload:   `{"action":"started","sender":{"login":"odeke-em","type":"User"}}`,
+			wantType:  TypeUser,
+			wantValid: true,
+		},
+		2: {
+			event:     EventRelease,
+			payload:   `{"action":"published","sender":{"login":"ghost","type":"Mannequin"}}`,
+			wantType:  Type("Mannequin"),
+			wantValid: false,
+		},
+	}
+
+	for i, tt := range tests {
+		got, err := ParseWebhook(tt.event, []byte(tt.payload))
+		if err != nil {
+			t.Errorf("#%d: unexpected error: %v", i, err)
+			continue
+		}
+		var sender *User
+		switch ev := got.(type) {
+		case *PushEvent:
+			sender = ev.Sender
+		case *WatchEvent:
+			sender = ev.Sender
+		case *ReleaseEvent:
+			sender = ev.Sender
+		}
+		if sender == nil {
+			t.Errorf("#%d: expected a non-nil sender", i)
+			continue
+		}
+		if sender.Type != tt.wantType {
+			t.Errorf("#%d: type: got %q want %q", i, sender.Type, tt.wantType)
+		}
+		if g, w := sender.Type.Valid(), tt.wantValid; g != w {
+			t.Errorf("#%d: valid: got %v want %v", i, g, w)
+		}
+	}
+}
+
+func TestAPIErrorFieldErrors(t *testing.T) {
+	client := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		fmt.Fprint(w, `{
+  "message": "Validation Failed",
+  "errors": [
+    {"resource": "Hook", "field": "config.url", "code": "missing_field"},
+    {"resource": "Hook", "field": "events", "code": "custom", "message": "\"pushes\" is not a valid event name"}
+  ],
+  "documentation_url": "https://docs.github.com/rest/reference/repos#create-a-repository-webhook"
+}`)
+	})
+
+	_, err := client.SubscribeToRepo(&RepoSubscribeRequest{
+		Owner:            "orijtech",
+		Repo:             "gcla",
+		HookSubscription: &SubscribeRequest{Name: "web", Events: []Event{"pushes"}},
+	})
+	ae, ok := err.(*APIError)
+	if !ok {
+		t.Fatalf("got err=%v (%T) want *APIError", err, err)
+	}
+	if g, w := ae.StatusCode, http.StatusUnprocessableEntity; g != w {
+		t.Errorf("status code: got %d want %d", g, w)
+	}
+	want := []*FieldError{
+		{Resource: "Hook", Field: "config.url", Code: "missing_field"},
+		{Resource: "Hook", Field: "events", Code: "custom", Message: `"pushes" is not a valid event name`},
+	}
+	if !reflect.DeepEqual(ae.Errors, want) {
+		t.Errorf("field errors:\ngot:  %+v\nwant: %+v", ae.Errors, want)
+	}
+	for _, substr := range []string{"Validation Failed", "Hook.config.url: missing_field", "Hook.events: custom"} {
+		if !strings.Contains(ae.Error(), substr) {
+			t.Errorf("error %q does not mention %q", ae.Error(), substr)
+		}
+	}
+}
+
+func TestFieldErrorAsString(t *testing.T) {
+	ae := new(APIError)
+	if err := json.Unmarshal([]byte(`{"message":"Validation Failed","errors":["name is too long"]}`), ae); err != nil {
+		t.Fatal(err)
+	}
+	if len(ae.Errors) != 1 || ae.Errors[0].Message != "name is too long" {
+		t.Errorf("got %+v", ae.Errors)
+	}
+}
+
+func TestUserAgent(t *testing.T) {
+	tests := [...]struct {
+		opts []ClientOption
+		want string
+	}{
+		0: {want: DefaultUserAgent},
+		1: {opts: []ClientOption{WithUserAgent("cla-bot/1.2 (+https://orijtech.com)")}, want: "cla-bot/1.2 (+https://orijtech.com)"},
+	}
+
+	t.Setenv(gclaEnvKey, "test-key")
+	for i, tt := range tests {
+		client, err := NewClientFromEnv(tt.opts...)
+		if err != nil {
+			t.Fatalf("#%d: %v", i, err)
+		}
+		var got string
+		client.SetHTTPRoundTripper(backend(func(w http.ResponseWriter, r *http.Request) {
+			got = r.Header.Get("User-Agent")
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		if _, err := client.IsStarred("orijtech", "gcla"); err != nil {
+			t.Errorf("#%d: %v", i, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("#%d: User-Agent: got %q want %q", i, got, tt.want)
+		}
+	}
+}
+
+func TestRequestID(t *testing.T) {
+	client := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/orijtech/gcla/hooks/1":
+			w.Header().Set(HeaderRequestID, "C5A4:6F8B:1BD2A:3A1E7:62A1F7E1")
+			fmt.Fprint(w, `{"id": 1}`)
+		default:
+			w.Header().Set(HeaderRequestID, "C5A4:6F8B:1BD2B:3A1E8:62A1F7E2")
+			http.Error(w, `{"message":"Not Found"}`, http.StatusNotFound)
+		}
+	})
+
+	if g := client.LastRequestID(); g != "" {
+		t.Errorf("before any request: got %q", g)
+	}
+	if _, err := client.GetHook("orijtech", "gcla", 1); err != nil {
+		t.Fatal(err)
+	}
+	if g, w := client.LastRequestID(), "C5A4:6F8B:1BD2A:3A1E7:62A1F7E1"; g != w {
+		t.Errorf("got %q want %q", g, w)
+	}
+
+	_, err := client.GetHook("orijtech", "gcla", 2)
+	ae, ok := err.(*APIError)
+	if !ok {
+		t.Fatalf("got err=%v (%T) want *APIError", err, err)
+	}
+	if g, w := ae.RequestID, "C5A4:6F8B:1BD2B:3A1E8:62A1F7E2"; g != w {
+		t.Errorf("APIError.RequestID: got %q want %q", g, w)
+	}
+	if !strings.Contains(ae.Error(), ae.RequestID) {
+		t.Errorf("error %q does not mention the request ID", ae.Error())
+	}
+	if g, w := client.LastRequestID(), ae.RequestID; g != w {
+		t.Errorf("got %q want %q", g, w)
+	}
+}
+
+func TestHTMLErrorPages(t *testing.T) {
+	errorPage := "<!DOCTYPE html>\n<html>\n  <head><title>Unicorn! &middot; GitHub</title></head>\n  <body>" +
+		strings.Repeat("<p>No server is currently available to service your request.</p>\n", 10) +
+		"</body>\n</html>\n"
+
+	tests := [...]struct {
+		status      int
+		contentType string
+		body        string
+		wantHTML    bool
+	}{
+		0: {status: http.StatusServiceUnavailable, contentType: "text/html; charset=utf-8", body: errorPage, wantHTML: true},
+		// Some error pages come with a 2XX status code.
+		1: {status: http.StatusOK, contentType: "text/html", body: errorPage, wantHTML: true},
+		// JSON is still decoded whatever its content type.
+		2: {status: http.StatusServiceUnavailable, contentType: "text/plain", body: `{"message":"Service Unavailable"}`},
+		3: {status: http.StatusServiceUnavailable, contentType: "application/json", body: ""},
+	}
+
+	for i, tt := range tests {
+		client := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", tt.contentType)
+			w.Header().Set(HeaderRequestID, "CC30:6B16:1C6C3F4:2A4E5B1:5F1B0B6D")
+			w.WriteHeader(tt.status)
+			fmt.Fprint(w, tt.body)
+		})
+		_, err := client.SubscribeToRepo(&RepoSubscribeRequest{
+			Owner:            "orijtech",
+			Repo:             "gcla",
+			HookSubscription: &SubscribeRequest{Events: []Event{EventPush}},
+		})
+		if !tt.wantHTML {
+			if _, ok := err.(*APIError); !ok {
+				t.Errorf("#%d: got err=%v (%T) want *APIError", i, err, err)
+			}
+			continue
+		}
+
+		ure, ok := err.(*UnexpectedResponseError)
+		if !ok {
+			t.Errorf("#%d: got err=%v (%T) want *UnexpectedResponseError", i, err, err)
+			continue
+		}
+		if g, w := ure.StatusCode, tt.status; g != w {
+			t.Errorf("#%d: status code: got %d want %d", i, g, w)
+		}
+		if !isStatusCode(err, tt.status) {
+			t.Errorf("#%d: isStatusCode doesn't recognize %d", i, tt.status)
+		}
+		if !strings.HasPrefix(ure.Snippet, "<!DOCTYPE html> <html> <head><title>Unicorn!") {
+			t.Errorf("#%d: snippet: got %q", i, ure.Snippet)
+		}
+		if g, w := len(ure.Snippet), maxSnippetLen+len("..."); g != w {
+			t.Errorf("#%d: snippet length: got %d want %d", i, g, w)
+		}
+		msg := err.Error()
+		for _, want := range []string{http.StatusText(tt.status), "text/html", "Unicorn!", "CC30:6B16:1C6C3F4:2A4E5B1:5F1B0B6D"} {
+			if !strings.Contains(msg, want) {
+				t.Errorf("#%d: %q is missing from %q", i, want, msg)
+			}
+		}
+		if strings.Contains(msg, "invalid character") {
+			t.Errorf("#%d: got a JSON syntax error %q", i, msg)
+		}
+	}
+}
+
+func TestBoolRequest(t *testing.T) {
+	tests := [...]struct {
+		status  int
+		want    bool
+		wantErr bool
+	}{
+		0: {status: http.StatusNoContent, want: true},
+		1: {status: http.StatusNotFound, want: false},
+		2: {status: http.StatusInternalServerError, wantErr: true},
+	}
+
+	for i, tt := range tests {
+		client := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+			if g, w := r.Method, "GET"; g != w {
+				t.Errorf("#%d: method: got %q want %q", i, g, w)
+			}
+			if g, w := r.URL.Path, "/repos/orijtech/gcla/collaborators/odeke-em"; g != w {
+				t.Errorf("#%d: path: got %q want %q", i, g, w)
+			}
+			if tt.status == http.StatusNoContent {
+				w.WriteHeader(tt.status)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(tt.status)
+			fmt.Fprint(w, `{"message": "oops"}`)
+		})
+
+		got, err := client.boolRequest("GET", "/repos/orijtech/gcla/collaborators/odeke-em")
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("#%d: expected a non-nil error", i)
+			} else if !isStatusCode(err, tt.status) {
+				t.Errorf("#%d: got %v want a %d error", i, err, tt.status)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("#%d: unexpected error: %v", i, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("#%d: got %v want %v", i, got, tt.want)
+		}
+	}
+}
+
+func TestNewClient(t *testing.T) {
+	t.Setenv(gclaEnvKey, "")
+	if _, err := NewClientFromEnv(); err == nil {
+		t.Fatalf("expected NewClientFromEnv to fail without %q", gclaEnvKey)
+	}
+
+	tests := [...]struct {
+		apiKey   string
+		wantAuth string
+	}{
+		0: {apiKey: "from-a-secret-store", wantAuth: "token from-a-secret-store"},
+		1: {apiKey: "", wantAuth: ""},
+	}
+
+	for i, tt := range tests {
+		buf := new(bytes.Buffer)
+		client := NewClient(tt.apiKey,
+			WithUserAgent("cla-bot/1.2"),
+			WithLogger(log.New(buf, "", 0)),
+			WithMaxRedirects(2),
+		)
+		var gotAuth, gotUA string
+		client.SetHTTPRoundTripper(backend(func(w http.ResponseWriter, r *http.Request) {
+			gotAuth, gotUA = r.Header.Get("Authorization"), r.Header.Get("User-Agent")
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		if _, err := client.IsStarred("orijtech", "gcla"); err == nil {
+			t.Errorf("#%d: expected an error", i)
+		}
+		if g, w := gotAuth, tt.wantAuth; g != w {
+			t.Errorf("#%d: Authorization: got %q want %q", i, g, w)
+		}
+		if g, w := gotUA, "cla-bot/1.2"; g != w {
+			t.Errorf("#%d: User-Agent: got %q want %q", i, g, w)
+		}
+		if buf.Len() == 0 {
+			t.Errorf("#%d: expected WithLogger's logger to be used", i)
+		}
+		if g, w := client.maxRedirects, 2; g != w {
+			t.Errorf("#%d: max redirects: got %d want %d", i, g, w)
+		}
+	}
+}
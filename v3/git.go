@@ -0,0 +1,320 @@
+// Copyright 2017 orijtech. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gcla
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Tree is a Git tree, the listing of a directory at a commit.
+type Tree struct {
+	SHA  string       `json:"sha,omitempty"`
+	URL  string       `json:"url,omitempty"`
+	Tree []*TreeEntry `json:"tree,omitempty"`
+
+	// Truncated is set when GitHub didn't return all the entries of a
+	// recursive listing, because the tree is too large. The missing
+	// entries can be fetched by listing the subtrees non-recursively.
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// decodeStream decodes a tree one entry at a time since
+// the recursive trees of large repositories run into megabytes.
+func (t *Tree) decodeStream(dec *json.Decoder) error {
+	if err := expectDelim(dec, '{'); err != nil {
+		return err
+	}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		switch tok {
+		case "sha":
+			err = dec.Decode(&t.SHA)
+		case "url":
+			err = dec.Decode(&t.URL)
+		case "truncated":
+			err = dec.Decode(&t.Truncated)
+		case "tree":
+			err = t.decodeEntries(dec)
+		default:
+			err = dec.Decode(new(json.RawMessage))
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return expectDelim(dec, '}')
+}
+
+func (t *Tree) decodeEntries(dec *json.Decoder) error {
+	if err := expectDelim(dec, '['); err != nil {
+		return err
+	}
+	for dec.More() {
+		entry := new(TreeEntry)
+		if err := dec.Decode(entry); err != nil {
+			return err
+		}
+		t.Tree = append(t.Tree, entry)
+	}
+	return expectDelim(dec, ']')
+}
+
+// Types of TreeEntry.
+const (
+	TreeEntryBlob   = "blob"
+	TreeEntryTree   = "tree"
+	TreeEntryCommit = "commit"
+)
+
+type TreeEntry struct {
+	// Path is relative to the tree that was requested.
+	Path string `json:"path,omitempty"`
+	// Mode is the Git file mode e.g. "100644" or "040000".
+	Mode string `json:"mode,omitempty"`
+	// Type is one of TreeEntryBlob, TreeEntryTree or, for
+	// submodules, TreeEntryCommit.
+	Type string `json:"type,omitempty"`
+	SHA  string `json:"sha,omitempty"`
+	// Size is only set for blobs.
+	Size uint64 `json:"size,omitempty"`
+	URL  string `json:"url,omitempty"`
+}
+
+// Blob is the content of a file in Git.
+type Blob struct {
+	SHA  string `json:"sha,omitempty"`
+	URL  string `json:"url,omitempty"`
+	Size uint64 `json:"size,omitempty"`
+
+	// Content is decoded from the response's encoding.
+	Content []byte `json:"-"`
+}
+
+var errEmptySHA = errors.New("expecting a non-empty SHA")
+
+func validateGitArgs(owner, repo, sha string) error {
+	if owner == "" {
+		return errEmptyOwner
+	}
+	if repo == "" {
+		return errEmptyRepo
+	}
+	if sha == "" {
+		return errEmptySHA
+	}
+	return nil
+}
+
+// GetTree retrieves the tree identified by sha, which can also be the
+// SHA of a commit or the name of a branch or tag. If recursive is set,
+// the entries of all its subtrees are listed too, up to GitHub's limits,
+// in which case the returned tree is Truncated.
+func (c *Client) GetTree(owner, repo, sha string, recursive bool) (*Tree, error) {
+	if err := validateGitArgs(owner, repo, sha); err != nil {
+		return nil, err
+	}
+	fullURL := fmt.Sprintf("%s/repos/%s/%s/git/trees/%s", baseURL, owner, repo, escapePath(sha))
+	if recursive {
+		fullURL += "?recursive=1"
+	}
+	req, err := http.NewRequest("GET", fullURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	tree := new(Tree)
+	if _, err := c.doHTTPReqJSON(req, tree); err != nil {
+		return nil, err
+	}
+	return tree, nil
+}
+
+// GetBlob retrieves the blob identified by sha and decodes its content.
+func (c *Client) GetBlob(owner, repo, sha string) (*Blob, error) {
+	if err := validateGitArgs(owner, repo, sha); err != nil {
+		return nil, err
+	}
+	fullURL := fmt.Sprintf("%s/repos/%s/%s/git/blobs/%s", baseURL, owner, repo, sha)
+	req, err := http.NewRequest("GET", fullURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	blob, _, err := c.doHTTPReq(req)
+	if err != nil {
+		return nil, err
+	}
+	recv := new(struct {
+		Blob
+		Content  string `json:"content"`
+		Encoding string `json:"encoding"`
+	})
+	if err := json.Unmarshal(blob, recv); err != nil {
+		return nil, err
+	}
+	if recv.Blob.Content, err = decodeContent(recv.Encoding, recv.Content); err != nil {
+		return nil, err
+	}
+	return &recv.Blob, nil
+}
+
+// Reference is a Git reference such as a branch or a tag.
+type Reference struct {
+	// Ref is the full name of the reference e.g. "refs/heads/master".
+	Ref    string     `json:"ref,omitempty"`
+	NodeID string     `json:"node_id,omitempty"`
+	URL    string     `json:"url,omitempty"`
+	Object *GitObject `json:"object,omitempty"`
+}
+
+// GitObject is the object that a Reference points to.
+type GitObject struct {
+	// Type is "commit" or, for annotated tags, "tag".
+	Type string `json:"type,omitempty"`
+	SHA  string `json:"sha,omitempty"`
+	URL  string `json:"url,omitempty"`
+}
+
+// validateRef checks that ref is a full reference name such as
+// "refs/heads/master" or "refs/tags/v3.0.0", following the rules
+// of "git check-ref-format" that GitHub enforces.
+func validateRef(ref string) error {
+	invalid := func(why string) error {
+		return fmt.Errorf("invalid ref %q: %s", ref, why)
+	}
+	if !strings.HasPrefix(ref, "refs/") || strings.Count(ref, "/") < 2 {
+		return invalid(`expecting a full name such as "refs/heads/<branch>" or "refs/tags/<tag>"`)
+	}
+	if strings.ContainsAny(ref, " ~^:?*[\\") || strings.Contains(ref, "..") || strings.Contains(ref, "@{") {
+		return invalid("contains a forbidden character or sequence")
+	}
+	for _, r := range ref {
+		if r < 0x20 || r == 0x7f {
+			return invalid("contains a control character")
+		}
+	}
+	for _, component := range strings.Split(ref, "/") {
+		if component == "" || strings.HasPrefix(component, ".") || strings.HasSuffix(component, ".lock") {
+			return invalid("has an empty component or one that starts with \".\" or ends with \".lock\"")
+		}
+	}
+	if strings.HasSuffix(ref, ".") {
+		return invalid(`ends with "."`)
+	}
+	return nil
+}
+
+// CreateRef creates the reference ref, for example "refs/tags/v3.0.0",
+// pointing at the commit sha. GitHub responds with "422 Unprocessable
+// Entity" if the reference already exists.
+func (c *Client) CreateRef(owner, repo, ref, sha string) (*Reference, error) {
+	if err := validateGitArgs(owner, repo, sha); err != nil {
+		return nil, err
+	}
+	if !shaRegexp.MatchString(sha) {
+		return nil, fmt.Errorf("invalid SHA %q, expecting 40 hexadecimal characters", sha)
+	}
+	if err := validateRef(ref); err != nil {
+		return nil, err
+	}
+	blob, err := json.Marshal(&createRefRequest{Ref: ref, SHA: sha})
+	if err != nil {
+		return nil, err
+	}
+	fullURL := fmt.Sprintf("%s/repos/%s/%s/git/refs", baseURL, owner, repo)
+	req, err := http.NewRequest("POST", fullURL, bytes.NewReader(blob))
+	if err != nil {
+		return nil, err
+	}
+	blob, _, err = c.doHTTPReq(req)
+	if err != nil {
+		return nil, err
+	}
+	created := new(Reference)
+	if err := json.Unmarshal(blob, created); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+type createRefRequest struct {
+	Ref string `json:"ref"`
+	SHA string `json:"sha"`
+}
+
+// GetRef retrieves the reference ref, for example "refs/heads/master".
+func (c *Client) GetRef(owner, repo, ref string) (*Reference, error) {
+	if owner == "" {
+		return nil, errEmptyOwner
+	}
+	if repo == "" {
+		return nil, errEmptyRepo
+	}
+	if err := validateRef(ref); err != nil {
+		return nil, err
+	}
+	// Unlike "git/refs/", "git/ref/" only matches the exact name.
+	fullURL := fmt.Sprintf("%s/repos/%s/%s/git/ref/%s", baseURL, owner, repo, escapePath(strings.TrimPrefix(ref, "refs/")))
+	req, err := http.NewRequest("GET", fullURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	blob, _, err := c.doHTTPReq(req)
+	if err != nil {
+		return nil, err
+	}
+	got := new(Reference)
+	if err := json.Unmarshal(blob, got); err != nil {
+		return nil, err
+	}
+	return got, nil
+}
+
+// DeleteRef deletes the reference ref, for example "refs/heads/feature".
+func (c *Client) DeleteRef(owner, repo, ref string) error {
+	if owner == "" {
+		return errEmptyOwner
+	}
+	if repo == "" {
+		return errEmptyRepo
+	}
+	if err := validateRef(ref); err != nil {
+		return err
+	}
+	// The endpoint takes the name without the "refs/" prefix.
+	fullURL := fmt.Sprintf("%s/repos/%s/%s/git/refs/%s", baseURL, owner, repo, escapePath(strings.TrimPrefix(ref, "refs/")))
+	req, err := http.NewRequest("DELETE", fullURL, nil)
+	if err != nil {
+		return err
+	}
+	_, _, err = c.doHTTPReq(req)
+	return err
+}
+
+// ShortSHA returns the abbreviated form of sha that GitHub displays,
+// its first 7 characters, or sha itself if it is shorter than that.
+func ShortSHA(sha string) string {
+	const shortLen = 7
+	if len(sha) <= shortLen {
+		return sha
+	}
+	return sha[:shortLen]
+}
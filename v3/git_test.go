@@ -0,0 +1,253 @@
+// Copyright 2017 orijtech. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gcla
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestGetTree(t *testing.T) {
+	client := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		if g, w := r.URL.Path, "/repos/orijtech/gcla/git/trees/master"; g != w {
+			t.Errorf("path: got %q want %q", g, w)
+		}
+		if g, w := r.URL.Query().Get("recursive"), "1"; g != w {
+			t.Errorf("recursive: got %q want %q", g, w)
+		}
+		fmt.Fprint(w, `{
+  "sha": "9fb037999f264ba9a7fc6274d15fa3ae2ab98312",
+  "url": "https://api.github.com/repos/orijtech/gcla/git/trees/9fb037999f264ba9a7fc6274d15fa3ae2ab98312",
+  "tree": [
+    {"path": "README.md", "mode": "100644", "type": "blob", "size": 132, "sha": "7c258a9869f33c1e1e1f74fbb32f07c86cb5a75b"},
+    {"path": "v3", "mode": "040000", "type": "tree", "sha": "bb4cc8d3b2e14b3af5df699876dd4ff3acd00b7f"},
+    {"path": "v3/gcla.go", "mode": "100644", "type": "blob", "size": 30475, "sha": "3c12a1f3a2b58dcbbd8d7a7b1a4e0b3c4d5e6f70"}
+  ],
+  "truncated": true
+}`)
+	})
+
+	tree, err := client.GetTree("orijtech", "gcla", "master", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !tree.Truncated {
+		t.Error("expected a truncated tree")
+	}
+	if g, w := len(tree.Tree), 3; g != w {
+		t.Fatalf("got %d entries want %d", g, w)
+	}
+	if e := tree.Tree[1]; e.Path != "v3" || e.Type != TreeEntryTree || e.Size != 0 {
+		t.Errorf("entry #1: got %#v", e)
+	}
+	if e := tree.Tree[2]; e.Path != "v3/gcla.go" || e.Type != TreeEntryBlob || e.Size != 30475 {
+		t.Errorf("entry #2: got %#v", e)
+	}
+
+	if _, err := client.GetTree("orijtech", "gcla", "", false); err == nil {
+		t.Error("expected an error for an empty SHA")
+	}
+}
+
+// largeTree returns the JSON of a recursive tree with n entries,
+// like that of a monorepo.
+func largeTree(n int) []byte {
+	var sb strings.Builder
+	sb.WriteString(`{"sha": "9fb037999f264ba9a7fc6274d15fa3ae2ab98312", "truncated": false, "tree": [`)
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			sb.WriteString(",")
+		}
+		fmt.Fprintf(&sb, `{"path": "pkg/dir%d/file%d.go", "mode": "100644", "type": "blob", "sha": "%040x", "size": %d}`, i/100, i, i, i)
+	}
+	sb.WriteString("]}\n")
+	return []byte(sb.String())
+}
+
+func TestGetTreeLarge(t *testing.T) {
+	const n = 50000
+	payload := largeTree(n)
+	client := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(payload)
+	})
+
+	tree, err := client.GetTree("orijtech", "gcla", "master", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g, w := len(tree.Tree), n; g != w {
+		t.Fatalf("entries: got %d want %d", g, w)
+	}
+	last := tree.Tree[n-1]
+	if g, w := last.Path, fmt.Sprintf("pkg/dir%d/file%d.go", (n-1)/100, n-1); g != w {
+		t.Errorf("path: got %q want %q", g, w)
+	}
+	if g, w := last.SHA, fmt.Sprintf("%040x", n-1); g != w {
+		t.Errorf("sha: got %q want %q", g, w)
+	}
+	if g, w := last.Size, uint64(n-1); g != w {
+		t.Errorf("size: got %d want %d", g, w)
+	}
+
+	// Malformed bodies are still reported.
+	payload = payload[:len(payload)/2]
+	if _, err := client.GetTree("orijtech", "gcla", "master", true); err == nil {
+		t.Error("expected an error for a truncated body")
+	}
+}
+
+func BenchmarkDecodeLargeTree(b *testing.B) {
+	payload := largeTree(50000)
+	client := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(payload)
+	})
+	newReq := func() *http.Request {
+		req, _ := http.NewRequest("GET", "https://api.github.com/repos/orijtech/gcla/git/trees/master?recursive=1", nil)
+		return req
+	}
+
+	b.Run("ReadAll", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			blob, _, err := client.doHTTPReq(newReq())
+			if err != nil {
+				b.Fatal(err)
+			}
+			if err := json.Unmarshal(blob, new(Tree)); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("Stream", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := client.doHTTPReqJSON(newReq(), new(Tree)); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func TestGetBlob(t *testing.T) {
+	client := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		if g, w := r.URL.Path, "/repos/orijtech/gcla/git/blobs/7c258a9869f33c1e1e1f74fbb32f07c86cb5a75b"; g != w {
+			t.Errorf("path: got %q want %q", g, w)
+		}
+		fmt.Fprint(w, `{
+  "sha": "7c258a9869f33c1e1e1f74fbb32f07c86cb5a75b",
+  "size": 22,
+  "url": "https://api.github.com/repos/orijtech/gcla/git/blobs/7c258a9869f33c1e1e1f74fbb32f07c86cb5a75b",
+  "content": "IyBnY2xhCgpHaXRIdWIgY2xp\nZW50Cg==\n",
+  "encoding": "base64"
+}`)
+	})
+
+	blob, err := client.GetBlob("orijtech", "gcla", "7c258a9869f33c1e1e1f74fbb32f07c86cb5a75b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g, w := string(blob.Content), "# gcla\n\nGitHub client\n"; g != w {
+		t.Errorf("content: got %q want %q", g, w)
+	}
+	if g, w := blob.Size, uint64(22); g != w {
+		t.Errorf("size: got %d want %d", g, w)
+	}
+	if g, w := blob.SHA, "7c258a9869f33c1e1e1f74fbb32f07c86cb5a75b"; g != w {
+		t.Errorf("sha: got %q want %q", g, w)
+	}
+}
+
+func TestCreateRef(t *testing.T) {
+	const sha = "aa218f56b14c9653891f9e74264a383fa43fefbd"
+	client := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		if g, w := r.Method+" "+r.URL.Path, "POST /repos/orijtech/gcla/git/refs"; g != w {
+			t.Errorf("request: got %q want %q", g, w)
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		if strings.Contains(string(body), "v2.0.0") {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			fmt.Fprint(w, `{"message":"Reference already exists","documentation_url":"https://docs.github.com/rest/git/refs#create-a-reference"}`)
+			return
+		}
+		if g, w := string(body), `{"ref":"refs/tags/v3.0.0","sha":"`+sha+`"}`; g != w {
+			t.Errorf("body: got %s want %s", g, w)
+		}
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{
+  "ref": "refs/tags/v3.0.0",
+  "node_id": "MDM6UmVmcmVmcy90YWdzL3YzLjAuMA==",
+  "url": "https://api.github.com/repos/orijtech/gcla/git/refs/tags/v3.0.0",
+  "object": {"type": "commit", "sha": "`+sha+`", "url": "https://api.github.com/repos/orijtech/gcla/git/commits/`+sha+`"}
+}`)
+	})
+
+	ref, err := client.CreateRef("orijtech", "gcla", "refs/tags/v3.0.0", sha)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ref.Ref != "refs/tags/v3.0.0" || ref.Object == nil || ref.Object.SHA != sha || ref.Object.Type != "commit" {
+		t.Errorf("got %#v", ref)
+	}
+
+	_, err = client.CreateRef("orijtech", "gcla", "refs/tags/v2.0.0", sha)
+	if !isStatusCode(err, http.StatusUnprocessableEntity) {
+		t.Errorf("existing ref: got err=%v want a 422 *APIError", err)
+	}
+
+	for i, ref := range []string{"tags/v3.0.0", "refs/v3", "refs/heads/a..b", "refs/heads/x.lock", "refs/heads/a b", "refs/heads/", "refs/heads/.hidden", "refs/heads/x."} {
+		if _, err := client.CreateRef("orijtech", "gcla", ref, sha); err == nil {
+			t.Errorf("#%d: %q: expected a non-nil error", i, ref)
+		}
+	}
+	if _, err := client.CreateRef("orijtech", "gcla", "refs/tags/v3.0.0", "master"); err == nil {
+		t.Error("expected an error for a SHA that isn't one")
+	}
+}
+
+func TestDeleteRef(t *testing.T) {
+	client := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		if g, w := r.Method+" "+r.URL.Path, "DELETE /repos/orijtech/gcla/git/refs/heads/feature/webhooks"; g != w {
+			t.Errorf("request: got %q want %q", g, w)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+	if err := client.DeleteRef("orijtech", "gcla", "refs/heads/feature/webhooks"); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.DeleteRef("orijtech", "gcla", "heads/feature/webhooks"); err == nil {
+		t.Error("expected an error for a ref without the \"refs/\" prefix")
+	}
+}
+
+func TestShortSHA(t *testing.T) {
+	tests := [...]struct {
+		sha, want string
+	}{
+		0: {sha: "aa218f56b14c9653891f9e74264a383fa43fefbd", want: "aa218f5"},
+		1: {sha: "aa218f5", want: "aa218f5"},
+		2: {sha: "aa21", want: "aa21"},
+		3: {sha: "", want: ""},
+	}
+
+	for i, tt := range tests {
+		if g, w := ShortSHA(tt.sha), tt.want; g != w {
+			t.Errorf("#%d: got %q want %q", i, g, w)
+		}
+	}
+}
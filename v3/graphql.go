@@ -0,0 +1,93 @@
+// Copyright 2017 orijtech. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gcla
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+const graphQLURL = baseURL + "/graphql"
+
+var errEmptyQuery = errors.New("expecting a non-empty query")
+
+type graphQLRequest struct {
+	Query     string                 `json:"query"`
+	Variables map[string]interface{} `json:"variables,omitempty"`
+}
+
+type graphQLResponse struct {
+	Data   json.RawMessage     `json:"data"`
+	Errors []*GraphQLErrorItem `json:"errors,omitempty"`
+}
+
+// GraphQLError is returned by GraphQL when GitHub's GraphQL API reports
+// errors. It responds with "200 OK" even then, so those errors aren't
+// APIErrors.
+type GraphQLError struct {
+	Errors []*GraphQLErrorItem
+}
+
+// GraphQLErrorItem is a single error reported by GitHub's GraphQL API.
+type GraphQLErrorItem struct {
+	Type    string        `json:"type,omitempty"`
+	Message string        `json:"message,omitempty"`
+	Path    []interface{} `json:"path,omitempty"`
+}
+
+func (gqe *GraphQLError) Error() string {
+	messages := make([]string, 0, len(gqe.Errors))
+	for _, item := range gqe.Errors {
+		messages = append(messages, item.Message)
+	}
+	return "graphql: " + strings.Join(messages, "; ")
+}
+
+// GraphQL runs query, a GraphQL query or mutation, with variables against
+// GitHub's GraphQL API and decodes the "data" of the response into recv,
+// which may be nil. It is meant for the few operations, such as marking a
+// draft pull request ready for review, that the REST API doesn't support.
+func (c *Client) GraphQL(query string, variables map[string]interface{}, recv interface{}) error {
+	if query == "" {
+		return errEmptyQuery
+	}
+	blob, err := json.Marshal(&graphQLRequest{Query: query, Variables: variables})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest("POST", graphQLURL, bytes.NewReader(blob))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	blob, _, err = c.doHTTPReq(req)
+	if err != nil {
+		return err
+	}
+	gres := new(graphQLResponse)
+	if err := json.Unmarshal(blob, gres); err != nil {
+		return err
+	}
+	if len(gres.Errors) > 0 {
+		return &GraphQLError{Errors: gres.Errors}
+	}
+	if recv == nil || len(gres.Data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(gres.Data, recv)
+}
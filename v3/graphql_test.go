@@ -0,0 +1,72 @@
+// Copyright 2017 orijtech. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gcla
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestGraphQL(t *testing.T) {
+	client := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		if g, w := r.Method, "POST"; g != w {
+			t.Errorf("method: got %q want %q", g, w)
+		}
+		if g, w := r.URL.Path, "/graphql"; g != w {
+			t.Errorf("path: got %q want %q", g, w)
+		}
+		var req graphQLRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatal(err)
+		}
+		switch req.Variables["login"] {
+		case "odeke-em":
+			fmt.Fprint(w, `{"data": {"user": {"name": "Emmanuel T Odeke"}}}`)
+		default:
+			fmt.Fprintf(w, `{"data": {"user": null}, "errors": [{"type": "NOT_FOUND", "path": ["user"], "message": "Could not resolve to a User with the login of '%s'."}]}`, req.Variables["login"])
+		}
+	})
+
+	const query = `query($login: String!) { user(login: $login) { name } }`
+	var recv struct {
+		User *struct {
+			Name string `json:"name"`
+		} `json:"user"`
+	}
+	if err := client.GraphQL(query, map[string]interface{}{"login": "odeke-em"}, &recv); err != nil {
+		t.Fatal(err)
+	}
+	if recv.User == nil || recv.User.Name != "Emmanuel T Odeke" {
+		t.Errorf("user: got %#v", recv.User)
+	}
+
+	err := client.GraphQL(query, map[string]interface{}{"login": "nobody"}, &recv)
+	gqe, ok := err.(*GraphQLError)
+	if !ok {
+		t.Fatalf("got %T (%v) want *GraphQLError", err, err)
+	}
+	if g, w := len(gqe.Errors), 1; g != w {
+		t.Fatalf("got %d errors want %d", g, w)
+	}
+	if g, w := gqe.Errors[0].Type, "NOT_FOUND"; g != w {
+		t.Errorf("type: got %q want %q", g, w)
+	}
+
+	if err := client.GraphQL("", nil, nil); err == nil {
+		t.Error("expected an error for an empty query")
+	}
+}
@@ -0,0 +1,190 @@
+// Copyright 2017 orijtech. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gcla
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sync"
+)
+
+// Delivery is a single webhook delivery received by a WebhookHandler.
+type Delivery struct {
+	// ID is the GUID from the "X-GitHub-Delivery" header.
+	ID    string
+	Event Event
+	// Payload is the parsed event, as returned by ParseWebhook.
+	Payload interface{}
+
+	// Logger logs lines prefixed with the delivery's ID and event.
+	// It is never nil.
+	Logger Logger
+}
+
+// WebhookHandler is an http.Handler that receives webhook deliveries
+// from GitHub, verifies and parses them, then invokes the registered
+// callbacks. Each delivery is acknowledged once its callbacks return.
+//
+// A WebhookHandler only looks at the request's method, headers and body,
+// never at its path, so it can be mounted anywhere, with or without
+// http.StripPrefix, alongside other handlers such as health checks:
+//
+//	mux := http.NewServeMux()
+//	mux.Handle("/github/webhooks/", http.StripPrefix("/github/webhooks", wh))
+//	mux.HandleFunc("/github/healthz", healthz)
+type WebhookHandler struct {
+	cfg *webhookConfig
+
+	mu      sync.RWMutex
+	onPing  func(*PingEvent)
+	onEvent func(*Delivery)
+}
+
+var _ http.Handler = (*WebhookHandler)(nil)
+
+func NewWebhookHandler(opts ...WebhookOption) *WebhookHandler {
+	return &WebhookHandler{cfg: newWebhookConfig(opts...)}
+}
+
+// WithSecret makes a WebhookHandler reject any delivery whose
+// "X-Hub-Signature-256" header doesn't match one of secrets, the secret
+// that the hook was configured with. Passing both the new and the old
+// secret allows rotating it without dropping deliveries that are still
+// signed with the old one. Empty secrets are ignored. ParseWebhook ignores it.
+func WithSecret(secrets ...string) WebhookOption {
+	return func(wc *webhookConfig) {
+		wc.secrets = nil
+		for _, secret := range secrets {
+			if secret != "" {
+				wc.secrets = append(wc.secrets, secret)
+			}
+		}
+	}
+}
+
+// SkipSignatureVerification makes a WebhookHandler configured WithSecret
+// accept deliveries of events without verifying their signatures, while
+// the signatures of all the other events are still verified. It is meant
+// for pings, while wiring up a hook whose secret isn't set yet.
+func SkipSignatureVerification(events ...Event) WebhookOption {
+	return func(wc *webhookConfig) {
+		if wc.unsignedEvents == nil {
+			wc.unsignedEvents = make(map[Event]bool, len(events))
+		}
+		for _, event := range events {
+			wc.unsignedEvents[event] = true
+		}
+	}
+}
+
+// AllowedEvents makes a WebhookHandler acknowledge deliveries of any
+// event not in events with "200 OK" without reading, verifying or parsing
+// their bodies, nor invoking any callbacks. Include EventPing to still
+// receive pings. An empty list allows all events. ParseWebhook ignores it.
+func AllowedEvents(events []Event) WebhookOption {
+	return func(wc *webhookConfig) {
+		if len(events) == 0 {
+			wc.allowedEvents = nil
+			return
+		}
+		wc.allowedEvents = make(map[Event]bool, len(events))
+		for _, event := range events {
+			wc.allowedEvents[event] = true
+		}
+	}
+}
+
+func (wc *webhookConfig) allows(event Event) bool {
+	return len(wc.allowedEvents) == 0 || wc.allowedEvents[event]
+}
+
+// OnPing registers fn to be invoked for "ping" deliveries,
+// which GitHub sends as soon as a hook is created.
+func (wh *WebhookHandler) OnPing(fn func(*PingEvent)) {
+	wh.mu.Lock()
+	wh.onPing = fn
+	wh.mu.Unlock()
+}
+
+// OnEvent registers fn to be invoked for every delivery,
+// other than pings if OnPing was registered.
+func (wh *WebhookHandler) OnEvent(fn func(*Delivery)) {
+	wh.mu.Lock()
+	wh.onEvent = fn
+	wh.mu.Unlock()
+}
+
+func (wh *WebhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "only POST is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	defer r.Body.Close()
+
+	d := &Delivery{
+		ID:    r.Header.Get(HeaderDelivery),
+		Event: Event(r.Header.Get(HeaderEvent)),
+	}
+	d.Logger = &prefixLogger{
+		parent: orNopLogger(wh.cfg.logger),
+		prefix: fmt.Sprintf("delivery=%s event=%s: ", d.ID, d.Event),
+	}
+	if !wh.cfg.allows(d.Event) {
+		d.Logger.Printf("ignored")
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	payload, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		d.Logger.Printf("reading body: %v", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(wh.cfg.secrets) > 0 && !wh.cfg.unsignedEvents[d.Event] {
+		if err := VerifySignature(payload, r.Header.Get(HeaderSignature256), wh.cfg.secrets...); err != nil {
+			d.Logger.Printf("rejected: %v", err)
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+	}
+
+	if d.Payload, err = wh.cfg.parse(d.Event, payload); err != nil {
+		if _, ok := err.(*UnhandledEventError); ok {
+			// Hooks are often subscribed to events that this package
+			// doesn't know yet. Failing those deliveries would only get
+			// the hook disabled by GitHub, so acknowledge them instead.
+			d.Logger.Printf("skipped: %v", err)
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		d.Logger.Printf("parsing payload: %v", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	d.Logger.Printf("received %d bytes", len(payload))
+
+	wh.mu.RLock()
+	onPing, onEvent := wh.onPing, wh.onEvent
+	wh.mu.RUnlock()
+
+	if pe, ok := d.Payload.(*PingEvent); ok && onPing != nil {
+		onPing(pe)
+	} else if onEvent != nil {
+		onEvent(d)
+	}
+	w.WriteHeader(http.StatusOK)
+}
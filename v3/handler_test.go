@@ -0,0 +1,311 @@
+// Copyright 2017 orijtech. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gcla
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const pingPayload = `{
+  "zen": "Design for failure.",
+  "hook_id": 109948940,
+  "hook": {
+    "type": "Repository",
+    "id": 109948940,
+    "name": "web",
+    "active": true,
+    "events": ["push", "pull_request"],
+    "config": {"content_type": "json", "url": "https://hooks.orijtech.com/gcla"}
+  },
+  "repository": {"id": 35129377, "full_name": "orijtech/gcla"},
+  "sender": {"login": "odeke-em", "id": 1}
+}`
+
+func newDelivery(event Event, payload string) *http.Request {
+	req := httptest.NewRequest("POST", "/", strings.NewReader(payload))
+	req.Header.Set(HeaderEvent, string(event))
+	req.Header.Set(HeaderDelivery, "72d3162e-cc78-11e3-81ab-4c9367dc0958")
+	req.Header.Set("Content-Type", "application/json")
+	return req
+}
+
+func TestWebhookHandlerPing(t *testing.T) {
+	wh := NewWebhookHandler()
+	var got *PingEvent
+	wh.OnPing(func(pe *PingEvent) { got = pe })
+	wh.OnEvent(func(d *Delivery) {
+		t.Errorf("unexpected OnEvent callback for %q", d.Event)
+	})
+
+	rec := httptest.NewRecorder()
+	wh.ServeHTTP(rec, newDelivery(EventPing, pingPayload))
+	if g, w := rec.Code, http.StatusOK; g != w {
+		t.Fatalf("status: got %d want %d: %s", g, w, rec.Body)
+	}
+	if got == nil {
+		t.Fatalf("expected OnPing to have been invoked")
+	}
+	if g, w := got.HookID, uint64(109948940); g != w {
+		t.Errorf("hook_id: got %d want %d", g, w)
+	}
+	if got.Hook == nil || !got.Hook.Active || got.Hook.Config.URL != "https://hooks.orijtech.com/gcla" {
+		t.Errorf("unexpected hook: %#v", got.Hook)
+	}
+	if g, w := got.Zen, "Design for failure."; g != w {
+		t.Errorf("zen: got %q want %q", g, w)
+	}
+}
+
+func TestWebhookHandlerOnEvent(t *testing.T) {
+	wh := NewWebhookHandler()
+	var deliveries []*Delivery
+	wh.OnEvent(func(d *Delivery) { deliveries = append(deliveries, d) })
+
+	// Without OnPing, pings go to OnEvent too.
+	for _, req := range []*http.Request{
+		newDelivery(EventPing, pingPayload),
+		newDelivery(EventPush, `{"ref":"refs/heads/master"}`),
+	} {
+		rec := httptest.NewRecorder()
+		wh.ServeHTTP(rec, req)
+		if g, w := rec.Code, http.StatusOK; g != w {
+			t.Fatalf("status: got %d want %d: %s", g, w, rec.Body)
+		}
+	}
+
+	if g, w := len(deliveries), 2; g != w {
+		t.Fatalf("got %d deliveries want %d", g, w)
+	}
+	if _, ok := deliveries[0].Payload.(*PingEvent); !ok {
+		t.Errorf("got %T want *PingEvent", deliveries[0].Payload)
+	}
+	if pe, ok := deliveries[1].Payload.(*PushEvent); !ok || pe.Ref != "refs/heads/master" {
+		t.Errorf("got %#v want a *PushEvent", deliveries[1].Payload)
+	}
+	if g, w := deliveries[1].ID, "72d3162e-cc78-11e3-81ab-4c9367dc0958"; g != w {
+		t.Errorf("delivery ID: got %q want %q", g, w)
+	}
+}
+
+func TestWebhookHandlerRejections(t *testing.T) {
+	wh := NewWebhookHandler(WithSecret("s3cr3t"))
+	wh.OnEvent(func(d *Delivery) {
+		t.Errorf("unexpected callback for %q", d.Event)
+	})
+
+	badSignature := newDelivery(EventPush, `{}`)
+	badSignature.Header.Set(HeaderSignature256, "sha256="+sign([]byte(`{}`), "wrong"))
+	malformed := newDelivery(EventPush, `{"ref":`)
+	malformed.Header.Set(HeaderSignature256, "sha256="+sign([]byte(`{"ref":`), "s3cr3t"))
+	unsignedUnknownEvent := newDelivery("not_an_event", `{}`)
+
+	tests := [...]struct {
+		req  *http.Request
+		want int
+	}{
+		0: {req: httptest.NewRequest("GET", "/", nil), want: http.StatusMethodNotAllowed},
+		1: {req: newDelivery(EventPush, `{}`), want: http.StatusUnauthorized},
+		2: {req: badSignature, want: http.StatusUnauthorized},
+		3: {req: malformed, want: http.StatusBadRequest},
+		// Unhandled events are only acknowledged once verified.
+		4: {req: unsignedUnknownEvent, want: http.StatusUnauthorized},
+	}
+
+	for i, tt := range tests {
+		rec := httptest.NewRecorder()
+		wh.ServeHTTP(rec, tt.req)
+		if g, w := rec.Code, tt.want; g != w {
+			t.Errorf("#%d: status: got %d want %d", i, g, w)
+		}
+	}
+}
+
+type unreadableBody struct{ t *testing.T }
+
+func (ub *unreadableBody) Read([]byte) (int, error) {
+	ub.t.Errorf("unexpected read of an ignored delivery's body")
+	return 0, io.EOF
+}
+
+func TestWebhookHandlerAllowedEvents(t *testing.T) {
+	wh := NewWebhookHandler(AllowedEvents([]Event{EventPush}), WithSecret("s3cr3t"))
+	var deliveries []*Delivery
+	wh.OnPing(func(pe *PingEvent) {
+		t.Errorf("unexpected OnPing callback")
+	})
+	wh.OnEvent(func(d *Delivery) { deliveries = append(deliveries, d) })
+
+	// Neither parsed nor verified, so not even the signature matters.
+	ignored := newDelivery(EventRelease, "")
+	ignored.Body = ioutil.NopCloser(&unreadableBody{t: t})
+	rec := httptest.NewRecorder()
+	wh.ServeHTTP(rec, ignored)
+	if g, w := rec.Code, http.StatusOK; g != w {
+		t.Errorf("ignored: status: got %d want %d: %s", g, w, rec.Body)
+	}
+	rec = httptest.NewRecorder()
+	wh.ServeHTTP(rec, newDelivery(EventPing, pingPayload))
+	if g, w := rec.Code, http.StatusOK; g != w {
+		t.Errorf("ping: status: got %d want %d: %s", g, w, rec.Body)
+	}
+	if len(deliveries) != 0 {
+		t.Fatalf("got %d deliveries want none", len(deliveries))
+	}
+
+	payload := `{"ref":"refs/heads/master"}`
+	allowed := newDelivery(EventPush, payload)
+	allowed.Header.Set(HeaderSignature256, "sha256="+sign([]byte(payload), "s3cr3t"))
+	rec = httptest.NewRecorder()
+	wh.ServeHTTP(rec, allowed)
+	if g, w := rec.Code, http.StatusOK; g != w {
+		t.Fatalf("allowed: status: got %d want %d: %s", g, w, rec.Body)
+	}
+	if g, w := len(deliveries), 1; g != w {
+		t.Fatalf("got %d deliveries want %d", g, w)
+	}
+
+	// An empty list allows all events.
+	wh = NewWebhookHandler(AllowedEvents(nil))
+	deliveries = deliveries[:0]
+	wh.OnEvent(func(d *Delivery) { deliveries = append(deliveries, d) })
+	wh.ServeHTTP(httptest.NewRecorder(), newDelivery(EventRelease, `{"action":"published"}`))
+	if g, w := len(deliveries), 1; g != w {
+		t.Errorf("allow all: got %d deliveries want %d", g, w)
+	}
+}
+
+func TestWebhookHandlerSkipSignatureVerification(t *testing.T) {
+	wh := NewWebhookHandler(WithSecret("s3cr3t"), SkipSignatureVerification(EventPing))
+	var events []Event
+	wh.OnEvent(func(d *Delivery) { events = append(events, d.Event) })
+
+	signedPush := newDelivery(EventPush, `{}`)
+	signedPush.Header.Set(HeaderSignature256, "sha256="+sign([]byte(`{}`), "s3cr3t"))
+
+	tests := [...]struct {
+		req  *http.Request
+		want int
+	}{
+		0: {req: newDelivery(EventPing, pingPayload), want: http.StatusOK},
+		1: {req: newDelivery(EventPush, `{}`), want: http.StatusUnauthorized},
+		2: {req: signedPush, want: http.StatusOK},
+	}
+
+	for i, tt := range tests {
+		rec := httptest.NewRecorder()
+		wh.ServeHTTP(rec, tt.req)
+		if g, w := rec.Code, tt.want; g != w {
+			t.Errorf("#%d: status: got %d want %d: %s", i, g, w, rec.Body)
+		}
+	}
+	if g, w := fmt.Sprint(events), fmt.Sprint([]Event{EventPing, EventPush}); g != w {
+		t.Errorf("delivered events: got %s want %s", g, w)
+	}
+}
+
+func TestWebhookHandlerMountedUnderSubPath(t *testing.T) {
+	wh := NewWebhookHandler()
+	var events []Event
+	wh.OnEvent(func(d *Delivery) { events = append(events, d.Event) })
+
+	mux := http.NewServeMux()
+	mux.Handle("/github/webhooks/", http.StripPrefix("/github/webhooks", wh))
+	mux.HandleFunc("/github/healthz", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "ok")
+	})
+
+	for i, path := range []string{"/github/webhooks/", "/github/webhooks/orijtech/gcla"} {
+		req := newDelivery(EventPush, `{"ref":"refs/heads/master"}`)
+		req.URL.Path = path
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		if g, w := rec.Code, http.StatusOK; g != w {
+			t.Errorf("#%d: status: got %d want %d: %s", i, g, w, rec.Body)
+		}
+	}
+	if g, w := len(events), 2; g != w {
+		t.Fatalf("got %d deliveries want %d", g, w)
+	}
+	for i, event := range events {
+		if event != EventPush {
+			t.Errorf("#%d: event: got %q want %q", i, event, EventPush)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("GET", "/github/healthz", nil))
+	if g, w := rec.Body.String(), "ok"; g != w {
+		t.Errorf("healthz: got %q want %q", g, w)
+	}
+	if g, w := len(events), 2; g != w {
+		t.Errorf("healthz reached the webhook handler: got %d deliveries want %d", g, w)
+	}
+}
+
+func TestWebhookHandlerSecretRotation(t *testing.T) {
+	wh := NewWebhookHandler(WithSecret("n3w-s3cr3t", "0ld-s3cr3t"))
+	var received int
+	wh.OnEvent(func(d *Delivery) { received++ })
+
+	payload := `{"ref":"refs/heads/master"}`
+	tests := [...]struct {
+		secret string
+		want   int
+	}{
+		0: {secret: "n3w-s3cr3t", want: http.StatusOK},
+		1: {secret: "0ld-s3cr3t", want: http.StatusOK},
+		2: {secret: "wrong", want: http.StatusUnauthorized},
+	}
+
+	for i, tt := range tests {
+		req := newDelivery(EventPush, payload)
+		req.Header.Set(HeaderSignature256, "sha256="+sign([]byte(payload), tt.secret))
+		rec := httptest.NewRecorder()
+		wh.ServeHTTP(rec, req)
+		if g, w := rec.Code, tt.want; g != w {
+			t.Errorf("#%d: status: got %d want %d", i, g, w)
+		}
+	}
+	if g, w := received, 2; g != w {
+		t.Errorf("got %d deliveries want %d", g, w)
+	}
+}
+
+func TestWebhookHandlerUnhandledEvent(t *testing.T) {
+	buf := new(bytes.Buffer)
+	wh := NewWebhookHandler(WithSecret("s3cr3t"), WithWebhookLogger(log.New(buf, "", 0)))
+	wh.OnEvent(func(d *Delivery) {
+		t.Errorf("unexpected callback for %q", d.Event)
+	})
+
+	req := newDelivery("not_an_event", `{"action":"created"}`)
+	req.Header.Set(HeaderSignature256, "sha256="+sign([]byte(`{"action":"created"}`), "s3cr3t"))
+	rec := httptest.NewRecorder()
+	wh.ServeHTTP(rec, req)
+	if g, w := rec.Code, http.StatusOK; g != w {
+		t.Errorf("status: got %d want %d: %s", g, w, rec.Body)
+	}
+	if g, w := buf.String(), `skipped: unhandled event "not_an_event"`; !strings.Contains(g, w) {
+		t.Errorf("log: got %q want it to contain %q", g, w)
+	}
+}
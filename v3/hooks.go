@@ -0,0 +1,581 @@
+// Copyright 2017 orijtech. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gcla
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	errEmptyOwner  = errors.New("expecting a non-empty owner")
+	errEmptyRepo   = errors.New("expecting a non-empty repo")
+	errEmptyHookID = errors.New("expecting a non-zero hook ID")
+)
+
+// Errors returned by ValidatePayloadURL.
+var (
+	ErrPayloadURLMissingScheme = errors.New("payload URL is missing its scheme, expecting \"https://\"")
+	ErrPayloadURLInsecure      = errors.New("payload URL must use https")
+	ErrPayloadURLMissingHost   = errors.New("payload URL is missing its host")
+	ErrPayloadURLLoopback      = errors.New("payload URL points to a loopback address that GitHub can't reach")
+	ErrPayloadURLFragment      = errors.New("payload URL must not have a fragment")
+)
+
+// ValidatePayloadURL checks that u is a URL that GitHub can deliver
+// webhooks to: an absolute https URL with a host that isn't a loopback
+// address, and without a fragment.
+func ValidatePayloadURL(u string) error {
+	return validatePayloadURL(u, false)
+}
+
+// validatePayloadURL is ValidatePayloadURL but if allowHTTP is set, it
+// also accepts http and loopback URLs, which are only useful in tests.
+func validatePayloadURL(u string, allowHTTP bool) error {
+	pu, err := url.Parse(u)
+	if err != nil {
+		return err
+	}
+	switch {
+	case pu.Scheme == "":
+		return ErrPayloadURLMissingScheme
+	case pu.Scheme == "https":
+	case pu.Scheme == "http" && allowHTTP:
+	default:
+		return ErrPayloadURLInsecure
+	}
+	if pu.Host == "" || pu.Hostname() == "" {
+		return ErrPayloadURLMissingHost
+	}
+	if !allowHTTP && isLoopback(pu.Hostname()) {
+		return ErrPayloadURLLoopback
+	}
+	if pu.Fragment != "" || strings.HasSuffix(u, "#") {
+		return ErrPayloadURLFragment
+	}
+	return nil
+}
+
+func isLoopback(host string) bool {
+	if strings.EqualFold(host, "localhost") || strings.HasSuffix(strings.ToLower(host), ".localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func validateHookArgs(owner, repo string, hookID uint64) error {
+	if owner == "" {
+		return errEmptyOwner
+	}
+	if repo == "" {
+		return errEmptyRepo
+	}
+	if hookID == 0 {
+		return errEmptyHookID
+	}
+	return nil
+}
+
+// GetHook retrieves the repository hook identified by hookID.
+func (c *Client) GetHook(owner, repo string, hookID uint64) (*Subscription, error) {
+	if err := validateHookArgs(owner, repo, hookID); err != nil {
+		return nil, err
+	}
+	fullURL := fmt.Sprintf("%s/repos/%s/%s/hooks/%d", baseURL, owner, repo, hookID)
+	req, err := http.NewRequest("GET", fullURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	blob, _, err := c.doHTTPReq(req)
+	if err != nil {
+		return nil, err
+	}
+	return decodeSubscription(blob)
+}
+
+// PingHook makes GitHub send a "ping" event to the hook identified by hookID.
+func (c *Client) PingHook(owner, repo string, hookID uint64) error {
+	if err := validateHookArgs(owner, repo, hookID); err != nil {
+		return err
+	}
+	fullURL := fmt.Sprintf("%s/repos/%s/%s/hooks/%d/pings", baseURL, owner, repo, hookID)
+	req, err := http.NewRequest("POST", fullURL, nil)
+	if err != nil {
+		return err
+	}
+	_, _, err = c.doHTTPReq(req)
+	return err
+}
+
+// DeleteHook deletes the repository hook identified by hookID.
+func (c *Client) DeleteHook(owner, repo string, hookID uint64) error {
+	if err := validateHookArgs(owner, repo, hookID); err != nil {
+		return err
+	}
+	fullURL := fmt.Sprintf("%s/repos/%s/%s/hooks/%d", baseURL, owner, repo, hookID)
+	req, err := http.NewRequest("DELETE", fullURL, nil)
+	if err != nil {
+		return err
+	}
+	_, _, err = c.doHTTPReq(req)
+	return err
+}
+
+var (
+	errNoEvents   = errors.New("expecting at least one event")
+	errEmptyEvent = errors.New("expecting non-empty events")
+)
+
+// AddHookEvents adds events to those that the hook identified by hookID
+// is subscribed to, keeping the existing ones, and returns the updated hook.
+// Events that the hook is already subscribed to are skipped and if there
+// are none left, the hook is returned without being updated.
+func (c *Client) AddHookEvents(owner, repo string, hookID uint64, events []Event) (*Subscription, error) {
+	if len(events) == 0 {
+		return nil, errNoEvents
+	}
+	for _, event := range events {
+		if event == "" {
+			return nil, errEmptyEvent
+		}
+	}
+	hook, err := c.GetHook(owner, repo, hookID)
+	if err != nil {
+		return nil, err
+	}
+
+	union := make([]Event, 0, len(hook.Events)+len(events))
+	seen := make(map[Event]bool)
+	for _, event := range append(hook.Events, events...) {
+		if !seen[event] {
+			seen[event] = true
+			union = append(union, event)
+		}
+	}
+	if len(union) == len(hook.Events) {
+		return hook, nil
+	}
+
+	blob, err := json.Marshal(&hookEventsUpdate{Events: union})
+	if err != nil {
+		return nil, err
+	}
+	fullURL := fmt.Sprintf("%s/repos/%s/%s/hooks/%d", baseURL, owner, repo, hookID)
+	req, err := http.NewRequest("PATCH", fullURL, bytes.NewReader(blob))
+	if err != nil {
+		return nil, err
+	}
+	blob, _, err = c.doHTTPReq(req)
+	if err != nil {
+		return nil, err
+	}
+	return decodeSubscription(blob)
+}
+
+type hookEventsUpdate struct {
+	Events []Event `json:"events"`
+}
+
+var errEmptyURLPrefix = errors.New("expecting a non-empty URL prefix")
+
+// UnsubscribeByURLPrefix deletes the hooks of owner/repo whose payload URL
+// starts with prefix and returns how many were deleted. It keeps going if
+// deleting a hook fails, in which case the error is a HookErrors.
+func (c *Client) UnsubscribeByURLPrefix(owner, repo, prefix string) (int, error) {
+	if prefix == "" {
+		// Otherwise every hook would be deleted.
+		return 0, errEmptyURLPrefix
+	}
+	hooks, err := c.ListHooks(owner, repo, nil)
+	if err != nil {
+		return 0, err
+	}
+	deleted := 0
+	var errs HookErrors
+	for _, hook := range hooks {
+		if hook.Config == nil || !strings.HasPrefix(hook.Config.URL, prefix) {
+			continue
+		}
+		if err := c.DeleteHook(owner, repo, hook.ID); err != nil {
+			errs = append(errs, &HookError{HookID: hook.ID, Err: err})
+			continue
+		}
+		deleted++
+	}
+	if len(errs) > 0 {
+		return deleted, errs
+	}
+	return deleted, nil
+}
+
+// HookError is the error of an operation on the hook identified by HookID.
+type HookError struct {
+	HookID uint64
+	Err    error
+}
+
+func (he *HookError) Error() string {
+	return fmt.Sprintf("hook %d: %v", he.HookID, he.Err)
+}
+
+// HookErrors aggregates the errors of an operation on multiple hooks.
+type HookErrors []*HookError
+
+func (hes HookErrors) Error() string {
+	msgs := make([]string, len(hes))
+	for i, he := range hes {
+		msgs[i] = he.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// EventWildcard subscribes a hook to all events.
+const EventWildcard Event = "*"
+
+// SupportedEvents returns the events that this package can parse, sorted.
+func (c *Client) SupportedEvents() []Event {
+	events := registeredEvents()
+	sort.Slice(events, func(i, j int) bool { return events[i] < events[j] })
+	return events
+}
+
+// ValidateAgainstSupported checks that every event of sr is one of
+// SupportedEvents or EventWildcard, so that no delivery of the hook
+// that sr creates will fail to parse.
+func (c *Client) ValidateAgainstSupported(sr *SubscribeRequest) error {
+	if sr == nil {
+		return errNilHookSubscription
+	}
+	return validateSupported(sr.Events)
+}
+
+func validateSupported(events []Event) error {
+	var unsupported []string
+	for _, event := range events {
+		if _, ok := lookupEventFactory(event); !ok && event != EventWildcard {
+			unsupported = append(unsupported, fmt.Sprintf("%q", event))
+		}
+	}
+	if len(unsupported) > 0 {
+		return fmt.Errorf("unsupported events: %s", strings.Join(unsupported, ", "))
+	}
+	return nil
+}
+
+// NewSubscribeRequest returns a SubscribeRequest for events given as
+// strings, for example from a config file, after checking that each is
+// one of SupportedEvents or EventWildcard. Surrounding spaces are ignored.
+func NewSubscribeRequest(name string, active bool, events ...string) (*SubscribeRequest, error) {
+	if len(events) == 0 {
+		return nil, errNoEvents
+	}
+	sr := &SubscribeRequest{Name: name, Active: active, Events: make([]Event, 0, len(events))}
+	for _, event := range events {
+		event = strings.TrimSpace(event)
+		if event == "" {
+			return nil, errEmptyEvent
+		}
+		sr.Events = append(sr.Events, Event(event))
+	}
+	if err := validateSupported(sr.Events); err != nil {
+		return nil, err
+	}
+	return sr, nil
+}
+
+// HookListOptions configures ListHooks.
+type HookListOptions struct {
+	// Concurrency if greater than 1, fetches the pages after the first
+	// one concurrently, at most Concurrency at a time, once the number
+	// of the last page is known from the "Link" header. It is capped at
+	// MaxPageConcurrency to avoid exhausting the rate limit.
+	Concurrency int
+}
+
+// MaxPageConcurrency caps the number of pages fetched concurrently.
+const MaxPageConcurrency = 8
+
+// ListHooks returns the hooks of owner/repo, in the
+// order that GitHub lists them, following pagination.
+func (c *Client) ListHooks(owner, repo string, opts *HookListOptions) ([]*Subscription, error) {
+	if owner == "" {
+		return nil, errEmptyOwner
+	}
+	if repo == "" {
+		return nil, errEmptyRepo
+	}
+	concurrency := 1
+	if opts != nil && opts.Concurrency > 1 {
+		concurrency = opts.Concurrency
+	}
+
+	fullURL := fmt.Sprintf("%s/repos/%s/%s/hooks", baseURL, owner, repo)
+	pages, err := c.fetchAllPages(fullURL, concurrency)
+	if err != nil {
+		return nil, err
+	}
+	var hooks []*Subscription
+	for _, blob := range pages {
+		var page []*Subscription
+		if err := json.Unmarshal(blob, &page); err != nil {
+			return nil, err
+		}
+		hooks = append(hooks, page...)
+	}
+	return hooks, nil
+}
+
+// fetchAllPages is like getAllPages but returns the body of every page in
+// order and fetches the pages after the first concurrently, if concurrency
+// permits, once the "last" relation of the "Link" header reveals their count.
+func (c *Client) fetchAllPages(fullURL string, concurrency int) ([][]byte, error) {
+	if concurrency > MaxPageConcurrency {
+		concurrency = MaxPageConcurrency
+	}
+	if concurrency <= 1 {
+		var pages [][]byte
+		err := c.getAllPages(fullURL, func(blob []byte) error {
+			pages = append(pages, blob)
+			return nil
+		})
+		return pages, err
+	}
+
+	req, err := http.NewRequest("GET", fullURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	first, hdr, err := c.doHTTPReq(req)
+	if err != nil {
+		return nil, err
+	}
+	rels := parseLinkHeader(hdr.Get("Link"))
+	lastURL, lastPage, ok := parsePageURL(rels["last"])
+	if !ok {
+		// Without the last page there's nothing to parallelize,
+		// so continue sequentially from wherever "next" points.
+		pages := [][]byte{first}
+		if next := rels["next"]; next != "" {
+			err = c.getAllPages(next, func(blob []byte) error {
+				pages = append(pages, blob)
+				return nil
+			})
+		}
+		return pages, err
+	}
+
+	pages := make([][]byte, lastPage)
+	pages[0] = first
+	errs := make([]error, lastPage)
+	sem := make(chan bool, concurrency)
+	var wg sync.WaitGroup
+	for page := 2; page <= lastPage; page++ {
+		wg.Add(1)
+		sem <- true
+		go func(page int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			pageURL := *lastURL
+			qv := pageURL.Query()
+			qv.Set("page", strconv.Itoa(page))
+			pageURL.RawQuery = qv.Encode()
+			req, err := http.NewRequest("GET", pageURL.String(), nil)
+			if err != nil {
+				errs[page-1] = err
+				return
+			}
+			pages[page-1], _, errs[page-1] = c.doHTTPReq(req)
+		}(page)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return pages, nil
+}
+
+// parsePageURL parses a pagination URL and its "page" query parameter.
+func parsePageURL(pageURL string) (*url.URL, int, bool) {
+	if pageURL == "" {
+		return nil, 0, false
+	}
+	u, err := url.Parse(pageURL)
+	if err != nil {
+		return nil, 0, false
+	}
+	page, err := strconv.Atoi(u.Query().Get("page"))
+	if err != nil || page < 1 {
+		return nil, 0, false
+	}
+	return u, page, true
+}
+
+// reachabilityTimeout bounds the HEAD request
+// of RepoSubscribeRequest.VerifyReachable.
+const reachabilityTimeout = 5 * time.Second
+
+// UnreachablePayloadURLError is returned when the payload
+// URL of a hook to be created fails to answer a HEAD request.
+type UnreachablePayloadURLError struct {
+	URL string
+	// Err is why the request failed. It is nil if
+	// the endpoint responded with a 5XX StatusCode.
+	Err        error
+	StatusCode int
+}
+
+func (upe *UnreachablePayloadURLError) Error() string {
+	if upe.Err != nil {
+		return fmt.Sprintf("payload URL %q is unreachable: %v", upe.URL, upe.Err)
+	}
+	return fmt.Sprintf("payload URL %q is unreachable: responded with status code %d", upe.URL, upe.StatusCode)
+}
+
+// probePayloadURL sends a HEAD request to payloadURL. It goes through
+// the default transport, never the one set by SetHTTPRoundTripper,
+// which is meant for GitHub, and isn't authenticated.
+func (c *Client) probePayloadURL(payloadURL string) error {
+	req, err := http.NewRequest("HEAD", payloadURL, nil)
+	if err != nil {
+		return &UnreachablePayloadURLError{URL: payloadURL, Err: err}
+	}
+	hc := &http.Client{Transport: c.defaultTransport(), Timeout: reachabilityTimeout}
+	res, err := hc.Do(req)
+	if err != nil {
+		if ue, ok := err.(*url.Error); ok {
+			err = ue.Err
+		}
+		return &UnreachablePayloadURLError{URL: payloadURL, Err: err}
+	}
+	res.Body.Close()
+	if res.StatusCode >= 500 {
+		return &UnreachablePayloadURLError{URL: payloadURL, StatusCode: res.StatusCode}
+	}
+	return nil
+}
+
+// HasSecret reports whether the hook has a secret configured.
+// GitHub never echoes a hook's secret back, it only returns
+// a masked placeholder such as "********" in its place.
+func (h *Hook) HasSecret() bool {
+	return h != nil && h.Config.hasSecret()
+}
+
+// HasSecret reports whether the hook has a secret configured.
+// See Hook.HasSecret.
+func (s *Subscription) HasSecret() bool {
+	return s != nil && s.Config.hasSecret()
+}
+
+// Diff describes how other differs from s, one human-readable line
+// per difference, for example to log what reconciling a hook against
+// its desired state is about to change. Events are compared as sets.
+// Secrets aren't compared since GitHub masks them and an empty InsecureSSL
+// is treated as InsecureSSLDisabled, its default. A nil Subscription
+// is treated as a blank one. Diff returns nil if there's no difference.
+func (s *Subscription) Diff(other *Subscription) []string {
+	if s == nil {
+		s = blankSubscription
+	}
+	if other == nil {
+		other = blankSubscription
+	}
+
+	var diffs []string
+	if s.Name != other.Name {
+		diffs = append(diffs, fmt.Sprintf("name changed from %q to %q", s.Name, other.Name))
+	}
+	if s.Active != other.Active {
+		diffs = append(diffs, fmt.Sprintf("active changed from %v to %v", s.Active, other.Active))
+	}
+	added, removed := eventSetDiff(s.Events, other.Events)
+	for _, event := range added {
+		diffs = append(diffs, fmt.Sprintf("event %q added", event))
+	}
+	for _, event := range removed {
+		diffs = append(diffs, fmt.Sprintf("event %q removed", event))
+	}
+
+	var cfg, otherCfg PayloadConfig
+	if s.Config != nil {
+		cfg = *s.Config
+	}
+	if other.Config != nil {
+		otherCfg = *other.Config
+	}
+	if cfg.URL != otherCfg.URL {
+		diffs = append(diffs, fmt.Sprintf("URL changed from %q to %q", cfg.URL, otherCfg.URL))
+	}
+	if cfg.ContentType != otherCfg.ContentType {
+		diffs = append(diffs, fmt.Sprintf("content type changed from %q to %q", cfg.ContentType, otherCfg.ContentType))
+	}
+	if insecureSSL, otherInsecureSSL := cfg.insecureSSL(), otherCfg.insecureSSL(); insecureSSL != otherInsecureSSL {
+		diffs = append(diffs, fmt.Sprintf("insecure_ssl changed from %q to %q", insecureSSL, otherInsecureSSL))
+	}
+	return diffs
+}
+
+// insecureSSL returns InsecureSSL, defaulting to InsecureSSLDisabled.
+func (pc *PayloadConfig) insecureSSL() string {
+	if pc.InsecureSSL == "" {
+		return InsecureSSLDisabled
+	}
+	return pc.InsecureSSL
+}
+
+// eventSetDiff returns the sorted events that
+// are only in to and those that are only in from.
+func eventSetDiff(from, to []Event) (added, removed []Event) {
+	inFrom := make(map[Event]bool, len(from))
+	for _, event := range from {
+		inFrom[event] = true
+	}
+	inTo := make(map[Event]bool, len(to))
+	for _, event := range to {
+		if !inTo[event] && !inFrom[event] {
+			added = append(added, event)
+		}
+		inTo[event] = true
+	}
+	for event := range inFrom {
+		if !inTo[event] {
+			removed = append(removed, event)
+		}
+	}
+	sort.Slice(added, func(i, j int) bool { return added[i] < added[j] })
+	sort.Slice(removed, func(i, j int) bool { return removed[i] < removed[j] })
+	return added, removed
+}
+
+func (pc *PayloadConfig) hasSecret() bool {
+	return pc != nil && pc.Secret != ""
+}
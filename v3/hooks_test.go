@@ -0,0 +1,911 @@
+// Copyright 2017 orijtech. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gcla
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSubscribeToRepoRequest(t *testing.T) {
+	client := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected HTTP call: %s %s", r.Method, r.URL)
+	})
+
+	req, err := client.SubscribeToRepoRequest(&RepoSubscribeRequest{
+		Owner: "orijtech",
+		Repo:  "gcla",
+		HookSubscription: &SubscribeRequest{
+			Name:   "web",
+			Active: true,
+			Events: []Event{EventPush},
+			Config: &PayloadConfig{URL: "https://hooks.orijtech.com/gcla", ContentType: JSON},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g, w := req.Method, "POST"; g != w {
+		t.Errorf("method: got %q want %q", g, w)
+	}
+	if g, w := req.URL.String(), "https://api.github.com/repos/orijtech/gcla/hooks"; g != w {
+		t.Errorf("url: got %q want %q", g, w)
+	}
+	if g := req.Header.Get("Authorization"); g != "" {
+		t.Errorf("unexpected Authorization header %q", g)
+	}
+	blob, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"name":"web","active":true,"events":["push"],"config":{"url":"https://hooks.orijtech.com/gcla","content_type":"json"}}`
+	if g := string(blob); g != want {
+		t.Errorf("body:\ngot:  %s\nwant: %s", g, want)
+	}
+
+	for i, rsr := range []*RepoSubscribeRequest{
+		nil,
+		{Repo: "gcla", HookSubscription: new(SubscribeRequest)},
+		{Owner: "orijtech", HookSubscription: new(SubscribeRequest)},
+		{Owner: "orijtech", Repo: "gcla"},
+	} {
+		if _, err := client.SubscribeToRepoRequest(rsr); err == nil {
+			t.Errorf("#%d: expected a non-nil error", i)
+		}
+	}
+}
+
+func TestSubscribeToRepoVerifyReachable(t *testing.T) {
+	var probes []string
+	reachable := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		probes = append(probes, r.Method)
+		if r.Header.Get("Authorization") != "" {
+			t.Errorf("the GitHub token leaked to the payload URL")
+		}
+	}))
+	defer reachable.Close()
+	broken := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer broken.Close()
+	dead := httptest.NewServer(http.NotFoundHandler())
+	dead.Close()
+
+	tests := [...]struct {
+		payloadURL string
+		wantErr    bool
+	}{
+		0: {payloadURL: reachable.URL + "/gcla"},
+		1: {payloadURL: broken.URL + "/gcla", wantErr: true},
+		2: {payloadURL: dead.URL + "/gcla", wantErr: true},
+	}
+
+	for i, tt := range tests {
+		var created bool
+		client := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+			created = true
+			fmt.Fprint(w, `{"id":12,"name":"web","active":true}`)
+		})
+		_, err := client.SubscribeToRepo(&RepoSubscribeRequest{
+			Owner:            "orijtech",
+			Repo:             "gcla",
+			VerifyReachable:  true,
+			AllowHTTP:        true,
+			HookSubscription: &SubscribeRequest{Events: []Event{EventPush}, Config: &PayloadConfig{URL: tt.payloadURL}},
+		})
+		if !tt.wantErr {
+			if err != nil {
+				t.Errorf("#%d: unexpected error: %v", i, err)
+			}
+			if !created {
+				t.Errorf("#%d: expected the hook to have been created", i)
+			}
+			continue
+		}
+		upe, ok := err.(*UnreachablePayloadURLError)
+		if !ok {
+			t.Errorf("#%d: got err=%v (%T) want *UnreachablePayloadURLError", i, err, err)
+		} else if upe.URL != tt.payloadURL {
+			t.Errorf("#%d: URL: got %q want %q", i, upe.URL, tt.payloadURL)
+		}
+		if created {
+			t.Errorf("#%d: the hook was created despite its unreachable payload URL", i)
+		}
+	}
+	if g, w := fmt.Sprint(probes), "[HEAD]"; g != w {
+		t.Errorf("probes: got %s want %s", g, w)
+	}
+}
+
+func TestSubscribeToRepoVerifyActive(t *testing.T) {
+	const (
+		unused = `{"code":null,"status":"unused","message":null}`
+		ok     = `{"code":200,"status":"active","message":"OK"}`
+		failed = `{"code":404,"status":"active","message":"Invalid HTTP Response: 404"}`
+	)
+	tests := [...]struct {
+		active bool
+		// lastResponses are reported by successive GETs
+		// of the hook, repeating the last one.
+		lastResponses []string
+		timeout       time.Duration
+		wantInactive  bool
+		wantPingErr   bool
+		wantGets      int
+	}{
+		// GitHub delivers the ping asynchronously.
+		0: {active: true, lastResponses: []string{unused, ok}, wantGets: 2},
+		1: {active: false, lastResponses: []string{ok}, wantInactive: true, wantGets: 1},
+		2: {active: true, lastResponses: []string{unused, failed}, wantPingErr: true, wantGets: 2},
+		// The wait for the ping is bounded.
+		3: {active: true, lastResponses: []string{unused}, timeout: 50 * time.Millisecond, wantPingErr: true},
+	}
+
+	for i, tt := range tests {
+		var pinged bool
+		gets := 0
+		client := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+			switch route := r.Method + " " + r.URL.Path; route {
+			case "POST /repos/orijtech/gcla/hooks":
+				fmt.Fprint(w, `{"id":12,"name":"web","active":true,"config":{"url":"https://hooks.orijtech.com/gcla"},"last_response":`+unused+`}`)
+			case "POST /repos/orijtech/gcla/hooks/12/pings":
+				pinged = true
+				w.WriteHeader(http.StatusNoContent)
+			case "GET /repos/orijtech/gcla/hooks/12":
+				lastResponse := tt.lastResponses[len(tt.lastResponses)-1]
+				if gets < len(tt.lastResponses) {
+					lastResponse = tt.lastResponses[gets]
+				}
+				gets++
+				fmt.Fprintf(w, `{"id":12,"name":"web","active":%v,"config":{"url":"https://hooks.orijtech.com/gcla"},"last_response":%s}`, tt.active, lastResponse)
+			default:
+				http.Error(w, fmt.Sprintf("unexpected route %q", route), http.StatusNotFound)
+			}
+		})
+
+		subs, err := client.SubscribeToRepo(&RepoSubscribeRequest{
+			Owner:         "orijtech",
+			Repo:          "gcla",
+			VerifyActive:  true,
+			VerifyTimeout: tt.timeout,
+			HookSubscription: &SubscribeRequest{
+				Name:   "web",
+				Active: true,
+				Events: []Event{EventPush},
+				Config: &PayloadConfig{URL: "https://hooks.orijtech.com/gcla", ContentType: JSON},
+			},
+		})
+		if !pinged {
+			t.Errorf("#%d: expected the hook to have been pinged", i)
+		}
+		if tt.wantGets != 0 && gets != tt.wantGets {
+			t.Errorf("#%d: gets: got %d want %d", i, gets, tt.wantGets)
+		}
+		if subs == nil {
+			t.Errorf("#%d: expected the hook to be returned, err=%v", i, err)
+			continue
+		}
+		switch {
+		case tt.wantInactive:
+			if _, ok := err.(*InactiveHookError); !ok {
+				t.Errorf("#%d: got err=%v (%T) want *InactiveHookError", i, err, err)
+			}
+			if subs.Active {
+				t.Errorf("#%d: expected the inactive hook to be returned, got %#v", i, subs)
+			}
+		case tt.wantPingErr:
+			if _, ok := err.(*HookPingError); !ok {
+				t.Errorf("#%d: got err=%v (%T) want *HookPingError", i, err, err)
+			}
+		case err != nil:
+			t.Errorf("#%d: unexpected error: %v", i, err)
+		case !subs.Active || subs.LastResponse == nil || subs.LastResponse.Code != 200:
+			t.Errorf("#%d: expected an active hook that answered the ping, got %#v", i, subs)
+		}
+	}
+}
+
+func TestSubscribeToRepoVerifyTimeoutClock(t *testing.T) {
+	now := time.Date(2023, time.March, 14, 17, 0, 0, 0, time.UTC)
+	gets := 0
+	client := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "GET /repos/orijtech/gcla/hooks/12":
+			gets++
+			// Every poll takes an hour according to the clock.
+			now = now.Add(time.Hour)
+			fmt.Fprint(w, `{"id":12,"name":"web","active":true,"last_response":{"code":null,"status":"unused","message":null}}`)
+		case "POST /repos/orijtech/gcla/hooks/12/pings":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			fmt.Fprint(w, `{"id":12,"name":"web","active":true}`)
+		}
+	})
+	WithClock(func() time.Time { return now })(client)
+
+	// The default VerifyTimeout of 10s runs out on the clock after
+	// the first poll, without waiting for it in real time.
+	start := time.Now()
+	_, err := client.SubscribeToRepo(&RepoSubscribeRequest{
+		Owner:        "orijtech",
+		Repo:         "gcla",
+		VerifyActive: true,
+		HookSubscription: &SubscribeRequest{
+			Name:   "web",
+			Events: []Event{EventPush},
+			Config: &PayloadConfig{URL: "https://hooks.orijtech.com/gcla", ContentType: JSON},
+		},
+	})
+	if _, ok := err.(*HookPingError); !ok {
+		t.Errorf("got err=%v (%T) want *HookPingError", err, err)
+	}
+	if g, w := gets, 1; g != w {
+		t.Errorf("gets: got %d want %d", g, w)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("waited %v in real time", elapsed)
+	}
+}
+
+func TestSubscribeRequestSecretMarshaling(t *testing.T) {
+	tests := [...]struct {
+		req  *SubscribeRequest
+		want string
+	}{
+		0: {
+			req:  &SubscribeRequest{Name: "web", Secret: "s3cr3t"},
+			want: `{"name":"web","config":{"secret":"s3cr3t"}}`,
+		},
+		1: {
+			req: &SubscribeRequest{
+				Name:   "web",
+				Secret: "s3cr3t",
+				Config: &PayloadConfig{URL: "https://hooks.orijtech.com/gcla", ContentType: JSON},
+			},
+			want: `{"name":"web","config":{"url":"https://hooks.orijtech.com/gcla","content_type":"json","secret":"s3cr3t"}}`,
+		},
+		2: {
+			req:  &SubscribeRequest{Name: "web", Config: &PayloadConfig{Secret: "inline"}},
+			want: `{"name":"web","config":{"secret":"inline"}}`,
+		},
+		3: {
+			req:  &SubscribeRequest{Name: "web"},
+			want: `{"name":"web"}`,
+		},
+	}
+
+	for i, tt := range tests {
+		var originalConfig PayloadConfig
+		if tt.req.Config != nil {
+			originalConfig = *tt.req.Config
+		}
+		blob, err := json.Marshal(tt.req)
+		if err != nil {
+			t.Errorf("#%d: %v", i, err)
+			continue
+		}
+		if g, w := string(blob), tt.want; g != w {
+			t.Errorf("#%d:\ngot:  %s\nwant: %s", i, g, w)
+		}
+		if tt.req.Config != nil && *tt.req.Config != originalConfig {
+			t.Errorf("#%d: the caller's Config was modified", i)
+		}
+	}
+}
+
+func TestHookHasSecret(t *testing.T) {
+	tests := [...]struct {
+		payload string
+		want    bool
+	}{
+		0: {payload: `{"id":1,"name":"web","config":{"url":"https://hooks.orijtech.com/gcla","secret":"********"}}`, want: true},
+		1: {payload: `{"id":2,"name":"web","config":{"url":"https://hooks.orijtech.com/gcla"}}`, want: false},
+		2: {payload: `{"id":3,"name":"web"}`, want: false},
+	}
+
+	for i, tt := range tests {
+		hook := new(Hook)
+		if err := json.Unmarshal([]byte(tt.payload), hook); err != nil {
+			t.Errorf("#%d: %v", i, err)
+			continue
+		}
+		if g, w := hook.HasSecret(), tt.want; g != w {
+			t.Errorf("#%d: Hook.HasSecret: got %v want %v", i, g, w)
+		}
+		subs := new(Subscription)
+		if err := json.Unmarshal([]byte(tt.payload), subs); err != nil {
+			t.Errorf("#%d: %v", i, err)
+			continue
+		}
+		if g, w := subs.HasSecret(), tt.want; g != w {
+			t.Errorf("#%d: Subscription.HasSecret: got %v want %v", i, g, w)
+		}
+	}
+}
+
+func TestListHooks(t *testing.T) {
+	const lastPage = 7
+	const perPage = 3
+
+	var mu sync.Mutex
+	var inFlight, maxInFlight int
+	client := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		inFlight++
+		if inFlight > maxInFlight {
+			maxInFlight = inFlight
+		}
+		mu.Unlock()
+		defer func() {
+			mu.Lock()
+			inFlight--
+			mu.Unlock()
+		}()
+
+		if g, w := r.URL.Path, "/repos/orijtech/gcla/hooks"; g != w {
+			t.Errorf("path: got %q want %q", g, w)
+		}
+		page := 1
+		if p := r.URL.Query().Get("page"); p != "" {
+			page, _ = strconv.Atoi(p)
+		}
+		if page < lastPage {
+			w.Header().Set("Link", fmt.Sprintf(`<https://api.github.com/repos/orijtech/gcla/hooks?page=%d>; rel="next", <https://api.github.com/repos/orijtech/gcla/hooks?page=%d>; rel="last"`, page+1, lastPage))
+		}
+		// Stagger responses so that later pages can finish first.
+		time.Sleep(time.Duration(lastPage-page) * time.Millisecond)
+		var hooks []string
+		for i := 0; i < perPage; i++ {
+			hooks = append(hooks, fmt.Sprintf(`{"id":%d,"name":"web"}`, (page-1)*perPage+i+1))
+		}
+		fmt.Fprintf(w, "[%s]", strings.Join(hooks, ","))
+	})
+
+	tests := [...]struct {
+		opts *HookListOptions
+	}{
+		0: {opts: nil},
+		1: {opts: &HookListOptions{Concurrency: 3}},
+		2: {opts: &HookListOptions{Concurrency: 100}},
+	}
+
+	for i, tt := range tests {
+		maxInFlight = 0
+		hooks, err := client.ListHooks("orijtech", "gcla", tt.opts)
+		if err != nil {
+			t.Errorf("#%d: %v", i, err)
+			continue
+		}
+		if g, w := len(hooks), lastPage*perPage; g != w {
+			t.Errorf("#%d: got %d hooks want %d", i, g, w)
+			continue
+		}
+		for j, hook := range hooks {
+			if g, w := hook.ID, uint64(j+1); g != w {
+				t.Errorf("#%d: hooks[%d]: got ID %d want %d", i, j, g, w)
+				break
+			}
+		}
+		if maxInFlight > MaxPageConcurrency {
+			t.Errorf("#%d: %d requests were in flight, exceeding %d", i, maxInFlight, MaxPageConcurrency)
+		}
+	}
+}
+
+func TestListHooksPageError(t *testing.T) {
+	client := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("page") {
+		case "":
+			w.Header().Set("Link", `<https://api.github.com/repos/orijtech/gcla/hooks?page=2>; rel="next", <https://api.github.com/repos/orijtech/gcla/hooks?page=3>; rel="last"`)
+			fmt.Fprint(w, `[{"id":1}]`)
+		case "2":
+			http.Error(w, `{"message":"Server Error"}`, http.StatusInternalServerError)
+		default:
+			fmt.Fprint(w, `[{"id":3}]`)
+		}
+	})
+
+	if _, err := client.ListHooks("orijtech", "gcla", &HookListOptions{Concurrency: 2}); !isStatusCode(err, http.StatusInternalServerError) {
+		t.Errorf("got err=%v want a 500 *APIError", err)
+	}
+}
+
+func TestValidatePayloadURL(t *testing.T) {
+	tests := [...]struct {
+		url       string
+		allowHTTP bool
+		wantErr   error
+	}{
+		0:  {url: "https://hooks.orijtech.com/gcla"},
+		1:  {url: "https://hooks.orijtech.com:8443"},
+		2:  {url: "http://hooks.orijtech.com/gcla", wantErr: ErrPayloadURLInsecure},
+		3:  {url: "http://hooks.orijtech.com/gcla", allowHTTP: true},
+		4:  {url: "hooks.orijtech.com/gcla", wantErr: ErrPayloadURLMissingScheme},
+		5:  {url: "ftp://hooks.orijtech.com/gcla", wantErr: ErrPayloadURLInsecure},
+		6:  {url: "https:///gcla", wantErr: ErrPayloadURLMissingHost},
+		7:  {url: "https://localhost:8080/gcla", wantErr: ErrPayloadURLLoopback},
+		8:  {url: "https://127.0.0.1/gcla", wantErr: ErrPayloadURLLoopback},
+		9:  {url: "http://localhost:8080/gcla", allowHTTP: true},
+		10: {url: "https://hooks.orijtech.com/gcla#events", wantErr: ErrPayloadURLFragment},
+		11: {url: "https://hooks.orijtech.com/gcla#", wantErr: ErrPayloadURLFragment},
+	}
+
+	for i, tt := range tests {
+		err := validatePayloadURL(tt.url, tt.allowHTTP)
+		if err != tt.wantErr {
+			t.Errorf("#%d: %q: got err=%v want %v", i, tt.url, err, tt.wantErr)
+		}
+		if !tt.allowHTTP {
+			if err2 := ValidatePayloadURL(tt.url); err2 != err {
+				t.Errorf("#%d: ValidatePayloadURL disagrees: got %v want %v", i, err2, err)
+			}
+		}
+	}
+
+	client := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected HTTP call: %s %s", r.Method, r.URL)
+	})
+	_, err := client.SubscribeToRepo(&RepoSubscribeRequest{
+		Owner: "orijtech",
+		Repo:  "gcla",
+		HookSubscription: &SubscribeRequest{
+			Config: &PayloadConfig{URL: "http://hooks.orijtech.com/gcla"},
+		},
+	})
+	if err != ErrPayloadURLInsecure {
+		t.Errorf("SubscribeToRepo: got err=%v want %v", err, ErrPayloadURLInsecure)
+	}
+}
+
+func TestUnsubscribeByURLPrefix(t *testing.T) {
+	var mu sync.Mutex
+	var deleted []string
+	failPath := ""
+	client := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/repos/orijtech/gcla/hooks":
+			fmt.Fprint(w, `[
+  {"id": 1, "config": {"url": "https://hooks.orijtech.com/gcla"}},
+  {"id": 2, "config": {"url": "https://ci.example.com/hooks"}},
+  {"id": 3, "config": {"url": "https://hooks.orijtech.com/gcla-staging"}}
+]`)
+		case r.Method == "DELETE":
+			mu.Lock()
+			deleted = append(deleted, r.URL.Path)
+			mu.Unlock()
+			if r.URL.Path == failPath {
+				http.Error(w, `{"message":"Server Error"}`, http.StatusInternalServerError)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+			http.NotFound(w, r)
+		}
+	})
+
+	n, err := client.UnsubscribeByURLPrefix("orijtech", "gcla", "https://hooks.orijtech.com/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g, w := n, 2; g != w {
+		t.Errorf("deleted: got %d want %d", g, w)
+	}
+	want := []string{"/repos/orijtech/gcla/hooks/1", "/repos/orijtech/gcla/hooks/3"}
+	if !reflect.DeepEqual(deleted, want) {
+		t.Errorf("deleted hooks:\ngot:  %q\nwant: %q", deleted, want)
+	}
+
+	// Failures are aggregated without stopping at the first.
+	failPath = "/repos/orijtech/gcla/hooks/3"
+	n, err = client.UnsubscribeByURLPrefix("orijtech", "gcla", "https://hooks.orijtech.com/")
+	hes, ok := err.(HookErrors)
+	if !ok || len(hes) != 1 || hes[0].HookID != 3 {
+		t.Fatalf("got err=%v (%T) want HookErrors for hook 3", err, err)
+	}
+	if g, w := n, 1; g != w {
+		t.Errorf("deleted: got %d want %d", g, w)
+	}
+
+	if _, err := client.UnsubscribeByURLPrefix("orijtech", "gcla", ""); err == nil {
+		t.Error("expected an error for an empty prefix")
+	}
+}
+
+func TestSubscribeToRepoDefaultHookConfig(t *testing.T) {
+	client := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected HTTP call: %s %s", r.Method, r.URL)
+	})
+	WithDefaultHookConfig(&PayloadConfig{
+		URL:         "https://hooks.orijtech.com/gcla",
+		ContentType: JSON,
+		Secret:      "s3cr3t",
+	})(client)
+
+	tests := [...]struct {
+		sr   *SubscribeRequest
+		want string
+	}{
+		// Without a Config, the client's default is used.
+		0: {
+			sr:   &SubscribeRequest{Name: "web", Events: []Event{EventPush}},
+			want: `{"name":"web","events":["push"],"config":{"url":"https://hooks.orijtech.com/gcla","content_type":"json","secret":"s3cr3t"}}`,
+		},
+		// Secret still takes precedence.
+		1: {
+			sr:   &SubscribeRequest{Name: "web", Secret: "other"},
+			want: `{"name":"web","config":{"url":"https://hooks.orijtech.com/gcla","content_type":"json","secret":"other"}}`,
+		},
+		// An explicit Config replaces the default.
+		2: {
+			sr:   &SubscribeRequest{Name: "web", Config: &PayloadConfig{URL: "https://ci.orijtech.com/hooks", ContentType: XML}},
+			want: `{"name":"web","config":{"url":"https://ci.orijtech.com/hooks","content_type":"xml"}}`,
+		},
+		// Without a Name, the one that GitHub expects for webhooks is used.
+		3: {
+			sr:   &SubscribeRequest{Events: []Event{EventPush}},
+			want: `{"name":"web","events":["push"],"config":{"url":"https://hooks.orijtech.com/gcla","content_type":"json","secret":"s3cr3t"}}`,
+		},
+	}
+
+	for i, tt := range tests {
+		req, err := client.SubscribeToRepoRequest(&RepoSubscribeRequest{Owner: "orijtech", Repo: "gcla", HookSubscription: tt.sr})
+		if err != nil {
+			t.Errorf("#%d: %v", i, err)
+			continue
+		}
+		blob, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			t.Errorf("#%d: %v", i, err)
+			continue
+		}
+		if g := string(blob); g != tt.want {
+			t.Errorf("#%d: body:\ngot:  %s\nwant: %s", i, g, tt.want)
+		}
+	}
+	if tests[0].sr.Config != nil {
+		t.Errorf("the caller's request was modified: %#v", tests[0].sr.Config)
+	}
+	if tests[3].sr.Name != "" {
+		t.Errorf("the caller's request was modified: %q", tests[3].sr.Name)
+	}
+}
+
+func TestAddHookEvents(t *testing.T) {
+	var patched []string
+	client := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		if g, w := r.URL.Path, "/repos/orijtech/gcla/hooks/7"; g != w {
+			t.Errorf("path: got %q want %q", g, w)
+		}
+		switch r.Method {
+		case "GET":
+			fmt.Fprint(w, `{"id": 7, "events": ["pull_request"], "active": true}`)
+		case "PATCH":
+			blob, _ := ioutil.ReadAll(r.Body)
+			patched = append(patched, string(blob))
+			var body struct{ Events []Event }
+			if err := json.Unmarshal(blob, &body); err != nil {
+				t.Errorf("decoding body: %v", err)
+			}
+			json.NewEncoder(w).Encode(&Subscription{ID: 7, Events: body.Events, Active: true})
+		default:
+			t.Errorf("unexpected method %q", r.Method)
+		}
+	})
+
+	got, err := client.AddHookEvents("orijtech", "gcla", 7, []Event{EventPush, EventPullRequest, EventPush})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Event{EventPullRequest, EventPush}
+	if !reflect.DeepEqual(got.Events, want) {
+		t.Errorf("events: got %q want %q", got.Events, want)
+	}
+	if g, w := patched, []string{`{"events":["pull_request","push"]}`}; !reflect.DeepEqual(g, w) {
+		t.Errorf("PATCH bodies: got %q want %q", g, w)
+	}
+
+	// Nothing new to add, so nothing is patched.
+	patched = nil
+	got, err = client.AddHookEvents("orijtech", "gcla", 7, []Event{EventPullRequest})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(patched) != 0 {
+		t.Errorf("unexpected PATCH: %q", patched)
+	}
+	if !reflect.DeepEqual(got.Events, []Event{EventPullRequest}) {
+		t.Errorf("events: got %q", got.Events)
+	}
+
+	for i, events := range [][]Event{nil, {EventPush, ""}} {
+		if _, err := client.AddHookEvents("orijtech", "gcla", 7, events); err == nil {
+			t.Errorf("#%d: expected a non-nil error", i)
+		}
+	}
+}
+
+func TestSubscribeToRepoArrayResponse(t *testing.T) {
+	const hook = `{"id": 109948940, "name": "web", "active": true, "events": ["push"], "config": {"url": "https://hooks.orijtech.com/gcla"}}`
+
+	tests := [...]struct {
+		body    string
+		wantErr bool
+	}{
+		0: {body: hook},
+		// Some proxies wrap the hook in an array.
+		1: {body: "[" + hook + "]"},
+		2: {body: "\n  [" + hook + "]\n"},
+		3: {body: "[]", wantErr: true},
+		4: {body: "{}", wantErr: true},
+	}
+
+	for i, tt := range tests {
+		client := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, tt.body)
+		})
+		subs, err := client.SubscribeToRepo(&RepoSubscribeRequest{
+			Owner:            "orijtech",
+			Repo:             "gcla",
+			HookSubscription: &SubscribeRequest{Name: "web", Events: []Event{EventPush}},
+		})
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("#%d: expected a non-nil error", i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("#%d: %v", i, err)
+			continue
+		}
+		if subs.ID != 109948940 || !subs.Active || subs.Config == nil || subs.Config.URL != "https://hooks.orijtech.com/gcla" {
+			t.Errorf("#%d: got %#v", i, subs)
+		}
+	}
+}
+
+func TestValidateAgainstSupported(t *testing.T) {
+	client := new(Client)
+	supported := client.SupportedEvents()
+	if len(supported) == 0 {
+		t.Fatal("expected supported events")
+	}
+	for i, event := range supported {
+		if _, err := ParseWebhook(event, []byte(`{}`)); err != nil {
+			t.Errorf("#%d: %q: %v", i, event, err)
+		}
+		if i > 0 && supported[i-1] >= event {
+			t.Errorf("#%d: %q is out of order", i, event)
+		}
+	}
+
+	tests := [...]struct {
+		events  []Event
+		wantErr string
+	}{
+		0: {events: []Event{EventPush, EventPullRequest}},
+		1: {events: []Event{EventWildcard}},
+		2: {events: nil},
+		3: {events: []Event{EventPush, "pushes", "check_suites"}, wantErr: `unsupported events: "pushes", "check_suites"`},
+		4: {events: []Event{EventIssues, EventIssueComment}},
+	}
+
+	for i, tt := range tests {
+		err := client.ValidateAgainstSupported(&SubscribeRequest{Events: tt.events})
+		if tt.wantErr == "" {
+			if err != nil {
+				t.Errorf("#%d: unexpected error: %v", i, err)
+			}
+			continue
+		}
+		if err == nil || err.Error() != tt.wantErr {
+			t.Errorf("#%d: got err=%v want %q", i, err, tt.wantErr)
+		}
+	}
+}
+
+func TestNewSubscribeRequest(t *testing.T) {
+	tests := [...]struct {
+		events  []string
+		want    []Event
+		wantErr bool
+	}{
+		0: {events: []string{"push", "pull_request"}, want: []Event{EventPush, EventPullRequest}},
+		1: {events: []string{" release ", "*"}, want: []Event{EventRelease, EventWildcard}},
+		2: {events: []string{"push", "pushes"}, wantErr: true},
+		3: {events: []string{"push", "  "}, wantErr: true},
+		4: {events: nil, wantErr: true},
+		5: {events: []string{"Push"}, wantErr: true},
+		6: {events: []string{"issues"}, want: []Event{EventIssues}},
+		7: {events: []string{"issues", "issue_comment"}, want: []Event{EventIssues, EventIssueComment}},
+	}
+
+	for i, tt := range tests {
+		sr, err := NewSubscribeRequest("web", true, tt.events...)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("#%d: expected a non-nil error", i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("#%d: %v", i, err)
+			continue
+		}
+		if sr.Name != "web" || !sr.Active {
+			t.Errorf("#%d: got name=%q active=%v", i, sr.Name, sr.Active)
+		}
+		if !reflect.DeepEqual(sr.Events, tt.want) {
+			t.Errorf("#%d: events: got %q want %q", i, sr.Events, tt.want)
+		}
+	}
+}
+
+func TestSubscriptionDiff(t *testing.T) {
+	current := &Subscription{
+		ID:     12,
+		Name:   "web",
+		Active: true,
+		Events: []Event{EventPush, EventPullRequest},
+		Config: &PayloadConfig{URL: "https://hooks.orijtech.com/gcla", ContentType: JSON, Secret: "********"},
+	}
+
+	tests := [...]struct {
+		other *Subscription
+		want  []string
+	}{
+		// IDs, secrets and the order of events don't matter.
+		0: {
+			other: &Subscription{
+				Name:   "web",
+				Active: true,
+				Events: []Event{EventPullRequest, EventPush, EventPush},
+				Config: &PayloadConfig{URL: "https://hooks.orijtech.com/gcla", ContentType: JSON, Secret: "s3cr3t", InsecureSSL: InsecureSSLDisabled},
+			},
+			want: nil,
+		},
+		1: {
+			other: &Subscription{
+				Name:   "web",
+				Active: true,
+				Events: []Event{EventPush, EventStatus, EventIssues},
+				Config: &PayloadConfig{URL: "https://hooks.orijtech.com/gcla", ContentType: JSON},
+			},
+			want: []string{
+				`event "issues" added`,
+				`event "status" added`,
+				`event "pull_request" removed`,
+			},
+		},
+		2: {
+			other: &Subscription{
+				Name:   "web",
+				Events: []Event{EventPush, EventPullRequest},
+				Config: &PayloadConfig{URL: "https://ci.orijtech.com/hooks", ContentType: XML},
+			},
+			want: []string{
+				"active changed from true to false",
+				`URL changed from "https://hooks.orijtech.com/gcla" to "https://ci.orijtech.com/hooks"`,
+				`content type changed from "json" to "xml"`,
+			},
+		},
+		3: {
+			other: nil,
+			want: []string{
+				`name changed from "web" to ""`,
+				"active changed from true to false",
+				`event "pull_request" removed`,
+				`event "push" removed`,
+				`URL changed from "https://hooks.orijtech.com/gcla" to ""`,
+				`content type changed from "json" to ""`,
+			},
+		},
+		// GitHub no longer verifying the payload URL's certificate.
+		4: {
+			other: &Subscription{
+				Name:   "web",
+				Active: true,
+				Events: []Event{EventPush, EventPullRequest},
+				Config: &PayloadConfig{URL: "https://hooks.orijtech.com/gcla", ContentType: JSON, InsecureSSL: InsecureSSLEnabled},
+			},
+			want: []string{`insecure_ssl changed from "0" to "1"`},
+		},
+	}
+
+	for i, tt := range tests {
+		got := current.Diff(tt.other)
+		if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("#%d:\ngot:  %q\nwant: %q", i, got, tt.want)
+		}
+	}
+}
+
+func TestSubscribeToRepoInsecureSSL(t *testing.T) {
+	const hookURL = "https://hooks.internal.orijtech.com/gcla"
+	tests := [...]struct {
+		sr          *SubscribeRequest
+		want        string
+		wantErr     bool
+		wantWarning bool
+	}{
+		0: {
+			sr:          &SubscribeRequest{InsecureSSL: true, Config: &PayloadConfig{URL: hookURL}},
+			want:        `{"name":"web","config":{"url":"` + hookURL + `","insecure_ssl":"1"}}`,
+			wantWarning: true,
+		},
+		1: {
+			sr:   &SubscribeRequest{Config: &PayloadConfig{URL: hookURL, InsecureSSL: InsecureSSLDisabled}},
+			want: `{"name":"web","config":{"url":"` + hookURL + `","insecure_ssl":"0"}}`,
+		},
+		2: {
+			sr:   &SubscribeRequest{Config: &PayloadConfig{URL: hookURL}},
+			want: `{"name":"web","config":{"url":"` + hookURL + `"}}`,
+		},
+		3: {
+			sr:      &SubscribeRequest{Config: &PayloadConfig{URL: hookURL, InsecureSSL: "true"}},
+			wantErr: true,
+		},
+	}
+
+	for i, tt := range tests {
+		client := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+			t.Errorf("#%d: unexpected HTTP call: %s %s", i, r.Method, r.URL)
+		})
+		buf := new(bytes.Buffer)
+		WithLogger(log.New(buf, "", 0))(client)
+
+		var originalConfig PayloadConfig
+		if tt.sr.Config != nil {
+			originalConfig = *tt.sr.Config
+		}
+		req, err := client.SubscribeToRepoRequest(&RepoSubscribeRequest{Owner: "orijtech", Repo: "gcla", HookSubscription: tt.sr})
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("#%d: expected a non-nil error", i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("#%d: %v", i, err)
+			continue
+		}
+		blob, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if g, w := string(blob), tt.want; g != w {
+			t.Errorf("#%d:\ngot:  %s\nwant: %s", i, g, w)
+		}
+		if tt.sr.Config != nil && *tt.sr.Config != originalConfig {
+			t.Errorf("#%d: the caller's Config was modified", i)
+		}
+		if g, w := strings.Contains(buf.String(), "won't verify TLS certificates"), tt.wantWarning; g != w {
+			t.Errorf("#%d: warning logged: got %v want %v: %q", i, g, w, buf)
+		}
+	}
+}
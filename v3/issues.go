@@ -0,0 +1,274 @@
+// Copyright 2017 orijtech. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gcla
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+type Issue struct {
+	ID        uint64     `json:"id,omitempty"`
+	URL       string     `json:"url,omitempty"`
+	HTMLURL   string     `json:"html_url,omitempty"`
+	Number    uint64     `json:"number,omitempty"`
+	State     State      `json:"state,omitempty"`
+	Title     string     `json:"title,omitempty"`
+	Body      string     `json:"body,omitempty"`
+	User      *User      `json:"user,omitempty"`
+	Labels    []*Label   `json:"labels,omitempty"`
+	Milestone *Milestone `json:"milestone,omitempty"`
+	Locked    bool       `json:"locked,omitempty"`
+	Comments  uint64     `json:"comments,omitempty"`
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+	ClosedAt  *time.Time `json:"closed_at,omitempty"`
+
+	// Assignee is the first of Assignees.
+	//
+	// Deprecated: issues can have multiple assignees, use Assignees.
+	Assignee  *User   `json:"assignee,omitempty"`
+	Assignees []*User `json:"assignees,omitempty"`
+
+	// PullRequestLinks is only set when the issue is a pull request.
+	PullRequestLinks *PullRequestLinks `json:"pull_request,omitempty"`
+}
+
+func (i *Issue) UnmarshalJSON(b []byte) error {
+	type issue Issue
+	if err := json.Unmarshal(b, (*issue)(i)); err != nil {
+		return err
+	}
+	if i.Assignee == nil && len(i.Assignees) > 0 {
+		i.Assignee = i.Assignees[0]
+	}
+	return nil
+}
+
+// PullRequestLinks points to the pull request
+// that an issue returned by GitHub represents.
+type PullRequestLinks struct {
+	URL      string `json:"url,omitempty"`
+	HTMLURL  string `json:"html_url,omitempty"`
+	DiffURL  string `json:"diff_url,omitempty"`
+	PatchURL string `json:"patch_url,omitempty"`
+}
+
+// IsPullRequest reports whether the issue is a pull request. GitHub's
+// issues endpoints return pull requests too since every pull request
+// is an issue.
+func (i *Issue) IsPullRequest() bool {
+	return i != nil && i.PullRequestLinks != nil
+}
+
+// IssuesEvent is the payload that's sent when webhook event "issues" is
+// fired, for example with ActionOpened, ActionEdited, ActionClosed,
+// ActionReopened, ActionAssigned or ActionLabeled. Assignee is only
+// set for ActionAssigned and ActionUnassigned, Label for ActionLabeled
+// and ActionUnlabeled, and Changes for ActionEdited.
+type IssuesEvent struct {
+	Action   Action  `json:"action,omitempty"`
+	Changes  *Change `json:"changes,omitempty"`
+	Issue    *Issue  `json:"issue,omitempty"`
+	Assignee *User   `json:"assignee,omitempty"`
+	Label    *Label  `json:"label,omitempty"`
+
+	Repository   *Repository   `json:"repository,omitempty"`
+	Organization *Organization `json:"organization,omitempty"`
+	Sender       *User         `json:"sender,omitempty"`
+	Installation *Installation `json:"installation,omitempty"`
+}
+
+// IssueCommentEvent is the payload that's sent when webhook event
+// "issue_comment" is fired. GitHub fires it for comments on pull
+// requests too, in which case Issue.PullRequestLinks is set.
+type IssueCommentEvent struct {
+	Action  Action   `json:"action,omitempty"`
+	Changes *Change  `json:"changes,omitempty"`
+	Issue   *Issue   `json:"issue,omitempty"`
+	Comment *Comment `json:"comment,omitempty"`
+
+	Repository   *Repository   `json:"repository,omitempty"`
+	Sender       *User         `json:"sender,omitempty"`
+	Installation *Installation `json:"installation,omitempty"`
+}
+
+// IsPullRequest reports whether the comment
+// was made on a pull request rather than an issue.
+func (e *IssueCommentEvent) IsPullRequest() bool {
+	return e != nil && e.Issue.IsPullRequest()
+}
+
+type Label struct {
+	ID          uint64 `json:"id,omitempty"`
+	URL         string `json:"url,omitempty"`
+	Name        string `json:"name,omitempty"`
+	Color       string `json:"color,omitempty"`
+	Description string `json:"description,omitempty"`
+	Default     bool   `json:"default,omitempty"`
+}
+
+// IssueListOptions filters and orders the results of ListIssues.
+// Zero values are omitted, deferring to GitHub's defaults.
+type IssueListOptions struct {
+	// State is one of "open", "closed" or "all".
+	State string
+	// Labels only matches issues that have all of the labels.
+	Labels []string
+	// Assignee is a username, "none" for unassigned
+	// issues or "*" for issues assigned to anyone.
+	Assignee string
+	Creator  string
+	// Since only matches issues updated at or after it.
+	Since *time.Time
+	// Sort is one of "created", "updated" or "comments".
+	Sort string
+	// Direction is either "asc" or "desc".
+	Direction string
+}
+
+func (ilo *IssueListOptions) values() (url.Values, error) {
+	qv := make(url.Values)
+	if ilo == nil {
+		return qv, nil
+	}
+	switch ilo.State {
+	case "", "open", "closed", "all":
+	default:
+		return nil, fmt.Errorf("invalid state %q", ilo.State)
+	}
+	switch ilo.Sort {
+	case "", "created", "updated", "comments":
+	default:
+		return nil, fmt.Errorf("invalid sort %q", ilo.Sort)
+	}
+	if err := validateDirection(ilo.Direction); err != nil {
+		return nil, err
+	}
+	for key, value := range map[string]string{
+		"state":     ilo.State,
+		"labels":    strings.Join(ilo.Labels, ","),
+		"assignee":  ilo.Assignee,
+		"creator":   ilo.Creator,
+		"sort":      ilo.Sort,
+		"direction": ilo.Direction,
+	} {
+		if value != "" {
+			qv.Set(key, value)
+		}
+	}
+	if ilo.Since != nil && !ilo.Since.IsZero() {
+		qv.Set("since", ilo.Since.UTC().Format(time.RFC3339))
+	}
+	return qv, nil
+}
+
+// ListIssues returns the issues of owner/repo that match opts, following
+// pagination. GitHub lists pull requests as issues too, those are left out.
+func (c *Client) ListIssues(owner, repo string, opts *IssueListOptions) ([]*Issue, error) {
+	if owner == "" {
+		return nil, errEmptyOwner
+	}
+	if repo == "" {
+		return nil, errEmptyRepo
+	}
+	qv, err := opts.values()
+	if err != nil {
+		return nil, err
+	}
+	fullURL := fmt.Sprintf("%s/repos/%s/%s/issues", baseURL, owner, repo)
+	if len(qv) > 0 {
+		fullURL += "?" + qv.Encode()
+	}
+
+	var issues []*Issue
+	err = c.getAllPages(fullURL, func(blob []byte) error {
+		var page []*Issue
+		if err := json.Unmarshal(blob, &page); err != nil {
+			return err
+		}
+		for _, issue := range page {
+			if !issue.IsPullRequest() {
+				issues = append(issues, issue)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return issues, nil
+}
+
+var (
+	errEmptyIssueNumber = errors.New("expecting a non-zero issue number")
+	errNoAssignees      = errors.New("expecting at least one assignee")
+)
+
+// AddAssignees assigns the users in assignees to issue number of
+// owner/repo. Pull requests are issues too so number can also refer
+// to a pull request. GitHub silently ignores users who can't be assigned.
+func (c *Client) AddAssignees(owner, repo string, number uint64, assignees []string) (*Issue, error) {
+	return c.doAssigneesRequest("POST", owner, repo, number, assignees)
+}
+
+// RemoveAssignees unassigns the users in assignees from issue,
+// or pull request, number of owner/repo.
+func (c *Client) RemoveAssignees(owner, repo string, number uint64, assignees []string) (*Issue, error) {
+	return c.doAssigneesRequest("DELETE", owner, repo, number, assignees)
+}
+
+type assigneesRequest struct {
+	Assignees []string `json:"assignees"`
+}
+
+func (c *Client) doAssigneesRequest(method, owner, repo string, number uint64, assignees []string) (*Issue, error) {
+	if owner == "" {
+		return nil, errEmptyOwner
+	}
+	if repo == "" {
+		return nil, errEmptyRepo
+	}
+	if number == 0 {
+		return nil, errEmptyIssueNumber
+	}
+	if len(assignees) == 0 {
+		return nil, errNoAssignees
+	}
+	blob, err := json.Marshal(&assigneesRequest{Assignees: assignees})
+	if err != nil {
+		return nil, err
+	}
+	fullURL := fmt.Sprintf("%s/repos/%s/%s/issues/%d/assignees", baseURL, owner, repo, number)
+	req, err := http.NewRequest(method, fullURL, bytes.NewReader(blob))
+	if err != nil {
+		return nil, err
+	}
+	blob, _, err = c.doHTTPReq(req)
+	if err != nil {
+		return nil, err
+	}
+	issue := new(Issue)
+	if err := json.Unmarshal(blob, issue); err != nil {
+		return nil, err
+	}
+	return issue, nil
+}
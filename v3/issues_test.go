@@ -0,0 +1,325 @@
+// Copyright 2017 orijtech. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gcla
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestListIssues(t *testing.T) {
+	client := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		if g, w := r.URL.Path, "/repos/orijtech/gcla/issues"; g != w {
+			t.Errorf("path: got %q want %q", g, w)
+		}
+		switch r.URL.Query().Get("page") {
+		case "":
+			want := "assignee=odeke-em&creator=jadekler&direction=asc&labels=bug%2Cneeds+triage&since=2017-08-21T18%3A30%3A05Z&sort=created&state=all"
+			if g := r.URL.RawQuery; g != want {
+				t.Errorf("query:\ngot:  %s\nwant: %s", g, want)
+			}
+			w.Header().Set("Link", `<https://api.github.com/repos/orijtech/gcla/issues?page=2>; rel="next"`)
+			fmt.Fprint(w, `[
+  {"number": 3, "title": "CLA check fails on forks", "state": "open", "labels": [{"name": "bug", "color": "d73a4a"}]},
+  {"number": 4, "title": "Add ListIssues", "state": "open", "pull_request": {"url": "https://api.github.com/repos/orijtech/gcla/pulls/4"}}
+]`)
+		case "2":
+			fmt.Fprint(w, `[{"number": 1, "title": "Document the server", "state": "closed"}]`)
+		}
+	})
+
+	since := time.Date(2017, time.August, 21, 18, 30, 5, 0, time.UTC)
+	issues, err := client.ListIssues("orijtech", "gcla", &IssueListOptions{
+		State:     "all",
+		Labels:    []string{"bug", "needs triage"},
+		Assignee:  "odeke-em",
+		Creator:   "jadekler",
+		Since:     &since,
+		Sort:      "created",
+		Direction: "asc",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var numbers []uint64
+	for _, issue := range issues {
+		numbers = append(numbers, issue.Number)
+	}
+	if g, w := fmt.Sprint(numbers), "[3 1]"; g != w {
+		t.Errorf("issues: got %s want %s, pull requests must be excluded", g, w)
+	}
+	if len(issues) > 0 && (len(issues[0].Labels) != 1 || issues[0].Labels[0].Name != "bug") {
+		t.Errorf("labels: got %+v", issues[0].Labels)
+	}
+}
+
+func TestListIssuesInvalidOptions(t *testing.T) {
+	client := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request to %q", r.URL)
+	})
+	invalid := []*IssueListOptions{
+		{State: "merged"},
+		{Sort: "popularity"},
+		{Direction: "sideways"},
+	}
+	for i, opts := range invalid {
+		if _, err := client.ListIssues("orijtech", "gcla", opts); err == nil {
+			t.Errorf("#%d: expected an error for %+v", i, opts)
+		}
+	}
+}
+
+func TestIssueIsPullRequest(t *testing.T) {
+	blob := []byte(`[
+  {"number": 3, "title": "CLA check fails on forks"},
+  {
+    "number": 4,
+    "title": "Add ListIssues",
+    "pull_request": {
+      "url": "https://api.github.com/repos/orijtech/gcla/pulls/4",
+      "html_url": "https://github.com/orijtech/gcla/pull/4",
+      "diff_url": "https://github.com/orijtech/gcla/pull/4.diff",
+      "patch_url": "https://github.com/orijtech/gcla/pull/4.patch"
+    }
+  }
+]`)
+	var issues []*Issue
+	if err := json.Unmarshal(blob, &issues); err != nil {
+		t.Fatal(err)
+	}
+	if issues[0].IsPullRequest() {
+		t.Errorf("#3 is a plain issue")
+	}
+	if !issues[1].IsPullRequest() {
+		t.Fatalf("#4 is a pull request")
+	}
+	if g, w := issues[1].PullRequestLinks.DiffURL, "https://github.com/orijtech/gcla/pull/4.diff"; g != w {
+		t.Errorf("diff_url: got %q want %q", g, w)
+	}
+}
+
+func TestParseIssueCommentEvent(t *testing.T) {
+	tests := [...]struct {
+		payload string
+		wantPR  bool
+	}{
+		0: {
+			payload: `{
+  "action": "created",
+  "issue": {"number": 3, "title": "CLA check fails on forks"},
+  "comment": {"id": 11, "body": "Still failing"},
+  "repository": {"full_name": "orijtech/gcla"},
+  "sender": {"login": "odeke-em"}
+}`,
+			wantPR: false,
+		},
+		1: {
+			payload: `{
+  "action": "created",
+  "issue": {
+    "number": 4,
+    "title": "Add ListIssues",
+    "pull_request": {
+      "url": "https://api.github.com/repos/orijtech/gcla/pulls/4",
+      "html_url": "https://github.com/orijtech/gcla/pull/4"
+    }
+  },
+  "comment": {"id": 12, "body": "LGTM"},
+  "repository": {"full_name": "orijtech/gcla"},
+  "sender": {"login": "odeke-em"}
+}`,
+			wantPR: true,
+		},
+	}
+
+	for i, tt := range tests {
+		got, err := ParseWebhook(EventIssueComment, []byte(tt.payload))
+		if err != nil {
+			t.Errorf("#%d: %v", i, err)
+			continue
+		}
+		ice, ok := got.(*IssueCommentEvent)
+		if !ok {
+			t.Errorf("#%d: got %T want *IssueCommentEvent", i, got)
+			continue
+		}
+		if g, w := ice.IsPullRequest(), tt.wantPR; g != w {
+			t.Errorf("#%d: IsPullRequest: got %v want %v", i, g, w)
+		}
+		if g, w := ice.Comment.ID, uint64(11+i); g != w {
+			t.Errorf("#%d: comment ID: got %d want %d", i, g, w)
+		}
+		if tt.wantPR && ice.Issue.PullRequestLinks.HTMLURL == "" {
+			t.Errorf("#%d: expected the pull request's html_url", i)
+		}
+	}
+
+	var nilEvent *IssueCommentEvent
+	if nilEvent.IsPullRequest() {
+		t.Errorf("a nil event is not a pull request")
+	}
+}
+
+func TestIssueAssignees(t *testing.T) {
+	issue := new(Issue)
+	blob := []byte(`{"number":3,"assignees":[{"login":"odeke-em","id":1},{"login":"jadekler","id":2}]}`)
+	if err := json.Unmarshal(blob, issue); err != nil {
+		t.Fatal(err)
+	}
+	if g, w := len(issue.Assignees), 2; g != w {
+		t.Fatalf("got %d assignees want %d", g, w)
+	}
+	if issue.Assignee == nil || issue.Assignee.Username != "odeke-em" {
+		t.Errorf("assignee: got %#v want odeke-em", issue.Assignee)
+	}
+}
+
+func TestAddAndRemoveAssignees(t *testing.T) {
+	assigned := map[string]bool{"odeke-em": true}
+	client := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		if g, w := r.URL.Path, "/repos/orijtech/gcla/issues/4/assignees"; g != w {
+			t.Errorf("path: got %q want %q", g, w)
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		ar := new(assigneesRequest)
+		if err := json.Unmarshal(body, ar); err != nil {
+			t.Errorf("decoding body %s: %v", body, err)
+		}
+		for _, login := range ar.Assignees {
+			switch r.Method {
+			case "POST":
+				assigned[login] = true
+			case "DELETE":
+				delete(assigned, login)
+			}
+		}
+		var users []string
+		for _, login := range []string{"odeke-em", "jadekler", "rakyll"} {
+			if assigned[login] {
+				users = append(users, fmt.Sprintf(`{"login":%q}`, login))
+			}
+		}
+		if r.Method == "POST" {
+			w.WriteHeader(http.StatusCreated)
+		}
+		fmt.Fprintf(w, `{"number":4,"pull_request":{"url":"https://api.github.com/repos/orijtech/gcla/pulls/4"},"assignees":[%s]}`, strings.Join(users, ","))
+	})
+
+	logins := func(issue *Issue) string {
+		var names []string
+		for _, user := range issue.Assignees {
+			names = append(names, user.Username)
+		}
+		return fmt.Sprint(names)
+	}
+
+	issue, err := client.AddAssignees("orijtech", "gcla", 4, []string{"jadekler", "rakyll"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g, w := logins(issue), "[odeke-em jadekler rakyll]"; g != w {
+		t.Errorf("after adding: got %s want %s", g, w)
+	}
+	if !issue.IsPullRequest() {
+		t.Errorf("expected #4 to be a pull request")
+	}
+
+	issue, err = client.RemoveAssignees("orijtech", "gcla", 4, []string{"odeke-em"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g, w := logins(issue), "[jadekler rakyll]"; g != w {
+		t.Errorf("after removing: got %s want %s", g, w)
+	}
+
+	if _, err := client.AddAssignees("orijtech", "gcla", 4, nil); err == nil {
+		t.Errorf("expected an error without assignees")
+	}
+}
+
+func TestParseIssuesEvent(t *testing.T) {
+	tests := [...]struct {
+		payload      string
+		wantAction   Action
+		wantAssignee string
+		wantLabel    string
+	}{
+		0: {
+			payload: `{
+  "action": "opened",
+  "issue": {"number": 3, "title": "CLA check fails on forks", "state": "open", "user": {"login": "odeke-em", "id": 7}},
+  "repository": {"full_name": "orijtech/gcla"},
+  "sender": {"login": "odeke-em", "id": 7}
+}`,
+			wantAction: ActionOpened,
+		},
+		1: {
+			payload: `{
+  "action": "assigned",
+  "issue": {"number": 3, "assignees": [{"login": "jadekler", "id": 8}]},
+  "assignee": {"login": "jadekler", "id": 8},
+  "repository": {"full_name": "orijtech/gcla"},
+  "sender": {"login": "odeke-em", "id": 7}
+}`,
+			wantAction:   ActionAssigned,
+			wantAssignee: "jadekler",
+		},
+		2: {
+			payload: `{
+  "action": "labeled",
+  "issue": {"number": 3, "labels": [{"id": 1, "name": "bug", "color": "d73a4a"}]},
+  "label": {"id": 1, "name": "bug", "color": "d73a4a"},
+  "repository": {"full_name": "orijtech/gcla"},
+  "organization": {"login": "orijtech", "id": 25489431},
+  "sender": {"login": "odeke-em", "id": 7}
+}`,
+			wantAction: ActionLabeled,
+			wantLabel:  "bug",
+		},
+	}
+
+	for i, tt := range tests {
+		ie, err := ParseIssuesEvent([]byte(tt.payload), WithEvent(EventIssues), DisallowUnknownFields())
+		if err != nil {
+			t.Errorf("#%d: %v", i, err)
+			continue
+		}
+		if g, w := ie.Action, tt.wantAction; g != w {
+			t.Errorf("#%d: action: got %q want %q", i, g, w)
+		}
+		if ie.Issue == nil || ie.Issue.Number != 3 {
+			t.Errorf("#%d: issue: got %#v", i, ie.Issue)
+		}
+		var assignee, label string
+		if ie.Assignee != nil {
+			assignee = ie.Assignee.Username
+		}
+		if ie.Label != nil {
+			label = ie.Label.Name
+		}
+		if g, w := assignee, tt.wantAssignee; g != w {
+			t.Errorf("#%d: assignee: got %q want %q", i, g, w)
+		}
+		if g, w := label, tt.wantLabel; g != w {
+			t.Errorf("#%d: label: got %q want %q", i, g, w)
+		}
+	}
+}
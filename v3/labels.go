@@ -0,0 +1,221 @@
+// Copyright 2017 orijtech. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gcla
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+var (
+	errNilLabel       = errors.New("expecting a non-nil label")
+	errEmptyLabelName = errors.New("expecting a non-empty label name")
+
+	// ErrLabelExists is returned by CreateLabel when the
+	// repository already has a label with the same name.
+	ErrLabelExists = errors.New("a label with the same name already exists")
+)
+
+// EnsureLabels makes owner/repo have labels, creating the ones that are
+// missing and updating the color and description of the ones that differ.
+// Labels are matched by name, ignoring case like GitHub does. An empty
+// Description leaves the existing one alone. Other labels are left alone.
+func (c *Client) EnsureLabels(owner, repo string, labels []*Label) error {
+	for _, l := range labels {
+		if l == nil {
+			return errNilLabel
+		}
+		if l.Name == "" {
+			return errEmptyLabelName
+		}
+	}
+	existing, err := c.listLabels(owner, repo)
+	if err != nil {
+		return err
+	}
+	byName := make(map[string]*Label, len(existing))
+	for _, l := range existing {
+		byName[strings.ToLower(l.Name)] = l
+	}
+
+	for _, want := range labels {
+		got, ok := byName[strings.ToLower(want.Name)]
+		if !ok {
+			if _, err := c.CreateLabel(owner, repo, want); err != nil {
+				return err
+			}
+			continue
+		}
+		if labelMatches(got, want) {
+			continue
+		}
+		update := &Label{Color: want.Color, Description: want.Description}
+		if _, err := c.UpdateLabel(owner, repo, got.Name, update); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func labelMatches(got, want *Label) bool {
+	if normalizeColor(got.Color) != normalizeColor(want.Color) {
+		return false
+	}
+	return want.Description == "" || got.Description == want.Description
+}
+
+// normalizeColor returns color as GitHub
+// reports it, in lowercase hex without a "#".
+func normalizeColor(color string) string {
+	return strings.ToLower(strings.TrimPrefix(color, "#"))
+}
+
+func (c *Client) listLabels(owner, repo string) ([]*Label, error) {
+	if owner == "" {
+		return nil, errEmptyOwner
+	}
+	if repo == "" {
+		return nil, errEmptyRepo
+	}
+	fullURL := fmt.Sprintf("%s/repos/%s/%s/labels", baseURL, owner, repo)
+
+	var labels []*Label
+	err := c.getAllPages(fullURL, func(blob []byte) error {
+		var page []*Label
+		if err := json.Unmarshal(blob, &page); err != nil {
+			return err
+		}
+		labels = append(labels, page...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return labels, nil
+}
+
+// labelRequest is the body of the requests that create or update labels.
+type labelRequest struct {
+	Name        string `json:"name,omitempty"`
+	NewName     string `json:"new_name,omitempty"`
+	Color       string `json:"color,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// CreateLabel creates label l in owner/repo. Name is required and Color
+// is a hex color code, with or without a "#". If owner/repo already has
+// a label named l.Name, ignoring case, ErrLabelExists is returned.
+func (c *Client) CreateLabel(owner, repo string, l *Label) (*Label, error) {
+	if l == nil {
+		return nil, errNilLabel
+	}
+	if l.Name == "" {
+		return nil, errEmptyLabelName
+	}
+	lr := &labelRequest{Name: l.Name, Color: normalizeColor(l.Color), Description: l.Description}
+	label, err := c.doLabelRequest("POST", owner, repo, "", lr)
+	if isAlreadyExists(err) {
+		return nil, ErrLabelExists
+	}
+	return label, err
+}
+
+// isAlreadyExists reports whether err is GitHub's
+// "422 Unprocessable Entity" for a duplicate resource.
+func isAlreadyExists(err error) bool {
+	ae, ok := err.(*APIError)
+	if !ok || ae.StatusCode != http.StatusUnprocessableEntity {
+		return false
+	}
+	for _, fe := range ae.Errors {
+		if fe != nil && fe.Code == "already_exists" {
+			return true
+		}
+	}
+	return false
+}
+
+// UpdateLabel applies the non-zero fields of l to the label named name
+// in owner/repo. The label is renamed if l.Name is set and differs from name.
+func (c *Client) UpdateLabel(owner, repo, name string, l *Label) (*Label, error) {
+	if l == nil {
+		return nil, errNilLabel
+	}
+	if name == "" {
+		return nil, errEmptyLabelName
+	}
+	lr := &labelRequest{Color: normalizeColor(l.Color), Description: l.Description}
+	if l.Name != name {
+		lr.NewName = l.Name
+	}
+	return c.doLabelRequest("PATCH", owner, repo, name, lr)
+}
+
+// DeleteLabel deletes the label named name from owner/repo,
+// removing it from every issue and pull request that has it.
+func (c *Client) DeleteLabel(owner, repo, name string) error {
+	if owner == "" {
+		return errEmptyOwner
+	}
+	if repo == "" {
+		return errEmptyRepo
+	}
+	if name == "" {
+		return errEmptyLabelName
+	}
+	fullURL := fmt.Sprintf("%s/repos/%s/%s/labels/%s", baseURL, owner, repo, url.PathEscape(name))
+	req, err := http.NewRequest("DELETE", fullURL, nil)
+	if err != nil {
+		return err
+	}
+	// GitHub responds with "204 No Content".
+	_, _, err = c.doHTTPReq(req)
+	return err
+}
+
+func (c *Client) doLabelRequest(method, owner, repo, name string, lr *labelRequest) (*Label, error) {
+	if owner == "" {
+		return nil, errEmptyOwner
+	}
+	if repo == "" {
+		return nil, errEmptyRepo
+	}
+	blob, err := json.Marshal(lr)
+	if err != nil {
+		return nil, err
+	}
+	fullURL := fmt.Sprintf("%s/repos/%s/%s/labels", baseURL, owner, repo)
+	if name != "" {
+		fullURL += "/" + url.PathEscape(name)
+	}
+	req, err := http.NewRequest(method, fullURL, bytes.NewReader(blob))
+	if err != nil {
+		return nil, err
+	}
+	blob, _, err = c.doHTTPReq(req)
+	if err != nil {
+		return nil, err
+	}
+	label := new(Label)
+	if err := json.Unmarshal(blob, label); err != nil {
+		return nil, err
+	}
+	return label, nil
+}
@@ -0,0 +1,187 @@
+// Copyright 2017 orijtech. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gcla
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"testing"
+)
+
+func TestEnsureLabels(t *testing.T) {
+	const existing = `[
+  {"id": 1, "name": "bug", "color": "d73a4a", "description": "Something isn't working"},
+  {"id": 2, "name": "Good first issue", "color": "7057ff", "description": "Good for newcomers"},
+  {"id": 3, "name": "wontfix", "color": "ffffff"}
+]`
+
+	tests := [...]struct {
+		labels []*Label
+		want   []string
+	}{
+		// Already matching, colors and names compare case-insensitively.
+		0: {
+			labels: []*Label{
+				{Name: "bug", Color: "#D73A4A", Description: "Something isn't working"},
+				{Name: "good first issue", Color: "7057ff"},
+			},
+			want: nil,
+		},
+		// A missing label is created.
+		1: {
+			labels: []*Label{{Name: "cla: yes", Color: "0e8a16", Description: "Signed the CLA"}},
+			want: []string{
+				`POST /repos/orijtech/gcla/labels {"name":"cla: yes","color":"0e8a16","description":"Signed the CLA"}`,
+			},
+		},
+		// A color mismatch updates the existing label, keeping its name.
+		2: {
+			labels: []*Label{{Name: "good first issue", Color: "#008672"}},
+			want: []string{
+				`PATCH /repos/orijtech/gcla/labels/Good%20first%20issue {"color":"008672"}`,
+			},
+		},
+		// So does a description mismatch.
+		3: {
+			labels: []*Label{{Name: "bug", Color: "d73a4a", Description: "Broken"}},
+			want: []string{
+				`PATCH /repos/orijtech/gcla/labels/bug {"color":"d73a4a","description":"Broken"}`,
+			},
+		},
+	}
+
+	for i, tt := range tests {
+		var got []string
+		client := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == "GET" {
+				fmt.Fprint(w, existing)
+				return
+			}
+			blob, _ := ioutil.ReadAll(r.Body)
+			got = append(got, fmt.Sprintf("%s %s %s", r.Method, r.URL.EscapedPath(), blob))
+			fmt.Fprint(w, `{"id": 4}`)
+		})
+		if err := client.EnsureLabels("orijtech", "gcla", tt.labels); err != nil {
+			t.Errorf("#%d: %v", i, err)
+			continue
+		}
+		if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("#%d: requests:\ngot:  %q\nwant: %q", i, got, tt.want)
+		}
+	}
+}
+
+func TestEnsureLabelsErrors(t *testing.T) {
+	client := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "GET" {
+			fmt.Fprint(w, `[]`)
+			return
+		}
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		fmt.Fprint(w, `{"message":"Validation Failed","errors":[{"resource":"Label","code":"invalid","field":"color"}]}`)
+	})
+
+	err := client.EnsureLabels("orijtech", "gcla", []*Label{{Name: "bug", Color: "red"}})
+	if !isStatusCode(err, http.StatusUnprocessableEntity) {
+		t.Errorf("got err=%v want a 422 *APIError", err)
+	}
+
+	for i, labels := range [][]*Label{
+		{nil},
+		{{Color: "d73a4a"}},
+	} {
+		if err := client.EnsureLabels("orijtech", "gcla", labels); err == nil {
+			t.Errorf("#%d: expected a non-nil error", i)
+		}
+	}
+	if err := client.EnsureLabels("", "gcla", nil); err == nil {
+		t.Error("expected an error for an empty owner")
+	}
+}
+
+func TestLabelCRUD(t *testing.T) {
+	var got []string
+	client := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		var blob []byte
+		if r.Body != nil {
+			blob, _ = ioutil.ReadAll(r.Body)
+		}
+		got = append(got, fmt.Sprintf("%s %s %s", r.Method, r.URL.EscapedPath(), blob))
+		switch {
+		case r.Method == "DELETE":
+			w.WriteHeader(http.StatusNoContent)
+		case r.Method == "POST" && r.URL.Path == "/repos/orijtech/gcla/labels" && len(got) > 1:
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			fmt.Fprint(w, `{"message":"Validation Failed","errors":[{"resource":"Label","code":"already_exists","field":"name"}]}`)
+		case r.Method == "POST":
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{"id": 208045946, "name": "bug", "color": "f29513", "description": "Something isn't working"}`)
+		case r.Method == "PATCH":
+			fmt.Fprint(w, `{"id": 208045946, "name": "bug :bug:", "color": "b01f26", "description": "Something isn't working"}`)
+		}
+	})
+
+	label, err := client.CreateLabel("orijtech", "gcla", &Label{Name: "bug", Color: "#F29513", Description: "Something isn't working"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g, w := label.ID, uint64(208045946); g != w {
+		t.Errorf("id: got %d want %d", g, w)
+	}
+	if _, err := client.CreateLabel("orijtech", "gcla", &Label{Name: "bug"}); err != ErrLabelExists {
+		t.Errorf("duplicate: got err=%v want %v", err, ErrLabelExists)
+	}
+
+	// Renaming.
+	label, err = client.UpdateLabel("orijtech", "gcla", "bug", &Label{Name: "bug :bug:", Color: "b01f26"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g, w := label.Name, "bug :bug:"; g != w {
+		t.Errorf("name: got %q want %q", g, w)
+	}
+	if err := client.DeleteLabel("orijtech", "gcla", "bug :bug:"); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		`POST /repos/orijtech/gcla/labels {"name":"bug","color":"f29513","description":"Something isn't working"}`,
+		`POST /repos/orijtech/gcla/labels {"name":"bug"}`,
+		`PATCH /repos/orijtech/gcla/labels/bug {"new_name":"bug :bug:","color":"b01f26"}`,
+		`DELETE /repos/orijtech/gcla/labels/bug%20:bug: `,
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("requests:\ngot:  %q\nwant: %q", got, want)
+	}
+
+	for i, l := range []*Label{nil, {Color: "f29513"}} {
+		if _, err := client.CreateLabel("orijtech", "gcla", l); err == nil {
+			t.Errorf("CreateLabel #%d: expected a non-nil error", i)
+		}
+	}
+	if _, err := client.UpdateLabel("orijtech", "gcla", "", &Label{Color: "f29513"}); err == nil {
+		t.Error("UpdateLabel: expected an error for an empty name")
+	}
+	if _, err := client.UpdateLabel("orijtech", "gcla", "bug", nil); err == nil {
+		t.Error("UpdateLabel: expected an error for a nil label")
+	}
+	if err := client.DeleteLabel("orijtech", "gcla", ""); err == nil {
+		t.Error("DeleteLabel: expected an error for an empty name")
+	}
+	if len(got) != len(want) {
+		t.Errorf("invalid arguments still sent %d requests", len(got)-len(want))
+	}
+}
@@ -0,0 +1,107 @@
+// Copyright 2017 orijtech. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gcla
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// Logger is the minimal logging interface used by Client and
+// WebhookHandler. It is satisfied by *log.Logger from the standard library.
+type Logger interface {
+	Printf(format string, args ...interface{})
+}
+
+type nopLogger struct{}
+
+func (nopLogger) Printf(string, ...interface{}) {}
+
+// prefixLogger is a child logger that prefixes every line,
+// for example to correlate the lines logged for a delivery.
+type prefixLogger struct {
+	parent Logger
+	prefix string
+}
+
+func (pl *prefixLogger) Printf(format string, args ...interface{}) {
+	pl.parent.Printf("%s%s", pl.prefix, fmt.Sprintf(format, args...))
+}
+
+func orNopLogger(l Logger) Logger {
+	if l == nil {
+		return nopLogger{}
+	}
+	return l
+}
+
+// WithLogger makes a Client log failed requests to l.
+// By default nothing is logged.
+func WithLogger(l Logger) ClientOption {
+	return func(c *Client) {
+		c.logger = l
+	}
+}
+
+// WithLoggedQueries makes a Client log the query strings of URLs, which
+// are redacted by default since some, like those of installation token
+// exchanges, carry secrets. It is only meant for debugging.
+func WithLoggedQueries() ClientOption {
+	return func(c *Client) {
+		c.logQueries = true
+	}
+}
+
+// sanitizeURL returns u without its query string, fragment and user
+// info, any of which may carry credentials, so that it is safe to log.
+func sanitizeURL(u *url.URL) string {
+	if u == nil {
+		return ""
+	}
+	sanitized := *u
+	sanitized.User = nil
+	sanitized.RawQuery = ""
+	sanitized.ForceQuery = false
+	sanitized.Fragment = ""
+	sanitized.RawFragment = ""
+	return sanitized.String()
+}
+
+// logURL returns the form of u that the Client's logger should print.
+func (c *Client) logURL(u *url.URL) string {
+	c.mu.RLock()
+	logQueries := c.logQueries
+	c.mu.RUnlock()
+	if logQueries && u != nil {
+		return u.String()
+	}
+	return sanitizeURL(u)
+}
+
+func (c *Client) log() Logger {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return orNopLogger(c.logger)
+}
+
+// WithWebhookLogger makes a WebhookHandler log each delivery and why
+// any was rejected to l. Every line carries the delivery's GUID and event,
+// as do the lines that callbacks log through Delivery.Logger.
+// By default nothing is logged. ParseWebhook ignores it.
+func WithWebhookLogger(l Logger) WebhookOption {
+	return func(wc *webhookConfig) {
+		wc.logger = l
+	}
+}
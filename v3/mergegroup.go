@@ -0,0 +1,41 @@
+// Copyright 2017 orijtech. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gcla
+
+// MergeGroupEvent is the payload sent when webhook "merge_group" is
+// fired for repositories that use a merge queue. ActionChecksRequested
+// asks CI to report the required checks against MergeGroup.HeadSHA,
+// the merge queue's temporary commit. ActionDestroyed carries a Reason,
+// one of "merged", "invalidated" or "dequeued".
+type MergeGroupEvent struct {
+	Action     Action      `json:"action,omitempty"`
+	Reason     string      `json:"reason,omitempty"`
+	MergeGroup *MergeGroup `json:"merge_group,omitempty"`
+
+	Repository   *Repository   `json:"repository,omitempty"`
+	Organization *Organization `json:"organization,omitempty"`
+	Sender       *User         `json:"sender,omitempty"`
+	Installation *Installation `json:"installation,omitempty"`
+}
+
+// MergeGroup is a group of pull requests that a merge queue
+// tests together on a temporary branch before merging them.
+type MergeGroup struct {
+	HeadSHA    string  `json:"head_sha,omitempty"`
+	HeadRef    string  `json:"head_ref,omitempty"`
+	BaseSHA    string  `json:"base_sha,omitempty"`
+	BaseRef    string  `json:"base_ref,omitempty"`
+	HeadCommit *Commit `json:"head_commit,omitempty"`
+}
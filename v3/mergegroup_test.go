@@ -0,0 +1,77 @@
+// Copyright 2017 orijtech. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gcla
+
+import (
+	"testing"
+)
+
+func TestParseMergeGroupEvent(t *testing.T) {
+	payload := []byte(`{
+  "action": "checks_requested",
+  "merge_group": {
+    "head_sha": "ec26c3e57ca3a959ca5aad62de7213c562f8c821",
+    "head_ref": "refs/heads/gh-readonly-queue/master/pr-42-f95f852bd8fca8fcc58a9a2d6c842781e32a215e",
+    "base_sha": "f95f852bd8fca8fcc58a9a2d6c842781e32a215e",
+    "base_ref": "refs/heads/master",
+    "head_commit": {
+      "id": "ec26c3e57ca3a959ca5aad62de7213c562f8c821",
+      "tree_id": "31b122c26a97cf9af023e9ddab94a82c6e77b0ea",
+      "message": "Merge pull request #42 from orijtech/merge-queue",
+      "timestamp": "2023-03-14T17:18:26Z",
+      "author": {"name": "Emmanuel T Odeke", "email": "emm.odeke@gmail.com"},
+      "committer": {"name": "GitHub", "email": "noreply@github.com"}
+    }
+  },
+  "repository": {"id": 35129377, "full_name": "orijtech/gcla"},
+  "organization": {"login": "orijtech", "id": 25489431},
+  "sender": {"login": "odeke-em", "id": 1},
+  "installation": {"id": 42}
+}`)
+
+	got, err := ParseWebhook(EventMergeGroup, payload, DisallowUnknownFields())
+	if err != nil {
+		t.Fatal(err)
+	}
+	mge, ok := got.(*MergeGroupEvent)
+	if !ok {
+		t.Fatalf("got %T want *MergeGroupEvent", got)
+	}
+	if g, w := mge.Action, ActionChecksRequested; g != w {
+		t.Errorf("action: got %q want %q", g, w)
+	}
+	mg := mge.MergeGroup
+	if mg == nil {
+		t.Fatal("expected a non-nil merge group")
+	}
+	if g, w := mg.HeadSHA, "ec26c3e57ca3a959ca5aad62de7213c562f8c821"; g != w {
+		t.Errorf("head_sha: got %q want %q", g, w)
+	}
+	if g, w := mg.BaseSHA, "f95f852bd8fca8fcc58a9a2d6c842781e32a215e"; g != w {
+		t.Errorf("base_sha: got %q want %q", g, w)
+	}
+	if g, w := mg.BaseRef, "refs/heads/master"; g != w {
+		t.Errorf("base_ref: got %q want %q", g, w)
+	}
+	if mg.HeadCommit == nil || mg.HeadCommit.ID != mg.HeadSHA {
+		t.Errorf("head_commit: got %#v", mg.HeadCommit)
+	}
+	if mge.Repository == nil || mge.Repository.FullName != "orijtech/gcla" {
+		t.Errorf("repository: got %#v", mge.Repository)
+	}
+	if mge.Sender == nil || mge.Sender.Username != "odeke-em" {
+		t.Errorf("sender: got %#v", mge.Sender)
+	}
+}
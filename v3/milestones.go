@@ -0,0 +1,182 @@
+// Copyright 2017 orijtech. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gcla
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+var errEmptyMilestoneNumber = errors.New("expecting a non-zero milestone number")
+
+// ListMilestones returns the milestones of owner/repo, following pagination.
+// state is one of "open", "closed" or "all" and if empty, defaults to "open".
+func (c *Client) ListMilestones(owner, repo string, state string) ([]*Milestone, error) {
+	if owner == "" {
+		return nil, errEmptyOwner
+	}
+	if repo == "" {
+		return nil, errEmptyRepo
+	}
+	switch state {
+	case "", "open", "closed", "all":
+	default:
+		return nil, fmt.Errorf("invalid state %q, expecting one of \"open\", \"closed\" or \"all\"", state)
+	}
+	fullURL := fmt.Sprintf("%s/repos/%s/%s/milestones", baseURL, owner, repo)
+	if state != "" {
+		fullURL += "?" + url.Values{"state": {state}}.Encode()
+	}
+
+	var milestones []*Milestone
+	err := c.getAllPages(fullURL, func(blob []byte) error {
+		var page []*Milestone
+		if err := json.Unmarshal(blob, &page); err != nil {
+			return err
+		}
+		milestones = append(milestones, page...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return milestones, nil
+}
+
+// GetMilestone retrieves milestone number of owner/repo.
+func (c *Client) GetMilestone(owner, repo string, number uint64) (*Milestone, error) {
+	if owner == "" {
+		return nil, errEmptyOwner
+	}
+	if repo == "" {
+		return nil, errEmptyRepo
+	}
+	if number == 0 {
+		return nil, errEmptyMilestoneNumber
+	}
+	fullURL := fmt.Sprintf("%s/repos/%s/%s/milestones/%d", baseURL, owner, repo, number)
+	req, err := http.NewRequest("GET", fullURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	blob, _, err := c.doHTTPReq(req)
+	if err != nil {
+		return nil, err
+	}
+	milestone := new(Milestone)
+	if err := json.Unmarshal(blob, milestone); err != nil {
+		return nil, err
+	}
+	return milestone, nil
+}
+
+// MilestoneRequest describes a milestone to create or the changes to
+// make to an existing one. Zero values are left out of the request.
+type MilestoneRequest struct {
+	Title string `json:"title,omitempty"`
+	// State is either "open" or "closed".
+	State       string `json:"state,omitempty"`
+	Description string `json:"description,omitempty"`
+	// DueOn is sent as an RFC3339 timestamp in UTC.
+	DueOn *time.Time `json:"-"`
+}
+
+func (mr MilestoneRequest) MarshalJSON() ([]byte, error) {
+	type milestoneRequest MilestoneRequest
+	wire := struct {
+		milestoneRequest
+		DueOn string `json:"due_on,omitempty"`
+	}{milestoneRequest: milestoneRequest(mr)}
+	if mr.DueOn != nil && !mr.DueOn.IsZero() {
+		wire.DueOn = mr.DueOn.UTC().Format(time.RFC3339)
+	}
+	return json.Marshal(wire)
+}
+
+func (mr *MilestoneRequest) validate() error {
+	if mr == nil {
+		return errors.New("expecting a non-nil milestone request")
+	}
+	switch mr.State {
+	case "", "open", "closed":
+		return nil
+	default:
+		return fmt.Errorf("invalid state %q, expecting either \"open\" or \"closed\"", mr.State)
+	}
+}
+
+var errEmptyMilestoneTitle = errors.New("expecting a non-empty milestone title")
+
+// CreateMilestone creates a milestone in owner/repo. Title is required.
+func (c *Client) CreateMilestone(owner, repo string, mr *MilestoneRequest) (*Milestone, error) {
+	if err := mr.validate(); err != nil {
+		return nil, err
+	}
+	if mr.Title == "" {
+		return nil, errEmptyMilestoneTitle
+	}
+	return c.doMilestoneRequest("POST", owner, repo, 0, mr)
+}
+
+// UpdateMilestone applies the non-zero fields of mr to milestone number of owner/repo.
+func (c *Client) UpdateMilestone(owner, repo string, number uint64, mr *MilestoneRequest) (*Milestone, error) {
+	if err := mr.validate(); err != nil {
+		return nil, err
+	}
+	if number == 0 {
+		return nil, errEmptyMilestoneNumber
+	}
+	return c.doMilestoneRequest("PATCH", owner, repo, number, mr)
+}
+
+// CloseMilestone closes milestone number of owner/repo.
+func (c *Client) CloseMilestone(owner, repo string, number uint64) (*Milestone, error) {
+	return c.UpdateMilestone(owner, repo, number, &MilestoneRequest{State: "closed"})
+}
+
+func (c *Client) doMilestoneRequest(method, owner, repo string, number uint64, mr *MilestoneRequest) (*Milestone, error) {
+	if owner == "" {
+		return nil, errEmptyOwner
+	}
+	if repo == "" {
+		return nil, errEmptyRepo
+	}
+	blob, err := json.Marshal(mr)
+	if err != nil {
+		return nil, err
+	}
+	fullURL := fmt.Sprintf("%s/repos/%s/%s/milestones", baseURL, owner, repo)
+	if number != 0 {
+		fullURL = fmt.Sprintf("%s/%d", fullURL, number)
+	}
+	req, err := http.NewRequest(method, fullURL, bytes.NewReader(blob))
+	if err != nil {
+		return nil, err
+	}
+	blob, _, err = c.doHTTPReq(req)
+	if err != nil {
+		return nil, err
+	}
+	milestone := new(Milestone)
+	if err := json.Unmarshal(blob, milestone); err != nil {
+		return nil, err
+	}
+	return milestone, nil
+}
@@ -0,0 +1,171 @@
+// Copyright 2017 orijtech. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gcla
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+)
+
+const milestoneV1 = `{
+  "url": "https://api.github.com/repos/orijtech/gcla/milestones/1",
+  "id": 1002604,
+  "number": 1,
+  "state": "open",
+  "title": "v1.0",
+  "description": "Tracking milestone for version 1.0",
+  "creator": {"login": "odeke-em", "id": 1},
+  "open_issues": 4,
+  "closed_issues": 8,
+  "created_at": "2017-08-21T18:30:05Z",
+  "due_on": "2017-10-09T23:39:01Z",
+  "closed_at": null
+}`
+
+func TestListMilestones(t *testing.T) {
+	client := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		if g, w := r.URL.Path, "/repos/orijtech/gcla/milestones"; g != w {
+			t.Errorf("path: got %q want %q", g, w)
+		}
+		if g, w := r.URL.Query().Get("state"), "all"; g != w {
+			t.Errorf("state: got %q want %q", g, w)
+		}
+		switch r.URL.Query().Get("page") {
+		case "":
+			w.Header().Set("Link", `<https://api.github.com/repos/orijtech/gcla/milestones?state=all&page=2>; rel="next"`)
+			fmt.Fprintf(w, "[%s]", milestoneV1)
+		case "2":
+			fmt.Fprint(w, `[{"number":2,"state":"closed","title":"v0.9","open_issues":0,"closed_issues":17}]`)
+		}
+	})
+
+	milestones, err := client.ListMilestones("orijtech", "gcla", "all")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g, w := len(milestones), 2; g != w {
+		t.Fatalf("got %d milestones want %d", g, w)
+	}
+
+	tests := [...]struct {
+		number       uint64
+		state        State
+		openIssues   uint64
+		closedIssues uint64
+	}{
+		0: {number: 1, state: StateOpen, openIssues: 4, closedIssues: 8},
+		1: {number: 2, state: StateClosed, openIssues: 0, closedIssues: 17},
+	}
+	for i, tt := range tests {
+		m := milestones[i]
+		if m.Number != tt.number || m.State != tt.state {
+			t.Errorf("#%d: got #%d (%s) want #%d (%s)", i, m.Number, m.State, tt.number, tt.state)
+		}
+		if m.OpenIssues != tt.openIssues || m.ClosedIssues != tt.closedIssues {
+			t.Errorf("#%d: issues: got %d open, %d closed want %d open, %d closed",
+				i, m.OpenIssues, m.ClosedIssues, tt.openIssues, tt.closedIssues)
+		}
+	}
+
+	if _, err := client.ListMilestones("orijtech", "gcla", "pending"); err == nil {
+		t.Errorf("expected an error for an invalid state")
+	}
+}
+
+func TestGetMilestone(t *testing.T) {
+	client := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		if g, w := r.URL.Path, "/repos/orijtech/gcla/milestones/1"; g != w {
+			t.Errorf("path: got %q want %q", g, w)
+		}
+		fmt.Fprint(w, milestoneV1)
+	})
+
+	m, err := client.GetMilestone("orijtech", "gcla", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g, w := m.Title, "v1.0"; g != w {
+		t.Errorf("title: got %q want %q", g, w)
+	}
+	if m.OpenIssues != 4 || m.ClosedIssues != 8 {
+		t.Errorf("issues: got %d open, %d closed", m.OpenIssues, m.ClosedIssues)
+	}
+	if m.DueOn == nil || m.DueOn.Year() != 2017 {
+		t.Errorf("due_on: got %v", m.DueOn)
+	}
+	if m.ClosedAt != nil {
+		t.Errorf("closed_at: got %v want nil", m.ClosedAt)
+	}
+}
+
+func TestCreateMilestone(t *testing.T) {
+	client := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		if g, w := r.Method+" "+r.URL.Path, "POST /repos/orijtech/gcla/milestones"; g != w {
+			t.Errorf("route: got %q want %q", g, w)
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		want := `{"title":"v1.0","description":"Tracking milestone for version 1.0","due_on":"2017-10-09T23:39:01Z"}`
+		if g := string(body); g != want {
+			t.Errorf("body:\ngot:  %s\nwant: %s", g, want)
+		}
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, milestoneV1)
+	})
+
+	// Due dates in other zones are converted to UTC.
+	dueOn := time.Date(2017, time.October, 9, 16, 39, 1, 0, time.FixedZone("PDT", -7*3600))
+	m, err := client.CreateMilestone("orijtech", "gcla", &MilestoneRequest{
+		Title:       "v1.0",
+		Description: "Tracking milestone for version 1.0",
+		DueOn:       &dueOn,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.DueOn == nil || !m.DueOn.Equal(dueOn) {
+		t.Errorf("due_on: got %v want %v", m.DueOn, dueOn)
+	}
+
+	if _, err := client.CreateMilestone("orijtech", "gcla", &MilestoneRequest{}); err == nil {
+		t.Errorf("expected an error for a missing title")
+	}
+}
+
+func TestCloseMilestone(t *testing.T) {
+	client := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		if g, w := r.Method+" "+r.URL.Path, "PATCH /repos/orijtech/gcla/milestones/1"; g != w {
+			t.Errorf("route: got %q want %q", g, w)
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		if g, w := string(body), `{"state":"closed"}`; g != w {
+			t.Errorf("body: got %s want %s", g, w)
+		}
+		fmt.Fprint(w, `{"number":1,"state":"closed","title":"v1.0","open_issues":0,"closed_issues":12,"closed_at":"2017-10-10T09:00:00Z"}`)
+	})
+
+	m, err := client.CloseMilestone("orijtech", "gcla", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.State != StateClosed {
+		t.Errorf("state: got %q want %q", m.State, StateClosed)
+	}
+	if m.ClosedAt == nil {
+		t.Errorf("expected closed_at to be set")
+	}
+}
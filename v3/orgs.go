@@ -0,0 +1,81 @@
+// Copyright 2017 orijtech. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gcla
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+)
+
+const (
+	RoleAll    = "all"
+	RoleAdmin  = "admin"
+	RoleMember = "member"
+)
+
+var (
+	errEmptyOrg      = errors.New("expecting a non-empty organization")
+	errEmptyUsername = errors.New("expecting a non-empty username")
+)
+
+// ListOrgMembers returns the members of org, following pagination.
+// role filters the members and is one of RoleAll, RoleAdmin or RoleMember.
+// An empty role is equivalent to RoleAll.
+func (c *Client) ListOrgMembers(org, role string) ([]*User, error) {
+	if org == "" {
+		return nil, errEmptyOrg
+	}
+	switch role {
+	case "", RoleAll, RoleAdmin, RoleMember:
+	default:
+		return nil, fmt.Errorf("invalid role %q, expecting one of %q, %q or %q", role, RoleAll, RoleAdmin, RoleMember)
+	}
+
+	qv := make(url.Values)
+	if role != "" {
+		qv.Set("role", role)
+	}
+	fullURL := fmt.Sprintf("%s/orgs/%s/members", baseURL, org)
+	if len(qv) > 0 {
+		fullURL += "?" + qv.Encode()
+	}
+
+	var members []*User
+	err := c.getAllPages(fullURL, func(blob []byte) error {
+		var page []*User
+		if err := json.Unmarshal(blob, &page); err != nil {
+			return err
+		}
+		members = append(members, page...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+// CheckOrgMembership reports whether username is a member of org.
+func (c *Client) CheckOrgMembership(org, username string) (bool, error) {
+	if org == "" {
+		return false, errEmptyOrg
+	}
+	if username == "" {
+		return false, errEmptyUsername
+	}
+	return c.boolRequest("GET", fmt.Sprintf("/orgs/%s/members/%s", org, username))
+}
@@ -0,0 +1,98 @@
+// Copyright 2017 orijtech. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gcla
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestListOrgMembers(t *testing.T) {
+	client := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		if g, w := r.URL.Path, "/orgs/orijtech/members"; g != w {
+			t.Errorf("path: got %q want %q", g, w)
+		}
+		if g, w := r.URL.Query().Get("role"), "admin"; g != w {
+			t.Errorf("role: got %q want %q", g, w)
+		}
+		switch r.URL.Query().Get("page") {
+		case "":
+			w.Header().Set("Link", `<https://api.github.com/orgs/orijtech/members?role=admin&page=2>; rel="next", <https://api.github.com/orgs/orijtech/members?role=admin&page=2>; rel="last"`)
+			fmt.Fprint(w, `[{"login":"odeke-em","id":1},{"login":"jadekler","id":2}]`)
+		case "2":
+			fmt.Fprint(w, `[{"login":"rakyll","id":3}]`)
+		default:
+			http.Error(w, "unexpected page", http.StatusBadRequest)
+		}
+	})
+
+	members, err := client.ListOrgMembers("orijtech", RoleAdmin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var logins []string
+	for _, member := range members {
+		logins = append(logins, member.Username)
+	}
+	if g, w := fmt.Sprint(logins), "[odeke-em jadekler rakyll]"; g != w {
+		t.Errorf("members: got %s want %s", g, w)
+	}
+
+	if _, err := client.ListOrgMembers("orijtech", "owner"); err == nil {
+		t.Errorf("expected an error for an invalid role")
+	}
+}
+
+func TestCheckOrgMembership(t *testing.T) {
+	client := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/orgs/orijtech/members/odeke-em":
+			w.WriteHeader(http.StatusNoContent)
+		case "/orgs/orijtech/members/stranger":
+			http.Error(w, `{"message":"Not Found"}`, http.StatusNotFound)
+		default:
+			http.Error(w, `{"message":"Server Error"}`, http.StatusInternalServerError)
+		}
+	})
+
+	tests := [...]struct {
+		username   string
+		wantMember bool
+		wantErr    bool
+	}{
+		0: {username: "odeke-em", wantMember: true},
+		1: {username: "stranger", wantMember: false},
+		2: {username: "broken", wantErr: true},
+		3: {username: "", wantErr: true},
+	}
+
+	for i, tt := range tests {
+		isMember, err := client.CheckOrgMembership("orijtech", tt.username)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("#%d: expected a non-nil error", i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("#%d: unexpected error: %v", i, err)
+			continue
+		}
+		if isMember != tt.wantMember {
+			t.Errorf("#%d: got %v want %v", i, isMember, tt.wantMember)
+		}
+	}
+}
@@ -0,0 +1,299 @@
+// Copyright 2017 orijtech. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gcla
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+func (pr *PullRequest) UnmarshalJSON(b []byte) error {
+	type pullRequest PullRequest
+	if err := json.Unmarshal(b, (*pullRequest)(pr)); err != nil {
+		return err
+	}
+	if pr.Assignee == nil && len(pr.Assignees) > 0 {
+		pr.Assignee = pr.Assignees[0]
+	}
+	return nil
+}
+
+// IsDraft reports whether pr is a draft, which
+// GitHub doesn't allow merging until it is ready for review.
+func (pr *PullRequest) IsDraft() bool {
+	return pr != nil && pr.Draft
+}
+
+// PullRequestListOptions filters and orders the results of ListPullRequests.
+// Zero values are omitted, deferring to GitHub's defaults.
+type PullRequestListOptions struct {
+	// State is one of "open", "closed" or "all".
+	State string
+	// Head filters by head user or organization
+	// and branch name in the form "user:ref-name".
+	Head string
+	// Base filters by the base branch name.
+	Base string
+	// Sort is one of "created", "updated",
+	// "popularity" or "long-running".
+	Sort string
+	// Direction is either "asc" or "desc".
+	Direction string
+}
+
+func (plo *PullRequestListOptions) values() (url.Values, error) {
+	qv := make(url.Values)
+	if plo == nil {
+		return qv, nil
+	}
+	switch plo.State {
+	case "", "open", "closed", "all":
+	default:
+		return nil, fmt.Errorf("invalid state %q", plo.State)
+	}
+	switch plo.Sort {
+	case "", "created", "updated", "popularity", "long-running":
+	default:
+		return nil, fmt.Errorf("invalid sort %q", plo.Sort)
+	}
+	if err := validateDirection(plo.Direction); err != nil {
+		return nil, err
+	}
+	for key, value := range map[string]string{
+		"state":     plo.State,
+		"head":      plo.Head,
+		"base":      plo.Base,
+		"sort":      plo.Sort,
+		"direction": plo.Direction,
+	} {
+		if value != "" {
+			qv.Set(key, value)
+		}
+	}
+	return qv, nil
+}
+
+func validateDirection(direction string) error {
+	switch direction {
+	case "", "asc", "desc":
+		return nil
+	default:
+		return fmt.Errorf("invalid direction %q, expecting either \"asc\" or \"desc\"", direction)
+	}
+}
+
+// ListPullRequests returns the pull requests of owner/repo
+// that match opts, following pagination.
+func (c *Client) ListPullRequests(owner, repo string, opts *PullRequestListOptions) ([]*PullRequest, error) {
+	if owner == "" {
+		return nil, errEmptyOwner
+	}
+	if repo == "" {
+		return nil, errEmptyRepo
+	}
+	qv, err := opts.values()
+	if err != nil {
+		return nil, err
+	}
+	fullURL := fmt.Sprintf("%s/repos/%s/%s/pulls", baseURL, owner, repo)
+	if len(qv) > 0 {
+		fullURL += "?" + qv.Encode()
+	}
+
+	var prs []*PullRequest
+	err = c.getAllPages(fullURL, func(blob []byte) error {
+		var page []*PullRequest
+		if err := json.Unmarshal(blob, &page); err != nil {
+			return err
+		}
+		prs = append(prs, page...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return prs, nil
+}
+
+// IssueRef identifies an issue, possibly in another repository.
+type IssueRef struct {
+	// Owner and Repo are empty for references within the same repository.
+	Owner  string
+	Repo   string
+	Number uint64
+}
+
+var (
+	closingRefRegexp = regexp.MustCompile(`(?i)\b(?:close[sd]?|fix(?:e[sd])?|resolve[sd]?):?\s+(?:([\w.-]+)/([\w.-]+))?#(\d+)\b`)
+
+	fencedCodeRegexp = regexp.MustCompile("(?s)```.*?(```|$)|~~~.*?(~~~|$)")
+	inlineCodeRegexp = regexp.MustCompile("`[^`\n]*`")
+)
+
+// ClosingIssueRefs parses the references in the pull request's Body that
+// GitHub closes once the pull request is merged, such as "Fixes #123" or
+// "Closes orijtech/otils#4", de-duplicated and in order of appearance.
+// The closing keywords are close, closes, closed, fix, fixes, fixed,
+// resolve, resolves and resolved, in any case. References inside code
+// blocks and code spans are ignored.
+func (pr *PullRequest) ClosingIssueRefs() []*IssueRef {
+	if pr == nil || pr.Body == "" {
+		return nil
+	}
+	body := fencedCodeRegexp.ReplaceAllString(pr.Body, "")
+	body = inlineCodeRegexp.ReplaceAllString(body, "")
+
+	var refs []*IssueRef
+	seen := make(map[string]bool)
+	for _, match := range closingRefRegexp.FindAllStringSubmatch(body, -1) {
+		number, err := strconv.ParseUint(match[3], 10, 64)
+		if err != nil || number == 0 {
+			continue
+		}
+		ref := &IssueRef{Owner: match[1], Repo: match[2], Number: number}
+		key := strings.ToLower(fmt.Sprintf("%s/%s#%d", ref.Owner, ref.Repo, ref.Number))
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		refs = append(refs, ref)
+	}
+	return refs
+}
+
+// ClosingIssues returns the numbers of the issues in the pull request's
+// own repository that merging it closes, as parsed by ClosingIssueRefs.
+// Cross-repository references are only included if they name the base
+// repository of the pull request.
+func (pr *PullRequest) ClosingIssues() []uint64 {
+	var baseRepo string
+	if pr != nil && pr.Base != nil && pr.Base.Repo != nil {
+		baseRepo = pr.Base.Repo.FullName
+	}
+
+	var numbers []uint64
+	seen := make(map[uint64]bool)
+	for _, ref := range pr.ClosingIssueRefs() {
+		if ref.Owner != "" && !strings.EqualFold(ref.Owner+"/"+ref.Repo, baseRepo) {
+			continue
+		}
+		if !seen[ref.Number] {
+			seen[ref.Number] = true
+			numbers = append(numbers, ref.Number)
+		}
+	}
+	return numbers
+}
+
+const pullRequestIDQuery = `query($owner: String!, $name: String!, $number: Int!) {
+  repository(owner: $owner, name: $name) {
+    pullRequest(number: $number) { id }
+  }
+}`
+
+// graphQLPullRequestFields are the fields of a pull request
+// that the draft mutations retrieve, see graphQLPullRequest.
+const graphQLPullRequestFields = `id number title state isDraft url`
+
+const (
+	markPullRequestReadyMutation = `mutation($id: ID!) {
+  markPullRequestReadyForReview(input: {pullRequestId: $id}) {
+    pullRequest { ` + graphQLPullRequestFields + ` }
+  }
+}`
+
+	convertPullRequestToDraftMutation = `mutation($id: ID!) {
+  convertPullRequestToDraft(input: {pullRequestId: $id}) {
+    pullRequest { ` + graphQLPullRequestFields + ` }
+  }
+}`
+)
+
+type graphQLPullRequest struct {
+	ID      string `json:"id"`
+	Number  uint64 `json:"number"`
+	Title   string `json:"title"`
+	State   string `json:"state"`
+	IsDraft bool   `json:"isDraft"`
+	URL     string `json:"url"`
+}
+
+func (gpr *graphQLPullRequest) toPullRequest() *PullRequest {
+	return &PullRequest{
+		NodeID:  gpr.ID,
+		Number:  gpr.Number,
+		Title:   gpr.Title,
+		State:   State(strings.ToLower(gpr.State)),
+		Draft:   gpr.IsDraft,
+		HTMLURL: gpr.URL,
+	}
+}
+
+// MarkPullRequestReady marks the draft pull request number as ready for
+// review. The REST API doesn't support it so it uses GraphQL, which only
+// returns the pull request's NodeID, Number, Title, State, Draft and HTMLURL.
+func (c *Client) MarkPullRequestReady(owner, repo string, number uint64) (*PullRequest, error) {
+	return c.setPullRequestDraft(owner, repo, number, markPullRequestReadyMutation, "markPullRequestReadyForReview")
+}
+
+// ConvertPullRequestToDraft converts the pull request number to a draft.
+// Like MarkPullRequestReady, it uses GraphQL.
+func (c *Client) ConvertPullRequestToDraft(owner, repo string, number uint64) (*PullRequest, error) {
+	return c.setPullRequestDraft(owner, repo, number, convertPullRequestToDraftMutation, "convertPullRequestToDraft")
+}
+
+func (c *Client) setPullRequestDraft(owner, repo string, number uint64, mutation, field string) (*PullRequest, error) {
+	if owner == "" {
+		return nil, errEmptyOwner
+	}
+	if repo == "" {
+		return nil, errEmptyRepo
+	}
+	if number == 0 {
+		return nil, errEmptyPullNumber
+	}
+
+	// GraphQL mutations identify pull requests by their node ID.
+	var lookup struct {
+		Repository *struct {
+			PullRequest *struct {
+				ID string `json:"id"`
+			} `json:"pullRequest"`
+		} `json:"repository"`
+	}
+	vars := map[string]interface{}{"owner": owner, "name": repo, "number": number}
+	if err := c.GraphQL(pullRequestIDQuery, vars, &lookup); err != nil {
+		return nil, err
+	}
+	if lookup.Repository == nil || lookup.Repository.PullRequest == nil || lookup.Repository.PullRequest.ID == "" {
+		return nil, fmt.Errorf("pull request %s/%s#%d not found", owner, repo, number)
+	}
+
+	var result map[string]*struct {
+		PullRequest *graphQLPullRequest `json:"pullRequest"`
+	}
+	vars = map[string]interface{}{"id": lookup.Repository.PullRequest.ID}
+	if err := c.GraphQL(mutation, vars, &result); err != nil {
+		return nil, err
+	}
+	if payload := result[field]; payload != nil && payload.PullRequest != nil {
+		return payload.PullRequest.toPullRequest(), nil
+	}
+	return nil, fmt.Errorf("%s: no pull request in the response", field)
+}
@@ -0,0 +1,355 @@
+// Copyright 2017 orijtech. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gcla
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestListPullRequests(t *testing.T) {
+	client := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		if g, w := r.URL.Path, "/repos/orijtech/gcla/pulls"; g != w {
+			t.Errorf("path: got %q want %q", g, w)
+		}
+		switch r.URL.Query().Get("page") {
+		case "":
+			if g, w := r.URL.RawQuery, "direction=desc&sort=updated&state=open"; g != w {
+				t.Errorf("query: got %q want %q", g, w)
+			}
+			w.Header().Set("Link", `<https://api.github.com/repos/orijtech/gcla/pulls?direction=desc&sort=updated&state=open&page=2>; rel="next"`)
+			fmt.Fprint(w, `[{"number":12,"title":"Add ListPullRequests","state":"open"}]`)
+		case "2":
+			fmt.Fprint(w, `[{"number":7,"title":"Add examples","state":"open"}]`)
+		}
+	})
+
+	prs, err := client.ListPullRequests("orijtech", "gcla", &PullRequestListOptions{
+		State:     "open",
+		Sort:      "updated",
+		Direction: "desc",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g, w := len(prs), 2; g != w {
+		t.Fatalf("got %d pull requests want %d", g, w)
+	}
+	if prs[0].Number != 12 || prs[1].Number != 7 {
+		t.Errorf("unexpected order: #%d, #%d", prs[0].Number, prs[1].Number)
+	}
+	if prs[0].State != StateOpen {
+		t.Errorf("state: got %q want %q", prs[0].State, StateOpen)
+	}
+}
+
+func TestListPullRequestsInvalidOptions(t *testing.T) {
+	client := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request to %q", r.URL)
+	})
+	invalid := []*PullRequestListOptions{
+		{State: "merged"},
+		{Sort: "comments"},
+		{Direction: "up"},
+	}
+	for i, opts := range invalid {
+		if _, err := client.ListPullRequests("orijtech", "gcla", opts); err == nil {
+			t.Errorf("#%d: expected an error for %+v", i, opts)
+		}
+	}
+}
+
+func TestPullRequestAssignees(t *testing.T) {
+	tests := [...]struct {
+		payload string
+	}{
+		0: {
+			payload: `{
+  "number": 12,
+  "head": {"ref": "assignees", "sha": "0d1a26e67d8f5eaf1f6ba5c57fc3c7d91ac0fd1c"},
+  "base": {"ref": "master", "sha": "9049f1265b7d61be4a8904a9a27120d2064dab3b"},
+  "assignee": {"login": "odeke-em", "id": 1},
+  "assignees": [{"login": "odeke-em", "id": 1}, {"login": "jadekler", "id": 2}]
+}`,
+		},
+		// Without "assignee", the first of "assignees" stands in for it.
+		1: {
+			payload: `{
+  "number": 12,
+  "head": {"ref": "assignees", "sha": "0d1a26e67d8f5eaf1f6ba5c57fc3c7d91ac0fd1c"},
+  "base": {"ref": "master", "sha": "9049f1265b7d61be4a8904a9a27120d2064dab3b"},
+  "assignees": [{"login": "odeke-em", "id": 1}, {"login": "jadekler", "id": 2}]
+}`,
+		},
+	}
+
+	for i, tt := range tests {
+		pr := new(PullRequest)
+		if err := json.Unmarshal([]byte(tt.payload), pr); err != nil {
+			t.Errorf("#%d: %v", i, err)
+			continue
+		}
+		if g, w := len(pr.Assignees), 2; g != w {
+			t.Errorf("#%d: got %d assignees want %d", i, g, w)
+			continue
+		}
+		if g, w := pr.Assignees[1].Username, "jadekler"; g != w {
+			t.Errorf("#%d: second assignee: got %q want %q", i, g, w)
+		}
+		if pr.Assignee == nil || pr.Assignee.Username != "odeke-em" {
+			t.Errorf("#%d: assignee: got %#v want odeke-em", i, pr.Assignee)
+		}
+	}
+}
+
+func TestPullRequestClosingIssues(t *testing.T) {
+	base := &Head{Repo: &Repository{FullName: "orijtech/gcla"}}
+
+	tests := [...]struct {
+		body string
+		want []uint64
+	}{
+		0: {body: "", want: nil},
+		1: {body: "Fixes #12", want: []uint64{12}},
+		2: {body: "closes #1, resolves #2 and FIXED #3", want: []uint64{1, 2, 3}},
+		3: {body: "Fixes #4\n\nAlso fixes #4 and fixes #5", want: []uint64{4, 5}},
+		// Only the base repository's cross-repository references count.
+		4: {body: "Fixes orijtech/gcla#6, fixes orijtech/otils#7", want: []uint64{6}},
+		5: {body: "Closes: #8", want: []uint64{8}},
+		// False positives.
+		6:  {body: "See #9, this is a prefix #10 and unfixes #11", want: nil},
+		7:  {body: "```\nfixes #12\n```\nCloses #13", want: []uint64{13}},
+		8:  {body: "Run `git commit -m 'fixes #14'` then resolve #15", want: []uint64{15}},
+		9:  {body: "Fixes #16abc and fixes #0", want: nil},
+		10: {body: "~~~\nfixes #17\n~~~", want: nil},
+	}
+
+	for i, tt := range tests {
+		pr := &PullRequest{Body: tt.body, Base: base}
+		got := pr.ClosingIssues()
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("#%d: got %v want %v", i, got, tt.want)
+		}
+	}
+}
+
+func TestPullRequestClosingIssueRefs(t *testing.T) {
+	pr := &PullRequest{Body: "Fixes #1 and resolves orijtech/otils#2; also fixes Orijtech/Otils#2"}
+	got := pr.ClosingIssueRefs()
+	want := []*IssueRef{
+		{Number: 1},
+		{Owner: "orijtech", Repo: "otils", Number: 2},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v want %+v", got, want)
+	}
+}
+
+func TestLinksDecoding(t *testing.T) {
+	const payload = `{
+  "number": 12,
+  "_links": {
+    "self": {"href": "https://api.github.com/repos/orijtech/gcla/pulls/12"},
+    "html": {"href": "https://github.com/orijtech/gcla/pull/12"},
+    "issue": {"href": "https://api.github.com/repos/orijtech/gcla/issues/12"},
+    "comments": {"href": "https://api.github.com/repos/orijtech/gcla/issues/12/comments"},
+    "review_comments": {"href": "https://api.github.com/repos/orijtech/gcla/pulls/12/comments"},
+    "review_comment": {"href": "https://api.github.com/repos/orijtech/gcla/pulls/comments{/number}"},
+    "commits": {"href": "https://api.github.com/repos/orijtech/gcla/pulls/12/commits"},
+    "statuses": {"href": "https://api.github.com/repos/orijtech/gcla/statuses/6dcb09b5b57875f334f61aebed695e2e4193db5e"}
+  }
+}`
+	pr := new(PullRequest)
+	if err := json.Unmarshal([]byte(payload), pr); err != nil {
+		t.Fatal(err)
+	}
+	links := pr.Links
+	if links == nil {
+		t.Fatal("expected non-nil Links")
+	}
+	tests := [...]struct {
+		link *Link
+		want string
+	}{
+		0: {links.Self, "https://api.github.com/repos/orijtech/gcla/pulls/12"},
+		1: {links.HTML, "https://github.com/orijtech/gcla/pull/12"},
+		2: {links.Issue, "https://api.github.com/repos/orijtech/gcla/issues/12"},
+		3: {links.Comments, "https://api.github.com/repos/orijtech/gcla/issues/12/comments"},
+		4: {links.ReviewComments, "https://api.github.com/repos/orijtech/gcla/pulls/12/comments"},
+		5: {links.ReviewComment, "https://api.github.com/repos/orijtech/gcla/pulls/comments{/number}"},
+		6: {links.Commits, "https://api.github.com/repos/orijtech/gcla/pulls/12/commits"},
+		7: {links.Statuses, "https://api.github.com/repos/orijtech/gcla/statuses/6dcb09b5b57875f334f61aebed695e2e4193db5e"},
+	}
+	for i, tt := range tests {
+		if tt.link == nil {
+			t.Errorf("#%d: expected a non-nil link", i)
+			continue
+		}
+		if tt.link.Href != tt.want {
+			t.Errorf("#%d: got %q want %q", i, tt.link.Href, tt.want)
+		}
+	}
+
+	comment := new(Comment)
+	blob := `{"id": 10, "_links": {"pull_request": {"href": "https://api.github.com/repos/orijtech/gcla/pulls/12"}}}`
+	if err := json.Unmarshal([]byte(blob), comment); err != nil {
+		t.Fatal(err)
+	}
+	if comment.Links == nil || comment.Links.PullRequest == nil {
+		t.Fatalf("expected comment.Links.PullRequest to be set, got %#v", comment.Links)
+	}
+	if g, w := comment.Links.PullRequest.Href, "https://api.github.com/repos/orijtech/gcla/pulls/12"; g != w {
+		t.Errorf("comment pull_request link: got %q want %q", g, w)
+	}
+}
+
+func TestPullRequestDraft(t *testing.T) {
+	tests := [...]struct {
+		payload string
+		want    bool
+	}{
+		0: {payload: `{"number": 12, "draft": true}`, want: true},
+		1: {payload: `{"number": 12, "draft": false}`, want: false},
+		2: {payload: `{"number": 12}`, want: false},
+	}
+
+	for i, tt := range tests {
+		pr := new(PullRequest)
+		if err := json.Unmarshal([]byte(tt.payload), pr); err != nil {
+			t.Errorf("#%d: %v", i, err)
+			continue
+		}
+		if g, w := pr.IsDraft(), tt.want; g != w {
+			t.Errorf("#%d: IsDraft: got %v want %v", i, g, w)
+		}
+	}
+
+	var nilPR *PullRequest
+	if nilPR.IsDraft() {
+		t.Error("a nil pull request can't be a draft")
+	}
+}
+
+func TestPullRequestDraftMutations(t *testing.T) {
+	tests := [...]struct {
+		call      func(*Client) (*PullRequest, error)
+		mutation  string
+		wantDraft bool
+	}{
+		0: {
+			call: func(c *Client) (*PullRequest, error) {
+				return c.MarkPullRequestReady("orijtech", "gcla", 12)
+			},
+			mutation: "markPullRequestReadyForReview",
+		},
+		1: {
+			call: func(c *Client) (*PullRequest, error) {
+				return c.ConvertPullRequestToDraft("orijtech", "gcla", 12)
+			},
+			mutation:  "convertPullRequestToDraft",
+			wantDraft: true,
+		},
+	}
+
+	for i, tt := range tests {
+		var mutated bool
+		client := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+			if g, w := r.URL.Path, "/graphql"; g != w {
+				t.Errorf("#%d: path: got %q want %q", i, g, w)
+			}
+			var req graphQLRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				t.Errorf("#%d: %v", i, err)
+				return
+			}
+			if id, ok := req.Variables["id"]; ok {
+				if g, w := id, "PR_kwDOAhjEUc4"; g != w {
+					t.Errorf("#%d: id: got %v want %q", i, g, w)
+				}
+				if !strings.Contains(req.Query, tt.mutation) {
+					t.Errorf("#%d: query %q doesn't invoke %q", i, req.Query, tt.mutation)
+				}
+				mutated = true
+				fmt.Fprintf(w, `{"data": {%q: {"pullRequest": {"id": "PR_kwDOAhjEUc4", "number": 12, "title": "Add drafts", "state": "OPEN", "isDraft": %t, "url": "https://github.com/orijtech/gcla/pull/12"}}}}`, tt.mutation, tt.wantDraft)
+				return
+			}
+			if g, w := req.Variables["owner"], "orijtech"; g != w {
+				t.Errorf("#%d: owner: got %v want %q", i, g, w)
+			}
+			if g, w := req.Variables["number"], float64(12); g != w {
+				t.Errorf("#%d: number: got %v want %v", i, g, w)
+			}
+			fmt.Fprint(w, `{"data": {"repository": {"pullRequest": {"id": "PR_kwDOAhjEUc4"}}}}`)
+		})
+
+		pr, err := tt.call(client)
+		if err != nil {
+			t.Errorf("#%d: %v", i, err)
+			continue
+		}
+		if !mutated {
+			t.Errorf("#%d: the mutation wasn't sent", i)
+		}
+		if g, w := pr.IsDraft(), tt.wantDraft; g != w {
+			t.Errorf("#%d: draft: got %v want %v", i, g, w)
+		}
+		if pr.Number != 12 || pr.State != StateOpen || pr.NodeID != "PR_kwDOAhjEUc4" {
+			t.Errorf("#%d: unexpected pull request %#v", i, pr)
+		}
+	}
+}
+
+func TestPullRequestDraftMutationNotFound(t *testing.T) {
+	client := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"data": {"repository": {"pullRequest": null}}, "errors": [{"type": "NOT_FOUND", "path": ["repository", "pullRequest"], "message": "Could not resolve to a PullRequest with the number of 99."}]}`)
+	})
+	if _, err := client.MarkPullRequestReady("orijtech", "gcla", 99); err == nil {
+		t.Fatal("expected an error")
+	} else if _, ok := err.(*GraphQLError); !ok {
+		t.Errorf("got %T want *GraphQLError", err)
+	}
+}
+
+// PullRequest.Head used to be tagged "base" like Base, and since
+// encoding/json ignores fields whose names clash, neither was decoded.
+func TestPullRequestHeadAndBase(t *testing.T) {
+	const payload = `{
+  "number": 12,
+  "head": {"ref": "feature", "sha": "0d1a26e67d8f5eaf1f6ba5c57fc3c7d91ac0fd1c"},
+  "base": {"ref": "master", "sha": "9049f1265b7d61be4a8904a9a27120d2064dab3b"}
+}`
+	pr := new(PullRequest)
+	if err := json.Unmarshal([]byte(payload), pr); err != nil {
+		t.Fatal(err)
+	}
+	if pr.Head == nil || pr.Head.Ref != "feature" || pr.Head.SHA != "0d1a26e67d8f5eaf1f6ba5c57fc3c7d91ac0fd1c" {
+		t.Errorf("head: got %#v", pr.Head)
+	}
+	if pr.Base == nil || pr.Base.Ref != "master" || pr.Base.SHA != "9049f1265b7d61be4a8904a9a27120d2064dab3b" {
+		t.Errorf("base: got %#v", pr.Base)
+	}
+
+	blob, err := json.Marshal(&PullRequest{Head: &Head{Ref: "feature"}, Base: &Head{Ref: "master"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(blob), `"head":{"ref":"feature"`) || !strings.Contains(string(blob), `"base":{"ref":"master"`) {
+		t.Errorf("marshaled: got %s", blob)
+	}
+}
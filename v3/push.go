@@ -0,0 +1,64 @@
+// Copyright 2017 orijtech. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gcla
+
+import (
+	"strings"
+)
+
+// IsDeletion reports whether the push deleted its branch or tag.
+func (p *PushEvent) IsDeletion() bool {
+	return isZeroSHA(p.headSHA())
+}
+
+// IsCreation reports whether the push created its branch or tag.
+func (p *PushEvent) IsCreation() bool {
+	return isZeroSHA(p.Before)
+}
+
+// headSHA returns the SHA of the ref after the push, regardless
+// of whether the event came from a webhook or the Events API.
+func (p *PushEvent) headSHA() string {
+	if p.After != "" {
+		return p.After
+	}
+	return p.Head
+}
+
+// isZeroSHA reports whether sha is the all zeros SHA that GitHub
+// reports for the side of a push where the ref doesn't exist.
+func isZeroSHA(sha string) bool {
+	return sha != "" && strings.Trim(sha, "0") == ""
+}
+
+// CompareURL returns the URL of the page that compares the ref before
+// and after the push. For a push that created the ref, there is nothing
+// to compare with so it returns the URL of the commits up to the new head,
+// and for one that deleted the ref, the URL of the commit it pointed to.
+// It returns "" if the repository's HTMLURL is unknown.
+func (p *PushEvent) CompareURL() string {
+	if p.Repository == nil || p.Repository.HTMLURL == "" {
+		return ""
+	}
+	repoURL := strings.TrimSuffix(p.Repository.HTMLURL, "/")
+	switch head := p.headSHA(); {
+	case p.IsDeletion():
+		return repoURL + "/commit/" + p.Before
+	case p.Before == "" || p.IsCreation():
+		return repoURL + "/commits/" + head
+	default:
+		return repoURL + "/compare/" + p.Before + "..." + head
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright 2017 orijtech. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gcla
+
+import (
+	"testing"
+)
+
+func TestPushEventCreationAndDeletion(t *testing.T) {
+	const zero = "0000000000000000000000000000000000000000"
+	const sha1 = "9049f1265b7d61be4a8904a9a27120d2064dab3b"
+	const sha2 = "0d1a26e67d8f5eaf1f6ba5c57fc3c7d91ac0fd1c"
+
+	tests := [...]struct {
+		payload      string
+		wantCreation bool
+		wantDeletion bool
+	}{
+		0: {payload: `{"ref":"refs/heads/master","before":"` + sha1 + `","after":"` + sha2 + `"}`},
+		1: {payload: `{"ref":"refs/heads/feature","before":"` + zero + `","after":"` + sha2 + `"}`, wantCreation: true},
+		2: {payload: `{"ref":"refs/tags/v1.0.0","before":"` + sha1 + `","after":"` + zero + `"}`, wantDeletion: true},
+		// The Events API reports the new SHA as "head".
+		3: {payload: `{"ref":"refs/heads/feature","before":"` + sha1 + `","head":"` + zero + `"}`, wantDeletion: true},
+		4: {payload: `{"ref":"refs/heads/master","before":"` + sha1 + `","head":"` + sha2 + `"}`},
+	}
+
+	for i, tt := range tests {
+		got, err := ParseWebhook(EventPush, []byte(tt.payload))
+		if err != nil {
+			t.Errorf("#%d: %v", i, err)
+			continue
+		}
+		pe := got.(*PushEvent)
+		if g, w := pe.IsCreation(), tt.wantCreation; g != w {
+			t.Errorf("#%d: IsCreation: got %v want %v", i, g, w)
+		}
+		if g, w := pe.IsDeletion(), tt.wantDeletion; g != w {
+			t.Errorf("#%d: IsDeletion: got %v want %v", i, g, w)
+		}
+	}
+}
+
+func TestPushEventCompareURL(t *testing.T) {
+	const zero = "0000000000000000000000000000000000000000"
+	const sha1 = "9049f1265b7d61be4a8904a9a27120d2064dab3b"
+	const sha2 = "0d1a26e67d8f5eaf1f6ba5c57fc3c7d91ac0fd1c"
+	repo := &Repository{HTMLURL: "https://github.com/orijtech/gcla"}
+
+	tests := [...]struct {
+		push *PushEvent
+		want string
+	}{
+		0: {
+			push: &PushEvent{Before: sha1, After: sha2, Repository: repo},
+			want: "https://github.com/orijtech/gcla/compare/" + sha1 + "..." + sha2,
+		},
+		// The Events API reports the new SHA as "head".
+		1: {
+			push: &PushEvent{Before: sha1, Head: sha2, Repository: repo},
+			want: "https://github.com/orijtech/gcla/compare/" + sha1 + "..." + sha2,
+		},
+		// Creation.
+		2: {
+			push: &PushEvent{Before: zero, After: sha2, Repository: repo},
+			want: "https://github.com/orijtech/gcla/commits/" + sha2,
+		},
+		3: {
+			push: &PushEvent{After: sha2, Repository: repo},
+			want: "https://github.com/orijtech/gcla/commits/" + sha2,
+		},
+		// Deletion.
+		4: {
+			push: &PushEvent{Before: sha1, After: zero, Repository: repo},
+			want: "https://github.com/orijtech/gcla/commit/" + sha1,
+		},
+		5: {push: &PushEvent{Before: sha1, After: sha2}, want: ""},
+	}
+
+	for i, tt := range tests {
+		if g, w := tt.push.CompareURL(), tt.want; g != w {
+			t.Errorf("#%d: got %q want %q", i, g, w)
+		}
+	}
+}
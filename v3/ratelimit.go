@@ -0,0 +1,163 @@
+// Copyright 2017 orijtech. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gcla
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RateLimit is the state of the rate limit that
+// GitHub reports in the headers of every response.
+type RateLimit struct {
+	Limit     int
+	Remaining int
+	Used      int
+	// Reset is when Remaining is reset to Limit.
+	Reset time.Time
+	// Resource is the rate limit's category e.g. "core" or "search".
+	Resource string
+}
+
+// parseRateLimit returns the rate limit from hdr, reporting
+// false if hdr doesn't have the "X-RateLimit-*" headers.
+func parseRateLimit(hdr http.Header) (RateLimit, bool) {
+	limit, err := strconv.Atoi(hdr.Get("X-RateLimit-Limit"))
+	if err != nil {
+		return RateLimit{}, false
+	}
+	remaining, err := strconv.Atoi(hdr.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		return RateLimit{}, false
+	}
+	rl := RateLimit{
+		Limit:     limit,
+		Remaining: remaining,
+		Resource:  hdr.Get("X-RateLimit-Resource"),
+	}
+	rl.Used, _ = strconv.Atoi(hdr.Get("X-RateLimit-Used"))
+	if secs, err := strconv.ParseInt(hdr.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+		rl.Reset = time.Unix(secs, 0)
+	}
+	return rl, true
+}
+
+// SleepUntilReset returns how long until the rate limit is reset,
+// or 0 if the reset time has already passed. It uses the wall time,
+// not the clock of a Client set with WithClock.
+func (rl RateLimit) SleepUntilReset() time.Duration {
+	return rl.sleepUntilReset(time.Now())
+}
+
+func (rl RateLimit) sleepUntilReset(now time.Time) time.Duration {
+	d := rl.Reset.Sub(now)
+	if d < 0 {
+		return 0
+	}
+	return d
+}
+
+// RateLimitError is returned for requests that were rejected
+// because the rate limit was exceeded. Its message reports the
+// time until the reset with SleepUntilReset, using the wall time.
+type RateLimitError struct {
+	RateLimit RateLimit
+	Err       *APIError
+}
+
+func (rle *RateLimitError) Error() string {
+	return fmt.Sprintf("rate limit of %d exceeded, resets in %s: %v", rle.RateLimit.Limit, rle.RateLimit.SleepUntilReset().Round(time.Second), rle.Err)
+}
+
+// LastRateLimit returns the rate limit reported by the latest response,
+// reporting false if no response has reported it yet.
+func (c *Client) LastRateLimit() (RateLimit, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.rateLimit, c.hasRateLimit
+}
+
+// isRateLimited reports whether err is a response to a request that was
+// rejected by either the primary rate limit or a secondary rate limit.
+// Secondary rate limits leave requests remaining, so they can only be
+// told apart by GitHub's message.
+func isRateLimited(err error) bool {
+	var msg string
+	switch err := err.(type) {
+	case *RateLimitError:
+		return true
+	case *APIError:
+		msg = err.Message
+	case *UnexpectedResponseError:
+		msg = err.Snippet
+	}
+	msg = strings.ToLower(msg)
+	return strings.Contains(msg, "secondary rate limit") || strings.Contains(msg, "abuse detection")
+}
+
+func (c *Client) recordRateLimit(hdr http.Header) (RateLimit, bool) {
+	rl, ok := parseRateLimit(hdr)
+	if ok {
+		c.mu.Lock()
+		c.rateLimit, c.hasRateLimit = rl, true
+		c.mu.Unlock()
+	}
+	return rl, ok
+}
+
+// WaitForRateLimit blocks until the rate limit reported by the latest
+// response has requests remaining, or ctx is done. Once the reset time
+// passes, the rate limit is refreshed from "/rate_limit", which doesn't
+// itself count against the rate limit. It returns immediately if no
+// response has reported the rate limit yet.
+func (c *Client) WaitForRateLimit(ctx context.Context) error {
+	backoff := &Backoff{Base: time.Second}
+	for refreshed := false; ; refreshed = true {
+		rl, ok := c.LastRateLimit()
+		if !ok || rl.Remaining > 0 {
+			return nil
+		}
+		d := rl.sleepUntilReset(c.now())
+		if d == 0 && refreshed {
+			// Our clock is likely ahead of GitHub's.
+			d = backoff.Next()
+		}
+		if d > 0 {
+			timer := time.NewTimer(d)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return ctx.Err()
+			case <-timer.C:
+			}
+		}
+		if err := c.refreshRateLimit(ctx); err != nil {
+			return err
+		}
+	}
+}
+
+func (c *Client) refreshRateLimit(ctx context.Context) error {
+	req, err := http.NewRequest("GET", baseURL+"/rate_limit", nil)
+	if err != nil {
+		return err
+	}
+	_, _, err = c.doHTTPReq(req.WithContext(ctx))
+	return err
+}
@@ -0,0 +1,175 @@
+// Copyright 2017 orijtech. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gcla
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestRateLimitSleepUntilReset(t *testing.T) {
+	if g := (RateLimit{Reset: time.Now().Add(-time.Minute)}).SleepUntilReset(); g != 0 {
+		t.Errorf("past reset: got %v want 0", g)
+	}
+	if g := (RateLimit{}).SleepUntilReset(); g != 0 {
+		t.Errorf("zero reset: got %v want 0", g)
+	}
+	g := (RateLimit{Reset: time.Now().Add(10 * time.Minute)}).SleepUntilReset()
+	if g <= 9*time.Minute || g > 10*time.Minute {
+		t.Errorf("future reset: got %v want about 10m", g)
+	}
+}
+
+func setRateLimitHeaders(w http.ResponseWriter, remaining int, reset time.Time) {
+	w.Header().Set("X-RateLimit-Limit", "5000")
+	w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(remaining))
+	w.Header().Set("X-RateLimit-Used", strconv.Itoa(5000-remaining))
+	w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+	w.Header().Set("X-RateLimit-Resource", "core")
+}
+
+func TestRateLimitError(t *testing.T) {
+	reset := time.Now().Add(time.Hour).Truncate(time.Second)
+	client := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		setRateLimitHeaders(w, 0, reset)
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, `{"message":"API rate limit exceeded for user ID 7."}`)
+	})
+
+	_, err := client.GetHook("orijtech", "gcla", 1)
+	rle, ok := err.(*RateLimitError)
+	if !ok {
+		t.Fatalf("got err=%v (%T) want *RateLimitError", err, err)
+	}
+	want := RateLimit{Limit: 5000, Remaining: 0, Used: 5000, Reset: reset, Resource: "core"}
+	if rle.RateLimit != want {
+		t.Errorf("rate limit:\ngot:  %+v\nwant: %+v", rle.RateLimit, want)
+	}
+	if !isStatusCode(err, http.StatusForbidden) {
+		t.Errorf("expected a 403 status code")
+	}
+	if rl, ok := client.LastRateLimit(); !ok || rl != want {
+		t.Errorf("last rate limit: got %+v, %v", rl, ok)
+	}
+}
+
+func TestWaitForRateLimit(t *testing.T) {
+	refreshes := 0
+	client := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		if g, w := r.URL.Path, "/rate_limit"; g != w {
+			t.Errorf("path: got %q want %q", g, w)
+		}
+		refreshes++
+		setRateLimitHeaders(w, 5000, time.Now().Add(time.Hour))
+		fmt.Fprint(w, `{}`)
+	})
+
+	// Nothing is known about the rate limit yet.
+	if err := client.WaitForRateLimit(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// The reset time has passed: a single refresh suffices.
+	client.rateLimit = RateLimit{Limit: 5000, Reset: time.Now().Add(-time.Hour)}
+	client.hasRateLimit = true
+	if err := client.WaitForRateLimit(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if g, w := refreshes, 1; g != w {
+		t.Errorf("refreshes: got %d want %d", g, w)
+	}
+	if rl, _ := client.LastRateLimit(); rl.Remaining != 5000 {
+		t.Errorf("remaining: got %d want 5000", rl.Remaining)
+	}
+
+	// The reset time is in the future: ctx bounds the wait.
+	client.rateLimit = RateLimit{Limit: 5000, Reset: time.Now().Add(time.Hour)}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := client.WaitForRateLimit(ctx); err != context.DeadlineExceeded {
+		t.Errorf("got err=%v want %v", err, context.DeadlineExceeded)
+	}
+	if g, w := refreshes, 1; g != w {
+		t.Errorf("refreshes: got %d want %d", g, w)
+	}
+}
+
+func TestWaitForRateLimitClock(t *testing.T) {
+	start := time.Date(2023, time.March, 14, 17, 0, 0, 0, time.UTC)
+	now := start
+	refreshes := 0
+	client := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		refreshes++
+		remaining := 0
+		if !now.Before(start.Add(time.Hour)) {
+			remaining = 5000
+		}
+		w.Header().Set("X-RateLimit-Limit", "5000")
+		w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(remaining))
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(start.Add(time.Hour).Unix(), 10))
+		fmt.Fprint(w, `{}`)
+	})
+	WithClock(func() time.Time { return now })(client)
+	client.rateLimit = RateLimit{Limit: 5000, Reset: start.Add(time.Hour)}
+	client.hasRateLimit = true
+
+	// According to the clock, the reset is an hour away.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := client.WaitForRateLimit(ctx); err != context.DeadlineExceeded {
+		t.Errorf("got err=%v want %v", err, context.DeadlineExceeded)
+	}
+	if g, w := refreshes, 0; g != w {
+		t.Errorf("refreshes: got %d want %d", g, w)
+	}
+
+	// Once the clock passes the reset, the
+	// rate limit is refreshed without waiting.
+	now = start.Add(time.Hour + time.Second)
+	ctx, cancel = context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := client.WaitForRateLimit(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if g, w := refreshes, 1; g != w {
+		t.Errorf("refreshes: got %d want %d", g, w)
+	}
+	if rl, _ := client.LastRateLimit(); rl.Remaining != 5000 {
+		t.Errorf("remaining: got %d want 5000", rl.Remaining)
+	}
+}
+
+func TestNewClientWithClock(t *testing.T) {
+	t.Setenv(gclaEnvKey, "")
+	reset := time.Date(2023, time.March, 14, 18, 0, 0, 0, time.UTC)
+	client := NewClient("", WithClock(func() time.Time { return reset.Add(-time.Hour) }))
+	client.SetHTTPRoundTripper(backend(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected HTTP call: %s %s", r.Method, r.URL)
+	}))
+	client.rateLimit = RateLimit{Limit: 5000, Reset: reset}
+	client.hasRateLimit = true
+
+	// The reset has long passed in real time, but not
+	// according to the clock passed to NewClient.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := client.WaitForRateLimit(ctx); err != context.DeadlineExceeded {
+		t.Errorf("got err=%v want %v", err, context.DeadlineExceeded)
+	}
+}
@@ -0,0 +1,123 @@
+// Copyright 2017 orijtech. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gcla
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"sync"
+)
+
+// RecordingTransport is an http.RoundTripper for tests that records every
+// request that it receives and replies with the responses queued with
+// QueueResponse, in order. Install it with Client.SetHTTPRoundTripper to
+// assert on the calls that a multi-step operation makes without a server.
+type RecordingTransport struct {
+	mu        sync.Mutex
+	requests  []*http.Request
+	bodies    [][]byte
+	responses []*queuedResponse
+}
+
+var _ http.RoundTripper = (*RecordingTransport)(nil)
+
+type queuedResponse struct {
+	statusCode int
+	header     http.Header
+	body       []byte
+}
+
+// QueueResponse queues the response to the next request that hasn't got one.
+// header may be nil.
+func (rt *RecordingTransport) QueueResponse(statusCode int, header http.Header, body string) {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	rt.responses = append(rt.responses, &queuedResponse{
+		statusCode: statusCode,
+		header:     header,
+		body:       []byte(body),
+	})
+}
+
+// Requests returns the requests received so far, in order. The body of
+// each request is buffered, so it can be read from every returned request.
+func (rt *RecordingTransport) Requests() []*http.Request {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	reqs := make([]*http.Request, len(rt.requests))
+	for i, req := range rt.requests {
+		reqs[i] = withBody(req, rt.bodies[i])
+	}
+	return reqs
+}
+
+func (rt *RecordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	rt.mu.Lock()
+	rt.requests = append(rt.requests, withBody(req, body))
+	rt.bodies = append(rt.bodies, body)
+	var qr *queuedResponse
+	if len(rt.responses) > 0 {
+		qr, rt.responses = rt.responses[0], rt.responses[1:]
+	}
+	rt.mu.Unlock()
+
+	if qr == nil {
+		return nil, fmt.Errorf("RecordingTransport: no response queued for %s %s", req.Method, req.URL)
+	}
+	header := make(http.Header)
+	for key, values := range qr.header {
+		for _, value := range values {
+			// Add canonicalizes keys that weren't.
+			header.Add(key, value)
+		}
+	}
+	return &http.Response{
+		Status:        strconv.Itoa(qr.statusCode) + " " + http.StatusText(qr.statusCode),
+		StatusCode:    qr.statusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(bytes.NewReader(qr.body)),
+		ContentLength: int64(len(qr.body)),
+		Request:       req,
+	}, nil
+}
+
+// withBody returns a shallow copy of req whose body reads body.
+func withBody(req *http.Request, body []byte) *http.Request {
+	clone := new(http.Request)
+	*clone = *req
+	clone.Header = req.Header.Clone()
+	clone.Body = ioutil.NopCloser(bytes.NewReader(body))
+	clone.GetBody = func() (io.ReadCloser, error) {
+		return ioutil.NopCloser(bytes.NewReader(body)), nil
+	}
+	return clone
+}
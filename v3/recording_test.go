@@ -0,0 +1,81 @@
+// Copyright 2017 orijtech. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gcla
+
+import (
+	"io/ioutil"
+	"net/http"
+	"testing"
+)
+
+func TestRecordingTransport(t *testing.T) {
+	rt := new(RecordingTransport)
+	rt.QueueResponse(http.StatusOK, nil, `{"id": 7, "events": ["pull_request"]}`)
+	rt.QueueResponse(http.StatusOK, http.Header{HeaderRequestID: {"C5A4:6F8B"}}, `{"id": 7, "events": ["pull_request", "push"]}`)
+
+	client := &Client{apiKey: "test-key"}
+	client.SetHTTPRoundTripper(rt)
+
+	hook, err := client.AddHookEvents("orijtech", "gcla", 7, []Event{EventPush})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g, w := len(hook.Events), 2; g != w {
+		t.Errorf("events: got %d want %d", g, w)
+	}
+	if g, w := client.LastRequestID(), "C5A4:6F8B"; g != w {
+		t.Errorf("request ID: got %q want %q", g, w)
+	}
+
+	reqs := rt.Requests()
+	if g, w := len(reqs), 2; g != w {
+		t.Fatalf("got %d requests want %d", g, w)
+	}
+	want := [...]struct {
+		method, url, body string
+	}{
+		0: {method: "GET", url: "https://api.github.com/repos/orijtech/gcla/hooks/7"},
+		1: {method: "PATCH", url: "https://api.github.com/repos/orijtech/gcla/hooks/7", body: `{"events":["pull_request","push"]}`},
+	}
+	for i, req := range reqs {
+		if g, w := req.Method, want[i].method; g != w {
+			t.Errorf("#%d: method: got %q want %q", i, g, w)
+		}
+		if g, w := req.URL.String(), want[i].url; g != w {
+			t.Errorf("#%d: url: got %q want %q", i, g, w)
+		}
+		if g, w := req.Header.Get("Authorization"), "token test-key"; g != w {
+			t.Errorf("#%d: authorization: got %q want %q", i, g, w)
+		}
+		// Bodies are buffered so they can be read more than once.
+		for j := 0; j < 2; j++ {
+			body, err := ioutil.ReadAll(rt.Requests()[i].Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if g, w := string(body), want[i].body; g != w {
+				t.Errorf("#%d: read #%d: body: got %q want %q", i, j, g, w)
+			}
+		}
+	}
+
+	// Without queued responses, requests fail.
+	if _, err := client.GetHook("orijtech", "gcla", 7); err == nil {
+		t.Error("expected an error once the queued responses ran out")
+	}
+	if g, w := len(rt.Requests()), 3; g != w {
+		t.Errorf("got %d requests want %d", g, w)
+	}
+}
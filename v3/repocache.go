@@ -0,0 +1,116 @@
+// Copyright 2017 orijtech. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gcla
+
+import (
+	"container/list"
+	"strings"
+	"sync"
+)
+
+// CachedRepo is a repository as GetRepository last
+// retrieved it, alongside the ETag of that response.
+type CachedRepo struct {
+	ETag       string
+	Repository *Repository
+}
+
+// RepoCache stores the repositories that GetRepository retrieves, keyed
+// by "owner/repo" in lowercase. Cached repositories are revalidated with
+// a conditional request, which GitHub doesn't count against the rate
+// limit when it answers "304 Not Modified". Implementations must be safe
+// for concurrent use and should be bounded.
+type RepoCache interface {
+	Get(key string) (*CachedRepo, bool)
+	Put(key string, cr *CachedRepo)
+}
+
+// WithRepoCache makes a Client cache the repositories
+// that GetRepository retrieves in cache.
+func WithRepoCache(cache RepoCache) ClientOption {
+	return func(c *Client) {
+		c.repoCache = cache
+	}
+}
+
+func repoCacheKey(owner, repo string) string {
+	// GitHub's names are case-insensitive.
+	return strings.ToLower(owner + "/" + repo)
+}
+
+func (c *Client) getRepoCache() RepoCache {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.repoCache
+}
+
+// DefaultRepoCacheSize is the size of the cache
+// that NewRepoCache creates for a non-positive size.
+const DefaultRepoCacheSize = 128
+
+// NewRepoCache returns an in-memory RepoCache that holds up to size
+// repositories, evicting the least recently used one when it is full.
+func NewRepoCache(size int) RepoCache {
+	if size <= 0 {
+		size = DefaultRepoCacheSize
+	}
+	return &lruRepoCache{
+		size:    size,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+type lruRepoCache struct {
+	mu   sync.Mutex
+	size int
+	// order holds the keys, most recently used first.
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+type lruEntry struct {
+	key string
+	cr  *CachedRepo
+}
+
+var _ RepoCache = (*lruRepoCache)(nil)
+
+func (lc *lruRepoCache) Get(key string) (*CachedRepo, bool) {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	elem, ok := lc.entries[key]
+	if !ok {
+		return nil, false
+	}
+	lc.order.MoveToFront(elem)
+	return elem.Value.(*lruEntry).cr, true
+}
+
+func (lc *lruRepoCache) Put(key string, cr *CachedRepo) {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	if elem, ok := lc.entries[key]; ok {
+		elem.Value.(*lruEntry).cr = cr
+		lc.order.MoveToFront(elem)
+		return
+	}
+	lc.entries[key] = lc.order.PushFront(&lruEntry{key: key, cr: cr})
+	for lc.order.Len() > lc.size {
+		oldest := lc.order.Back()
+		lc.order.Remove(oldest)
+		delete(lc.entries, oldest.Value.(*lruEntry).key)
+	}
+}
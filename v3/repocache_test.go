@@ -0,0 +1,108 @@
+// Copyright 2017 orijtech. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gcla
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestGetRepositoryCache(t *testing.T) {
+	etag := `"v1"`
+	stars := 10
+	var ifNoneMatch []string
+	client := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		if g, w := strings.ToLower(r.URL.Path), "/repos/orijtech/gcla"; g != w {
+			t.Errorf("path: got %q want %q", g, w)
+		}
+		ifNoneMatch = append(ifNoneMatch, r.Header.Get("If-None-Match"))
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		fmt.Fprintf(w, `{"id": 35129377, "full_name": "orijtech/gcla", "stargazers_count": %d}`, stars)
+	})
+	cache := NewRepoCache(2)
+	WithRepoCache(cache)(client)
+
+	tests := [...]struct {
+		owner, repo string
+		// update if set changes the repository before the request.
+		update    bool
+		wantStars uint64
+		wantSent  string
+	}{
+		// A miss caches the response.
+		0: {owner: "orijtech", repo: "gcla", wantStars: 10, wantSent: ""},
+		// A hit is revalidated and answered with "304 Not Modified".
+		1: {owner: "orijtech", repo: "gcla", wantStars: 10, wantSent: `"v1"`},
+		// Names are case-insensitive.
+		2: {owner: "OrijTech", repo: "GCLA", wantStars: 10, wantSent: `"v1"`},
+		// A stale entry is replaced by GitHub's latest.
+		3: {owner: "orijtech", repo: "gcla", update: true, wantStars: 11, wantSent: `"v1"`},
+		4: {owner: "orijtech", repo: "gcla", wantStars: 11, wantSent: `"v2"`},
+	}
+
+	for i, tt := range tests {
+		if tt.update {
+			etag, stars = `"v2"`, 11
+		}
+		r, err := client.GetRepository(tt.owner, tt.repo)
+		if err != nil {
+			t.Errorf("#%d: %v", i, err)
+			continue
+		}
+		if g, w := r.StargazersCount, tt.wantStars; g != w {
+			t.Errorf("#%d: stars: got %d want %d", i, g, w)
+		}
+		if g, w := ifNoneMatch[len(ifNoneMatch)-1], tt.wantSent; g != w {
+			t.Errorf("#%d: If-None-Match: got %q want %q", i, g, w)
+		}
+		// Callers can't corrupt the cache.
+		r.FullName = "modified"
+	}
+	cr, ok := cache.Get("orijtech/gcla")
+	if !ok || cr.ETag != `"v2"` || cr.Repository.FullName != "orijtech/gcla" {
+		t.Errorf("cache: got %#v", cr)
+	}
+}
+
+func TestRepoCacheBounded(t *testing.T) {
+	cache := NewRepoCache(2)
+	for _, key := range []string{"a/1", "a/2", "a/3"} {
+		cache.Put(key, &CachedRepo{ETag: key})
+		if key == "a/2" {
+			// Make "a/1" the most recently used.
+			cache.Get("a/1")
+		}
+	}
+
+	tests := [...]struct {
+		key    string
+		wantOK bool
+	}{
+		0: {key: "a/1", wantOK: true},
+		1: {key: "a/2", wantOK: false},
+		2: {key: "a/3", wantOK: true},
+	}
+	for i, tt := range tests {
+		if _, ok := cache.Get(tt.key); ok != tt.wantOK {
+			t.Errorf("#%d: %q: got ok=%v want %v", i, tt.key, ok, tt.wantOK)
+		}
+	}
+}
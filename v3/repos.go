@@ -0,0 +1,491 @@
+// Copyright 2017 orijtech. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gcla
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+)
+
+// Visibility is who can see a repository. VisibilityInternal,
+// for members of the enterprise, is only available on GitHub Enterprise.
+type Visibility string
+
+const (
+	VisibilityInternal Visibility = "internal"
+	VisibilityPrivate  Visibility = "private"
+	VisibilityPublic   Visibility = "public"
+)
+
+// UnmarshalJSON keeps Private and Visibility consistent
+// when a payload only carries one of them.
+func (r *Repository) UnmarshalJSON(b []byte) error {
+	type repository Repository
+	if err := json.Unmarshal(b, (*repository)(r)); err != nil {
+		return err
+	}
+	switch {
+	case r.Visibility == "" && r.Private:
+		r.Visibility = VisibilityPrivate
+	case r.Visibility == VisibilityPrivate || r.Visibility == VisibilityInternal:
+		r.Private = true
+	}
+	return nil
+}
+
+// Skippable reports whether bots should leave the repository alone
+// since it is archived, and so read-only, or disabled by GitHub.
+func (r *Repository) Skippable() bool {
+	return r != nil && (r.Archived || r.Disabled)
+}
+
+type CreateRepoRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Homepage    string `json:"homepage,omitempty"`
+	Private     bool   `json:"private,omitempty"`
+
+	// AutoInit creates an initial commit with an empty README.
+	AutoInit bool `json:"auto_init,omitempty"`
+	// GitignoreTemplate is the name of a .gitignore template
+	// to apply e.g. "Go". It is ignored unless AutoInit is set.
+	GitignoreTemplate string `json:"gitignore_template,omitempty"`
+	// LicenseTemplate is the keyword of a license
+	// to apply e.g. "apache-2.0".
+	LicenseTemplate string `json:"license_template,omitempty"`
+}
+
+var errEmptyRepoName = errors.New("expecting a non-empty repository name")
+
+// CreateRepo creates a repository in org or for the
+// authenticated user if org is empty.
+func (c *Client) CreateRepo(org string, crr *CreateRepoRequest) (*Repository, error) {
+	if crr == nil || crr.Name == "" {
+		return nil, errEmptyRepoName
+	}
+	blob, err := json.Marshal(crr)
+	if err != nil {
+		return nil, err
+	}
+	fullURL := fmt.Sprintf("%s/user/repos", baseURL)
+	if org != "" {
+		fullURL = fmt.Sprintf("%s/orgs/%s/repos", baseURL, org)
+	}
+	req, err := http.NewRequest("POST", fullURL, bytes.NewReader(blob))
+	if err != nil {
+		return nil, err
+	}
+	blob, _, err = c.doHTTPReq(req)
+	if err != nil {
+		return nil, err
+	}
+	repo := new(Repository)
+	if err := json.Unmarshal(blob, repo); err != nil {
+		return nil, err
+	}
+	return repo, nil
+}
+
+// GetRepository retrieves owner/repo. With WithRepoCache,
+// a cached copy is returned if it is still up to date.
+func (c *Client) GetRepository(owner, repo string) (*Repository, error) {
+	if owner == "" {
+		return nil, errEmptyOwner
+	}
+	if repo == "" {
+		return nil, errEmptyRepo
+	}
+	fullURL := fmt.Sprintf("%s/repos/%s/%s", baseURL, owner, repo)
+	req, err := http.NewRequest("GET", fullURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	cache := c.getRepoCache()
+	var cached *CachedRepo
+	if cache != nil {
+		if cr, ok := cache.Get(repoCacheKey(owner, repo)); ok && cr != nil && cr.Repository != nil && cr.ETag != "" {
+			cached = cr
+			req.Header.Set("If-None-Match", cr.ETag)
+		}
+	}
+	blob, hdr, err := c.doHTTPReq(req)
+	if cached != nil && isStatusCode(err, http.StatusNotModified) {
+		// Copy it so that callers can't modify the cache.
+		r := *cached.Repository
+		return &r, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	got := new(Repository)
+	if err := json.Unmarshal(blob, got); err != nil {
+		return nil, err
+	}
+	if etag := hdr.Get("ETag"); cache != nil && etag != "" {
+		r := *got
+		cache.Put(repoCacheKey(owner, repo), &CachedRepo{ETag: etag, Repository: &r})
+	}
+	return got, nil
+}
+
+// WithDefaultBranchCache makes a Client remember the default branch of
+// each repository that DefaultBranchSHA looks up, saving a request on
+// subsequent calls. A cached branch that no longer exists, say because
+// it was renamed, is looked up again.
+func WithDefaultBranchCache() ClientOption {
+	return func(c *Client) {
+		c.defaultBranches = make(map[string]string)
+	}
+}
+
+// DefaultBranchSHA returns the SHA of the
+// latest commit on the default branch of owner/repo.
+func (c *Client) DefaultBranchSHA(owner, repo string) (string, error) {
+	branch, cached := c.cachedDefaultBranch(owner, repo)
+	if cached {
+		ref, err := c.GetRef(owner, repo, "refs/heads/"+branch)
+		if !isStatusCode(err, http.StatusNotFound) {
+			return refSHA(ref, err)
+		}
+	}
+	r, err := c.GetRepository(owner, repo)
+	if err != nil {
+		return "", err
+	}
+	if r.DefaultBranch == "" {
+		// Empty repositories have no branches.
+		return "", fmt.Errorf("%s/%s has no default branch", owner, repo)
+	}
+	c.cacheDefaultBranch(owner, repo, r.DefaultBranch)
+	return refSHA(c.GetRef(owner, repo, "refs/heads/"+r.DefaultBranch))
+}
+
+func refSHA(ref *Reference, err error) (string, error) {
+	if err != nil {
+		return "", err
+	}
+	if ref.Object == nil {
+		return "", fmt.Errorf("%q points to no object", ref.Ref)
+	}
+	return ref.Object.SHA, nil
+}
+
+func (c *Client) cachedDefaultBranch(owner, repo string) (string, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	branch, ok := c.defaultBranches[owner+"/"+repo]
+	return branch, ok
+}
+
+func (c *Client) cacheDefaultBranch(owner, repo, branch string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.defaultBranches != nil {
+		c.defaultBranches[owner+"/"+repo] = branch
+	}
+}
+
+// RepoListOptions filters and orders the results of ListUserRepos
+// and ListMyRepos. Zero values are omitted, deferring to GitHub's defaults.
+type RepoListOptions struct {
+	// Type is one of "all", "owner" or "member", and also "public"
+	// or "private" for ListMyRepos.
+	Type string
+	// Sort is one of "created", "updated", "pushed" or "full_name".
+	Sort string
+	// Direction is either "asc" or "desc".
+	Direction string
+}
+
+func (rlo *RepoListOptions) values() (url.Values, error) {
+	qv := make(url.Values)
+	if rlo == nil {
+		return qv, nil
+	}
+	switch rlo.Type {
+	case "", "all", "owner", "member", "public", "private":
+	default:
+		return nil, fmt.Errorf("invalid type %q", rlo.Type)
+	}
+	switch rlo.Sort {
+	case "", "created", "updated", "pushed", "full_name":
+	default:
+		return nil, fmt.Errorf("invalid sort %q", rlo.Sort)
+	}
+	if err := validateDirection(rlo.Direction); err != nil {
+		return nil, err
+	}
+	for key, value := range map[string]string{
+		"type":      rlo.Type,
+		"sort":      rlo.Sort,
+		"direction": rlo.Direction,
+	} {
+		if value != "" {
+			qv.Set(key, value)
+		}
+	}
+	return qv, nil
+}
+
+// ListUserRepos returns the public repositories of username
+// that match opts, following pagination.
+func (c *Client) ListUserRepos(username string, opts *RepoListOptions) ([]*Repository, error) {
+	if username == "" {
+		return nil, errEmptyUsername
+	}
+	if opts != nil && (opts.Type == "public" || opts.Type == "private") {
+		return nil, fmt.Errorf("invalid type %q, expecting one of \"all\", \"owner\" or \"member\"", opts.Type)
+	}
+	return c.listRepos(fmt.Sprintf("%s/users/%s/repos", baseURL, username), opts)
+}
+
+// ListMyRepos returns the repositories that the authenticated
+// user has access to and that match opts, following pagination.
+func (c *Client) ListMyRepos(opts *RepoListOptions) ([]*Repository, error) {
+	return c.listRepos(baseURL+"/user/repos", opts)
+}
+
+func (c *Client) listRepos(fullURL string, opts *RepoListOptions) ([]*Repository, error) {
+	qv, err := opts.values()
+	if err != nil {
+		return nil, err
+	}
+	if len(qv) > 0 {
+		fullURL += "?" + qv.Encode()
+	}
+
+	var repos []*Repository
+	err = c.getAllPages(fullURL, func(blob []byte) error {
+		var page []*Repository
+		if err := json.Unmarshal(blob, &page); err != nil {
+			return err
+		}
+		repos = append(repos, page...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return repos, nil
+}
+
+// IsCollaborator reports whether username is a collaborator on owner/repo,
+// including organization members with access to it. It requires push access.
+func (c *Client) IsCollaborator(owner, repo, username string) (bool, error) {
+	if owner == "" {
+		return false, errEmptyOwner
+	}
+	if repo == "" {
+		return false, errEmptyRepo
+	}
+	if username == "" {
+		return false, errEmptyUsername
+	}
+	return c.boolRequest("GET", fmt.Sprintf("/repos/%s/%s/collaborators/%s", owner, repo, username))
+}
+
+// DeleteRepo deletes owner/repo. It requires
+// admin access and the "delete_repo" scope.
+func (c *Client) DeleteRepo(owner, repo string) error {
+	if owner == "" {
+		return errEmptyOwner
+	}
+	if repo == "" {
+		return errEmptyRepo
+	}
+	fullURL := fmt.Sprintf("%s/repos/%s/%s", baseURL, owner, repo)
+	req, err := http.NewRequest("DELETE", fullURL, nil)
+	if err != nil {
+		return err
+	}
+	_, _, err = c.doHTTPReq(req)
+	return err
+}
+
+// UnarchiveForbiddenError is returned by SetRepoArchived when GitHub
+// refuses to unarchive a repository with "403 Forbidden", which it does
+// for repositories that can only be unarchived from the web interface,
+// such as those archived by GitHub staff, and for non-admins.
+type UnarchiveForbiddenError struct {
+	Owner string
+	Repo  string
+	// Err is the *APIError or *UnexpectedResponseError
+	// that GitHub's response was decoded into.
+	Err error
+}
+
+func (ufe *UnarchiveForbiddenError) Error() string {
+	return fmt.Sprintf("unarchiving %s/%s is forbidden: %v", ufe.Owner, ufe.Repo, ufe.Err)
+}
+
+// SetRepoArchived archives owner/repo, making it read-only,
+// or unarchives it. It requires admin access to the repository.
+func (c *Client) SetRepoArchived(owner, repo string, archived bool) (*Repository, error) {
+	if owner == "" {
+		return nil, errEmptyOwner
+	}
+	if repo == "" {
+		return nil, errEmptyRepo
+	}
+	blob, err := json.Marshal(struct {
+		Archived bool `json:"archived"`
+	}{archived})
+	if err != nil {
+		return nil, err
+	}
+	fullURL := fmt.Sprintf("%s/repos/%s/%s", baseURL, owner, repo)
+	req, err := http.NewRequest("PATCH", fullURL, bytes.NewReader(blob))
+	if err != nil {
+		return nil, err
+	}
+	blob, _, err = c.doHTTPReq(req)
+	if err != nil {
+		// Rate limits are also reported with "403 Forbidden".
+		if !archived && isStatusCode(err, http.StatusForbidden) && !isRateLimited(err) {
+			return nil, &UnarchiveForbiddenError{Owner: owner, Repo: repo, Err: err}
+		}
+		return nil, err
+	}
+	got := new(Repository)
+	if err := json.Unmarshal(blob, got); err != nil {
+		return nil, err
+	}
+	return got, nil
+}
+
+// RepoRenamedError is returned for requests to a repository that was
+// renamed or transferred, which GitHub answers with a redirect. Such
+// redirects aren't followed so that callers can update their references,
+// as a redirected POST would otherwise be retried as a GET.
+type RepoRenamedError struct {
+	// OldFullName is the "owner/repo" that was requested.
+	OldFullName string
+	// NewFullName is the current "owner/repo" of the repository.
+	// It is empty if it couldn't be resolved.
+	NewFullName string
+	// Location is where GitHub redirected the request to, for example
+	// "https://api.github.com/repositories/35129377/hooks".
+	Location string
+}
+
+func (rre *RepoRenamedError) Error() string {
+	if rre.NewFullName == "" {
+		return fmt.Sprintf("repository %q has moved to %q", rre.OldFullName, rre.Location)
+	}
+	return fmt.Sprintf("repository %q was renamed to %q", rre.OldFullName, rre.NewFullName)
+}
+
+var (
+	repoPathRegexp         = regexp.MustCompile(`^/repos/([^/]+)/([^/]+)`)
+	repositoryIDPathRegexp = regexp.MustCompile(`^/repositories/(\d+)`)
+)
+
+func isRedirect(code int) bool {
+	switch code {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		return true
+	default:
+		return false
+	}
+}
+
+// DefaultMaxRedirects is how many redirects a Client
+// follows per request unless WithMaxRedirects is used.
+const DefaultMaxRedirects = 5
+
+// WithMaxRedirects sets how many redirects a Client follows per request
+// before giving up with a *TooManyRedirectsError, guarding against
+// redirect loops. A negative n disables following redirects.
+// It defaults to DefaultMaxRedirects.
+func WithMaxRedirects(n int) ClientOption {
+	return func(c *Client) {
+		c.maxRedirects = n
+	}
+}
+
+// TooManyRedirectsError is returned when a request
+// was redirected more times than the Client allows.
+type TooManyRedirectsError struct {
+	Max int
+	// Location is the redirect that wasn't followed.
+	Location string
+}
+
+func (tmre *TooManyRedirectsError) Error() string {
+	return fmt.Sprintf("stopped after %d redirects, at %q", tmre.Max, tmre.Location)
+}
+
+// checkRedirect stops at redirects of repository endpoints
+// so that doHTTPReq can report them as *RepoRenamedError.
+func (c *Client) checkRedirect(req *http.Request, via []*http.Request) error {
+	if repoPathRegexp.MatchString(via[0].URL.Path) {
+		return http.ErrUseLastResponse
+	}
+	c.mu.RLock()
+	max := c.maxRedirects
+	c.mu.RUnlock()
+	switch {
+	case max == 0:
+		max = DefaultMaxRedirects
+	case max < 0:
+		max = 0
+	}
+	if len(via) > max {
+		return &TooManyRedirectsError{Max: max, Location: c.logURL(req.URL)}
+	}
+	return nil
+}
+
+// repoRenamedError returns the *RepoRenamedError for res, a redirect
+// in response to req, or nil if req wasn't for a repository endpoint.
+// GitHub redirects to the repository's ID, so its current name is
+// looked up with a request to "/repositories/:id".
+func (c *Client) repoRenamedError(req *http.Request, res *http.Response) *RepoRenamedError {
+	match := repoPathRegexp.FindStringSubmatch(req.URL.Path)
+	if match == nil {
+		return nil
+	}
+	rre := &RepoRenamedError{
+		OldFullName: match[1] + "/" + match[2],
+		Location:    res.Header.Get("Location"),
+	}
+	loc, err := url.Parse(rre.Location)
+	if err != nil {
+		return rre
+	}
+	idMatch := repositoryIDPathRegexp.FindStringSubmatch(loc.Path)
+	if idMatch == nil {
+		return rre
+	}
+	lookup, err := http.NewRequest("GET", fmt.Sprintf("%s/repositories/%s", baseURL, idMatch[1]), nil)
+	if err != nil {
+		return rre
+	}
+	blob, _, err := c.doHTTPReq(lookup)
+	if err != nil {
+		return rre
+	}
+	repo := new(Repository)
+	if err := json.Unmarshal(blob, repo); err == nil {
+		rre.NewFullName = repo.FullName
+	}
+	return rre
+}
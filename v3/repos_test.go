@@ -0,0 +1,661 @@
+// Copyright 2017 orijtech. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gcla
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCreateRepo(t *testing.T) {
+	tests := [...]struct {
+		org      string
+		wantPath string
+		wantName string
+	}{
+		0: {org: "", wantPath: "/user/repos", wantName: "odeke-em/sandbox"},
+		1: {org: "orijtech", wantPath: "/orgs/orijtech/repos", wantName: "orijtech/sandbox"},
+	}
+
+	for i, tt := range tests {
+		client := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+			if g, w := r.Method, "POST"; g != w {
+				t.Errorf("#%d: method: got %q want %q", i, g, w)
+			}
+			if g, w := r.URL.Path, tt.wantPath; g != w {
+				t.Errorf("#%d: path: got %q want %q", i, g, w)
+			}
+			crr := new(CreateRepoRequest)
+			if err := json.NewDecoder(r.Body).Decode(crr); err != nil {
+				t.Errorf("#%d: decoding body: %v", i, err)
+			}
+			if !crr.Private || !crr.AutoInit || crr.GitignoreTemplate != "Go" || crr.LicenseTemplate != "apache-2.0" {
+				t.Errorf("#%d: unexpected request body: %#v", i, crr)
+			}
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprintf(w, `{"id":99,"name":%q,"full_name":%q,"private":true}`, crr.Name, tt.wantName)
+		})
+
+		repo, err := client.CreateRepo(tt.org, &CreateRepoRequest{
+			Name:              "sandbox",
+			Description:       "Scratch space",
+			Private:           true,
+			AutoInit:          true,
+			GitignoreTemplate: "Go",
+			LicenseTemplate:   "apache-2.0",
+		})
+		if err != nil {
+			t.Errorf("#%d: %v", i, err)
+			continue
+		}
+		if g, w := repo.FullName, tt.wantName; g != w {
+			t.Errorf("#%d: full_name: got %q want %q", i, g, w)
+		}
+		if !repo.Private {
+			t.Errorf("#%d: expected a private repository", i)
+		}
+	}
+}
+
+func TestCreateRepoRequiresName(t *testing.T) {
+	client := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request to %q", r.URL)
+	})
+	if _, err := client.CreateRepo("orijtech", &CreateRepoRequest{}); err == nil {
+		t.Errorf("expected an error for an empty name")
+	}
+}
+
+func TestDeleteRepo(t *testing.T) {
+	var deleted bool
+	client := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "DELETE" && r.URL.Path == "/repos/orijtech/sandbox" {
+			deleted = true
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		http.Error(w, `{"message":"Not Found"}`, http.StatusNotFound)
+	})
+
+	if err := client.DeleteRepo("orijtech", "sandbox"); err != nil {
+		t.Fatal(err)
+	}
+	if !deleted {
+		t.Errorf("expected the repository to have been deleted")
+	}
+	if err := client.DeleteRepo("orijtech", "missing"); !isStatusCode(err, http.StatusNotFound) {
+		t.Errorf("got err=%v want a 404 *APIError", err)
+	}
+}
+
+func TestDefaultBranchSHA(t *testing.T) {
+	const sha = "aa218f56b14c9653891f9e74264a383fa43fefbd"
+	tests := [...]struct {
+		opts []ClientOption
+		want []string
+	}{
+		// Without a cache, the repository is fetched every time.
+		0: {
+			want: []string{
+				"/repos/orijtech/gcla",
+				"/repos/orijtech/gcla/git/ref/heads/main",
+				"/repos/orijtech/gcla",
+				"/repos/orijtech/gcla/git/ref/heads/main",
+			},
+		},
+		1: {
+			opts: []ClientOption{WithDefaultBranchCache()},
+			want: []string{
+				"/repos/orijtech/gcla",
+				"/repos/orijtech/gcla/git/ref/heads/main",
+				"/repos/orijtech/gcla/git/ref/heads/main",
+			},
+		},
+	}
+
+	for i, tt := range tests {
+		var got []string
+		client := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+			got = append(got, r.URL.Path)
+			switch r.URL.Path {
+			case "/repos/orijtech/gcla":
+				fmt.Fprint(w, `{"id": 35129377, "full_name": "orijtech/gcla", "default_branch": "main"}`)
+			case "/repos/orijtech/gcla/git/ref/heads/main":
+				fmt.Fprintf(w, `{"ref": "refs/heads/main", "object": {"type": "commit", "sha": %q}}`, sha)
+			default:
+				http.Error(w, `{"message":"Not Found"}`, http.StatusNotFound)
+			}
+		})
+		for _, opt := range tt.opts {
+			opt(client)
+		}
+
+		for j := 0; j < 2; j++ {
+			gotSHA, err := client.DefaultBranchSHA("orijtech", "gcla")
+			if err != nil {
+				t.Errorf("#%d.%d: %v", i, j, err)
+				continue
+			}
+			if gotSHA != sha {
+				t.Errorf("#%d.%d: got %q want %q", i, j, gotSHA, sha)
+			}
+		}
+		if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("#%d: requests:\ngot:  %q\nwant: %q", i, got, tt.want)
+		}
+	}
+}
+
+func TestDefaultBranchSHARenamedBranch(t *testing.T) {
+	defaultBranch := "master"
+	var got []string
+	client := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		got = append(got, r.URL.Path)
+		switch r.URL.Path {
+		case "/repos/orijtech/gcla":
+			fmt.Fprintf(w, `{"default_branch": %q}`, defaultBranch)
+		case "/repos/orijtech/gcla/git/ref/heads/" + defaultBranch:
+			fmt.Fprintf(w, `{"ref": "refs/heads/%s", "object": {"sha": "%s"}}`, defaultBranch, defaultBranch+"-sha")
+		default:
+			http.Error(w, `{"message":"Not Found"}`, http.StatusNotFound)
+		}
+	})
+	WithDefaultBranchCache()(client)
+
+	if _, err := client.DefaultBranchSHA("orijtech", "gcla"); err != nil {
+		t.Fatal(err)
+	}
+	defaultBranch = "main"
+	sha, err := client.DefaultBranchSHA("orijtech", "gcla")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g, w := sha, "main-sha"; g != w {
+		t.Errorf("got %q want %q", g, w)
+	}
+	want := []string{
+		"/repos/orijtech/gcla",
+		"/repos/orijtech/gcla/git/ref/heads/master",
+		// The cached branch is gone.
+		"/repos/orijtech/gcla/git/ref/heads/master",
+		"/repos/orijtech/gcla",
+		"/repos/orijtech/gcla/git/ref/heads/main",
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("requests:\ngot:  %q\nwant: %q", got, want)
+	}
+
+	// Empty repositories have no default branch.
+	client = newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"full_name": "orijtech/empty"}`)
+	})
+	if _, err := client.DefaultBranchSHA("orijtech", "empty"); err == nil {
+		t.Error("expected an error for an empty repository")
+	}
+	if _, err := client.DefaultBranchSHA("", "gcla"); err == nil {
+		t.Error("expected an error for an empty owner")
+	}
+}
+
+func TestSetRepoArchived(t *testing.T) {
+	var bodies []string
+	client := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		if g, w := r.Method+" "+r.URL.Path, "PATCH /repos/orijtech/gcla"; g != w {
+			t.Errorf("route: got %q want %q", g, w)
+		}
+		blob, _ := ioutil.ReadAll(r.Body)
+		bodies = append(bodies, string(blob))
+		if strings.Contains(string(blob), "false") {
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"message":"Repository was archived so is read-only."}`)
+			return
+		}
+		fmt.Fprint(w, `{"id": 35129377, "full_name": "orijtech/gcla", "archived": true}`)
+	})
+
+	repo, err := client.SetRepoArchived("orijtech", "gcla", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !repo.Archived {
+		t.Error("expected the repository to be archived")
+	}
+
+	_, err = client.SetRepoArchived("orijtech", "gcla", false)
+	ufe, ok := err.(*UnarchiveForbiddenError)
+	if !ok {
+		t.Fatalf("got err=%v (%T) want *UnarchiveForbiddenError", err, err)
+	}
+	if !isStatusCode(ufe.Err, http.StatusForbidden) {
+		t.Errorf("got %#v", ufe.Err)
+	}
+	if g, w := fmt.Sprint(bodies), `[{"archived":true} {"archived":false}]`; g != w {
+		t.Errorf("bodies: got %s want %s", g, w)
+	}
+
+	if _, err := client.SetRepoArchived("", "gcla", true); err == nil {
+		t.Error("expected an error for an empty owner")
+	}
+}
+
+func TestSetRepoArchivedForbidden(t *testing.T) {
+	tests := [...]struct {
+		contentType   string
+		remaining     string
+		body          string
+		wantForbidden bool
+	}{
+		0: {
+			contentType:   "application/json",
+			remaining:     "4999",
+			body:          `{"message":"Must have admin rights to Repository."}`,
+			wantForbidden: true,
+		},
+		// GitHub's HTML error pages are still decided by the status.
+		1: {
+			contentType:   "text/html",
+			remaining:     "4999",
+			body:          `<html><body>Forbidden</body></html>`,
+			wantForbidden: true,
+		},
+		// Secondary rate limits leave requests remaining.
+		2: {
+			contentType: "application/json",
+			remaining:   "4999",
+			body:        `{"message":"You have exceeded a secondary rate limit. Please wait a few minutes before you try again."}`,
+		},
+		3: {
+			contentType: "application/json",
+			remaining:   "0",
+			body:        `{"message":"API rate limit exceeded for user ID 1."}`,
+		},
+	}
+
+	for i, tt := range tests {
+		client := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", tt.contentType)
+			w.Header().Set("X-RateLimit-Limit", "5000")
+			w.Header().Set("X-RateLimit-Remaining", tt.remaining)
+			w.Header().Set("X-RateLimit-Reset", "1678813200")
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, tt.body)
+		})
+		_, err := client.SetRepoArchived("orijtech", "gcla", false)
+		if err == nil {
+			t.Errorf("#%d: expected an error", i)
+			continue
+		}
+		ufe, ok := err.(*UnarchiveForbiddenError)
+		if ok != tt.wantForbidden {
+			t.Errorf("#%d: got err=%v (%T), want *UnarchiveForbiddenError: %v", i, err, err, tt.wantForbidden)
+			continue
+		}
+		if ok && !isStatusCode(ufe.Err, http.StatusForbidden) {
+			t.Errorf("#%d: got %#v", i, ufe.Err)
+		}
+	}
+}
+
+func TestRepositorySkippable(t *testing.T) {
+	tests := [...]struct {
+		payload       string
+		wantArchived  bool
+		wantDisabled  bool
+		wantSkippable bool
+	}{
+		0: {payload: `{"full_name": "orijtech/gcla", "archived": false, "disabled": false}`},
+		1: {
+			payload:      `{"full_name": "orijtech/gcla", "fork": false, "archived": true, "disabled": false, "default_branch": "master"}`,
+			wantArchived: true, wantSkippable: true,
+		},
+		2: {
+			payload:      `{"full_name": "orijtech/gcla", "archived": false, "disabled": true}`,
+			wantDisabled: true, wantSkippable: true,
+		},
+		3: {
+			payload:      `{"full_name": "orijtech/gcla", "archived": true, "disabled": true}`,
+			wantArchived: true, wantDisabled: true, wantSkippable: true,
+		},
+	}
+
+	for i, tt := range tests {
+		r := new(Repository)
+		if err := json.Unmarshal([]byte(tt.payload), r); err != nil {
+			t.Errorf("#%d: %v", i, err)
+			continue
+		}
+		if r.Archived != tt.wantArchived || r.Disabled != tt.wantDisabled {
+			t.Errorf("#%d: got archived=%v disabled=%v want %v %v", i, r.Archived, r.Disabled, tt.wantArchived, tt.wantDisabled)
+		}
+		if g, w := r.Skippable(), tt.wantSkippable; g != w {
+			t.Errorf("#%d: Skippable: got %v want %v", i, g, w)
+		}
+	}
+
+	var nilRepo *Repository
+	if nilRepo.Skippable() {
+		t.Error("a nil repository isn't skippable")
+	}
+}
+
+func TestMaxRedirects(t *testing.T) {
+	tests := [...]struct {
+		opts     []ClientOption
+		wantMax  int
+		wantHops int
+	}{
+		0: {wantMax: DefaultMaxRedirects, wantHops: DefaultMaxRedirects + 1},
+		1: {opts: []ClientOption{WithMaxRedirects(2)}, wantMax: 2, wantHops: 3},
+		2: {opts: []ClientOption{WithMaxRedirects(-1)}, wantMax: 0, wantHops: 1},
+	}
+
+	for i, tt := range tests {
+		var hops int
+		client := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+			hops++
+			// Bounce between two URLs forever.
+			next := "/user/a"
+			if r.URL.Path == next {
+				next = "/user/b"
+			}
+			http.Redirect(w, r, next, http.StatusFound)
+		})
+		for _, opt := range tt.opts {
+			opt(client)
+		}
+
+		req, _ := http.NewRequest("GET", "https://api.github.com/user/a", nil)
+		_, _, err := client.doHTTPReq(req)
+		tmre, ok := err.(*TooManyRedirectsError)
+		if !ok {
+			t.Errorf("#%d: got err=%v (%T) want a *TooManyRedirectsError", i, err, err)
+			continue
+		}
+		if g, w := tmre.Max, tt.wantMax; g != w {
+			t.Errorf("#%d: max: got %d want %d", i, g, w)
+		}
+		if g, w := hops, tt.wantHops; g != w {
+			t.Errorf("#%d: requests: got %d want %d", i, g, w)
+		}
+	}
+}
+
+func TestRepoRenamed(t *testing.T) {
+	client := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/orijtech/old-gcla/hooks", "/repos/orijtech/old-gcla/hooks/1":
+			w.Header().Set("Location", "https://api.github.com/repositories/35129377"+r.URL.Path[len("/repos/orijtech/old-gcla"):])
+			w.WriteHeader(http.StatusMovedPermanently)
+			fmt.Fprint(w, `{"message":"Moved Permanently","url":"https://api.github.com/repositories/35129377/hooks"}`)
+		case "/repositories/35129377":
+			fmt.Fprint(w, `{"id":35129377,"full_name":"orijtech/gcla"}`)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+			http.NotFound(w, r)
+		}
+	})
+
+	_, err := client.SubscribeToRepo(&RepoSubscribeRequest{
+		Owner:            "orijtech",
+		Repo:             "old-gcla",
+		HookSubscription: &SubscribeRequest{Name: "web", Events: []Event{EventPush}},
+	})
+	rre, ok := err.(*RepoRenamedError)
+	if !ok {
+		t.Fatalf("got err=%v (%T) want *RepoRenamedError", err, err)
+	}
+	want := &RepoRenamedError{
+		OldFullName: "orijtech/old-gcla",
+		NewFullName: "orijtech/gcla",
+		Location:    "https://api.github.com/repositories/35129377/hooks",
+	}
+	if *rre != *want {
+		t.Errorf("got %+v want %+v", rre, want)
+	}
+
+	// GET requests aren't followed either.
+	_, err = client.GetHook("orijtech", "old-gcla", 1)
+	if rre, ok := err.(*RepoRenamedError); !ok || rre.NewFullName != "orijtech/gcla" {
+		t.Errorf("got err=%v (%T) want *RepoRenamedError", err, err)
+	}
+}
+
+func TestRepositoryLicense(t *testing.T) {
+	tests := [...]struct {
+		payload string
+		want    *License
+	}{
+		0: {
+			payload: `{
+  "id": 35129377,
+  "name": "gcla",
+  "full_name": "orijtech/gcla",
+  "license": {
+    "key": "apache-2.0",
+    "name": "Apache License 2.0",
+    "spdx_id": "Apache-2.0",
+    "url": "https://api.github.com/licenses/apache-2.0",
+    "node_id": "MDc6TGljZW5zZTI="
+  }
+}`,
+			want: &License{
+				Key:    "apache-2.0",
+				Name:   "Apache License 2.0",
+				SPDXID: "Apache-2.0",
+				URL:    "https://api.github.com/licenses/apache-2.0",
+				NodeID: "MDc6TGljZW5zZTI=",
+			},
+		},
+		1: {
+			payload: `{"full_name": "orijtech/gcla", "license": {"key": "other", "name": "Other", "spdx_id": "NOASSERTION", "url": null}}`,
+			want:    &License{Key: "other", Name: "Other", SPDXID: "NOASSERTION"},
+		},
+		2: {payload: `{"full_name": "orijtech/gcla", "license": null}`},
+	}
+
+	for i, tt := range tests {
+		repo := new(Repository)
+		if err := json.Unmarshal([]byte(tt.payload), repo); err != nil {
+			t.Errorf("#%d: %v", i, err)
+			continue
+		}
+		if !reflect.DeepEqual(repo.License, tt.want) {
+			t.Errorf("#%d: got %#v want %#v", i, repo.License, tt.want)
+		}
+	}
+}
+
+func TestRepositoryVisibility(t *testing.T) {
+	tests := [...]struct {
+		payload        string
+		wantPrivate    bool
+		wantVisibility Visibility
+	}{
+		0: {payload: `{"full_name": "orijtech/gcla", "private": false, "visibility": "public"}`, wantVisibility: VisibilityPublic},
+		1: {payload: `{"full_name": "orijtech/infra", "private": true, "visibility": "internal"}`, wantPrivate: true, wantVisibility: VisibilityInternal},
+		2: {payload: `{"full_name": "orijtech/infra", "visibility": "internal"}`, wantPrivate: true, wantVisibility: VisibilityInternal},
+		3: {payload: `{"full_name": "orijtech/secrets", "private": true}`, wantPrivate: true, wantVisibility: VisibilityPrivate},
+		// Older payloads lack "visibility".
+		4: {payload: `{"full_name": "orijtech/gcla", "private": false}`},
+	}
+
+	for i, tt := range tests {
+		repo := new(Repository)
+		if err := json.Unmarshal([]byte(tt.payload), repo); err != nil {
+			t.Errorf("#%d: %v", i, err)
+			continue
+		}
+		if g, w := repo.Private, tt.wantPrivate; g != w {
+			t.Errorf("#%d: private: got %v want %v", i, g, w)
+		}
+		if g, w := repo.Visibility, tt.wantVisibility; g != w {
+			t.Errorf("#%d: visibility: got %q want %q", i, g, w)
+		}
+	}
+}
+
+func TestRepositoryForkParentAndSource(t *testing.T) {
+	const payload = `{
+  "full_name": "odeke-em/gcla",
+  "fork": true,
+  "parent": {"full_name": "orijtech/gcla", "fork": true, "visibility": "public"},
+  "source": {"full_name": "googleapis/gcla", "fork": false, "visibility": "public"}
+}`
+	repo := new(Repository)
+	if err := json.Unmarshal([]byte(payload), repo); err != nil {
+		t.Fatal(err)
+	}
+	if !repo.Fork {
+		t.Error("expected a fork")
+	}
+	if repo.Parent == nil || repo.Parent.FullName != "orijtech/gcla" {
+		t.Fatalf("parent: got %#v", repo.Parent)
+	}
+	if !repo.Parent.Fork || repo.Parent.Visibility != VisibilityPublic {
+		t.Errorf("parent: got fork=%v visibility=%q", repo.Parent.Fork, repo.Parent.Visibility)
+	}
+	if repo.Source == nil || repo.Source.FullName != "googleapis/gcla" {
+		t.Errorf("source: got %#v", repo.Source)
+	}
+	if repo.Parent.Parent != nil || repo.Source.Source != nil {
+		t.Error("expected the nested repositories to have no parent or source")
+	}
+
+	notFork := new(Repository)
+	if err := json.Unmarshal([]byte(`{"full_name": "orijtech/gcla"}`), notFork); err != nil {
+		t.Fatal(err)
+	}
+	if notFork.Parent != nil || notFork.Source != nil {
+		t.Errorf("unexpected parent %#v or source %#v", notFork.Parent, notFork.Source)
+	}
+}
+
+func TestListUserRepos(t *testing.T) {
+	tests := [...]struct {
+		list      func(*Client) ([]*Repository, error)
+		wantPath  string
+		wantQuery string
+	}{
+		0: {
+			list: func(c *Client) ([]*Repository, error) {
+				return c.ListUserRepos("odeke-em", &RepoListOptions{Type: "owner", Sort: "pushed", Direction: "desc"})
+			},
+			wantPath:  "/users/odeke-em/repos",
+			wantQuery: "direction=desc&sort=pushed&type=owner",
+		},
+		1: {
+			list: func(c *Client) ([]*Repository, error) {
+				return c.ListMyRepos(&RepoListOptions{Type: "private"})
+			},
+			wantPath:  "/user/repos",
+			wantQuery: "type=private",
+		},
+		2: {
+			list: func(c *Client) ([]*Repository, error) {
+				return c.ListMyRepos(nil)
+			},
+			wantPath: "/user/repos",
+		},
+	}
+
+	for i, tt := range tests {
+		client := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+			if g, w := r.URL.Path, tt.wantPath; g != w {
+				t.Errorf("#%d: path: got %q want %q", i, g, w)
+			}
+			switch r.URL.Query().Get("page") {
+			case "":
+				if g, w := r.URL.RawQuery, tt.wantQuery; g != w {
+					t.Errorf("#%d: query: got %q want %q", i, g, w)
+				}
+				w.Header().Set("Link", fmt.Sprintf(`<https://api.github.com%s?page=2>; rel="next"`, tt.wantPath))
+				fmt.Fprint(w, `[{"full_name": "odeke-em/gcla"}]`)
+			case "2":
+				fmt.Fprint(w, `[{"full_name": "odeke-em/otils"}]`)
+			}
+		})
+
+		repos, err := tt.list(client)
+		if err != nil {
+			t.Errorf("#%d: %v", i, err)
+			continue
+		}
+		if g, w := len(repos), 2; g != w {
+			t.Errorf("#%d: got %d repositories want %d", i, g, w)
+			continue
+		}
+		if repos[0].FullName != "odeke-em/gcla" || repos[1].FullName != "odeke-em/otils" {
+			t.Errorf("#%d: unexpected order: %q, %q", i, repos[0].FullName, repos[1].FullName)
+		}
+	}
+}
+
+func TestListUserReposInvalidOptions(t *testing.T) {
+	client := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request to %q", r.URL)
+	})
+	invalid := []*RepoListOptions{
+		{Type: "forks"},
+		{Sort: "stars"},
+		{Direction: "up"},
+		// Only ListMyRepos supports listing private repositories.
+		{Type: "private"},
+	}
+	for i, opts := range invalid {
+		if _, err := client.ListUserRepos("odeke-em", opts); err == nil {
+			t.Errorf("#%d: expected an error for %+v", i, opts)
+		}
+	}
+	if _, err := client.ListUserRepos("", nil); err == nil {
+		t.Error("expected an error for an empty username")
+	}
+}
+
+func TestIsCollaborator(t *testing.T) {
+	client := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/orijtech/gcla/collaborators/odeke-em":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+
+	tests := [...]struct {
+		username string
+		want     bool
+	}{
+		0: {username: "odeke-em", want: true},
+		1: {username: "octocat", want: false},
+	}
+	for i, tt := range tests {
+		got, err := client.IsCollaborator("orijtech", "gcla", tt.username)
+		if err != nil {
+			t.Errorf("#%d: %v", i, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("#%d: got %v want %v", i, got, tt.want)
+		}
+	}
+	if _, err := client.IsCollaborator("orijtech", "gcla", ""); err == nil {
+		t.Error("expected an error for an empty username")
+	}
+}
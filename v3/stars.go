@@ -0,0 +1,77 @@
+// Copyright 2017 orijtech. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gcla
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// StarEvent is the payload sent when webhook "star" is fired, with
+// ActionCreated when owner/repo is starred and ActionDeleted when the
+// star is removed. Unlike the legacy "watch" event, it reports unstars.
+type StarEvent struct {
+	Action Action `json:"action,omitempty"`
+	// StarredAt is nil for ActionDeleted.
+	StarredAt *time.Time `json:"starred_at,omitempty"`
+
+	Repository   *Repository   `json:"repository,omitempty"`
+	Organization *Organization `json:"organization,omitempty"`
+	Sender       *User         `json:"sender,omitempty"`
+	Installation *Installation `json:"installation,omitempty"`
+}
+
+// Star stars owner/repo on behalf of the authenticated user.
+func (c *Client) Star(owner, repo string) error {
+	req, err := c.newStarredRequest("PUT", owner, repo)
+	if err != nil {
+		return err
+	}
+	_, _, err = c.doHTTPReq(req)
+	return err
+}
+
+// Unstar removes the authenticated user's star from owner/repo.
+func (c *Client) Unstar(owner, repo string) error {
+	req, err := c.newStarredRequest("DELETE", owner, repo)
+	if err != nil {
+		return err
+	}
+	_, _, err = c.doHTTPReq(req)
+	return err
+}
+
+// IsStarred reports whether the authenticated user has starred owner/repo.
+func (c *Client) IsStarred(owner, repo string) (bool, error) {
+	if owner == "" {
+		return false, errEmptyOwner
+	}
+	if repo == "" {
+		return false, errEmptyRepo
+	}
+	return c.boolRequest("GET", fmt.Sprintf("/user/starred/%s/%s", owner, repo))
+}
+
+func (c *Client) newStarredRequest(method, owner, repo string) (*http.Request, error) {
+	if owner == "" {
+		return nil, errEmptyOwner
+	}
+	if repo == "" {
+		return nil, errEmptyRepo
+	}
+	fullURL := fmt.Sprintf("%s/user/starred/%s/%s", baseURL, owner, repo)
+	return http.NewRequest(method, fullURL, nil)
+}
@@ -0,0 +1,130 @@
+// Copyright 2017 orijtech. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gcla
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestStarring(t *testing.T) {
+	starred := map[string]bool{"orijtech/otils": true}
+	client := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		const prefix = "/user/starred/"
+		if len(r.URL.Path) <= len(prefix) || r.URL.Path[:len(prefix)] != prefix {
+			http.Error(w, "unexpected path "+r.URL.Path, http.StatusBadRequest)
+			return
+		}
+		fullName := r.URL.Path[len(prefix):]
+		switch r.Method {
+		case "PUT":
+			starred[fullName] = true
+		case "DELETE":
+			delete(starred, fullName)
+		case "GET":
+			if !starred[fullName] {
+				http.Error(w, `{"message":"Not Found"}`, http.StatusNotFound)
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	isStarred := func(owner, repo string) bool {
+		ok, err := client.IsStarred(owner, repo)
+		if err != nil {
+			t.Fatalf("IsStarred(%q, %q): %v", owner, repo, err)
+		}
+		return ok
+	}
+
+	if !isStarred("orijtech", "otils") {
+		t.Errorf("expected orijtech/otils to be starred")
+	}
+	if isStarred("orijtech", "gcla") {
+		t.Errorf("expected orijtech/gcla not to be starred yet")
+	}
+	if err := client.Star("orijtech", "gcla"); err != nil {
+		t.Fatal(err)
+	}
+	if !isStarred("orijtech", "gcla") {
+		t.Errorf("expected orijtech/gcla to be starred after Star")
+	}
+	if err := client.Unstar("orijtech", "gcla"); err != nil {
+		t.Fatal(err)
+	}
+	if isStarred("orijtech", "gcla") {
+		t.Errorf("expected orijtech/gcla not to be starred after Unstar")
+	}
+	if err := client.Star("", "gcla"); err == nil {
+		t.Errorf("expected an error for an empty owner")
+	}
+}
+
+func TestIsStarredServerError(t *testing.T) {
+	client := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"message":"Server Error"}`, http.StatusInternalServerError)
+	})
+	if _, err := client.IsStarred("orijtech", "gcla"); err == nil {
+		t.Errorf("expected a non-nil error")
+	}
+}
+
+func TestParseStarEvent(t *testing.T) {
+	starredAt := time.Date(2023, time.March, 14, 17, 18, 26, 0, time.UTC)
+	tests := [...]struct {
+		payload       string
+		wantAction    Action
+		wantStarredAt *time.Time
+	}{
+		0: {
+			payload:       `{"action": "created", "starred_at": "2023-03-14T17:18:26Z", "repository": {"full_name": "orijtech/gcla"}, "sender": {"login": "odeke-em"}}`,
+			wantAction:    ActionCreated,
+			wantStarredAt: &starredAt,
+		},
+		1: {
+			payload:    `{"action": "deleted", "starred_at": null, "repository": {"full_name": "orijtech/gcla"}, "sender": {"login": "odeke-em"}}`,
+			wantAction: ActionDeleted,
+		},
+	}
+
+	for i, tt := range tests {
+		got, err := ParseWebhook(EventStar, []byte(tt.payload), DisallowUnknownFields())
+		if err != nil {
+			t.Errorf("#%d: %v", i, err)
+			continue
+		}
+		se, ok := got.(*StarEvent)
+		if !ok {
+			t.Errorf("#%d: got %T want *StarEvent", i, got)
+			continue
+		}
+		if g, w := se.Action, tt.wantAction; g != w {
+			t.Errorf("#%d: action: got %q want %q", i, g, w)
+		}
+		switch {
+		case tt.wantStarredAt == nil:
+			if se.StarredAt != nil {
+				t.Errorf("#%d: starred_at: got %v want nil", i, se.StarredAt)
+			}
+		case se.StarredAt == nil || !se.StarredAt.Equal(*tt.wantStarredAt):
+			t.Errorf("#%d: starred_at: got %v want %v", i, se.StarredAt, tt.wantStarredAt)
+		}
+		if se.Repository == nil || se.Repository.FullName != "orijtech/gcla" {
+			t.Errorf("#%d: repository: got %#v", i, se.Repository)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+// Copyright 2017 orijtech. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gcla
+
+// RequiredChecksPassed reports whether every context in required, such
+// as the required status checks of a branch's protection, has a status
+// in statuses whose State is StateSuccess. It also returns the contexts
+// that are missing from statuses or whose state is anything else, for
+// example StatePending or StateFailure, in the order of required.
+//
+// A context may have been reported more than once; statuses are expected
+// newest first, as GitHub lists them, so only the first one counts.
+func RequiredChecksPassed(statuses []*StatusEvent, required []string) (bool, []string) {
+	latest := make(map[string]State, len(statuses))
+	for _, status := range statuses {
+		if status == nil {
+			continue
+		}
+		if _, seen := latest[status.Context]; !seen {
+			latest[status.Context] = status.State
+		}
+	}
+
+	var notPassed []string
+	checked := make(map[string]bool, len(required))
+	for _, context := range required {
+		if checked[context] {
+			continue
+		}
+		checked[context] = true
+		if state, ok := latest[context]; !ok || state != StateSuccess {
+			notPassed = append(notPassed, context)
+		}
+	}
+	return len(notPassed) == 0, notPassed
+}
@@ -0,0 +1,90 @@
+// Copyright 2017 orijtech. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gcla
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRequiredChecksPassed(t *testing.T) {
+	required := []string{"ci/circleci", "continuous-integration/travis-ci", "cla/google"}
+	tests := [...]struct {
+		statuses      []*StatusEvent
+		required      []string
+		wantPassed    bool
+		wantNotPassed []string
+	}{
+		// All pass.
+		0: {
+			statuses: []*StatusEvent{
+				{Context: "ci/circleci", State: StateSuccess},
+				{Context: "continuous-integration/travis-ci", State: StateSuccess},
+				{Context: "cla/google", State: StateSuccess},
+				{Context: "codecov/patch", State: StateFailure},
+			},
+			required:   required,
+			wantPassed: true,
+		},
+		// One failing.
+		1: {
+			statuses: []*StatusEvent{
+				{Context: "ci/circleci", State: StateSuccess},
+				{Context: "continuous-integration/travis-ci", State: StateFailure},
+				{Context: "cla/google", State: StateSuccess},
+			},
+			required:      required,
+			wantNotPassed: []string{"continuous-integration/travis-ci"},
+		},
+		// One missing and one still pending.
+		2: {
+			statuses: []*StatusEvent{
+				{Context: "ci/circleci", State: StatePending},
+				{Context: "cla/google", State: StateSuccess},
+			},
+			required:      required,
+			wantNotPassed: []string{"ci/circleci", "continuous-integration/travis-ci"},
+		},
+		// Only the newest status of a context counts.
+		3: {
+			statuses: []*StatusEvent{
+				{Context: "ci/circleci", State: StateSuccess},
+				{Context: "ci/circleci", State: StateError},
+			},
+			required:   []string{"ci/circleci", "ci/circleci"},
+			wantPassed: true,
+		},
+		4: {
+			statuses: []*StatusEvent{
+				{Context: "ci/circleci", State: StateError},
+				{Context: "ci/circleci", State: StateSuccess},
+			},
+			required:      []string{"ci/circleci"},
+			wantNotPassed: []string{"ci/circleci"},
+		},
+		// Nothing required.
+		5: {statuses: nil, required: nil, wantPassed: true},
+	}
+
+	for i, tt := range tests {
+		passed, notPassed := RequiredChecksPassed(tt.statuses, tt.required)
+		if passed != tt.wantPassed {
+			t.Errorf("#%d: passed: got %v want %v", i, passed, tt.wantPassed)
+		}
+		if !reflect.DeepEqual(notPassed, tt.wantNotPassed) {
+			t.Errorf("#%d: not passed: got %q want %q", i, notPassed, tt.wantNotPassed)
+		}
+	}
+}
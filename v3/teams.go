@@ -0,0 +1,105 @@
+// Copyright 2017 orijtech. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gcla
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// RoleMaintainer is the team role that can add
+// and remove team members and edit the team.
+const RoleMaintainer = "maintainer"
+
+var errEmptyTeamID = errors.New("expecting a non-zero team ID")
+
+// ListTeamMembers returns the members of the team, following pagination.
+func (c *Client) ListTeamMembers(teamID uint64) ([]*User, error) {
+	if teamID == 0 {
+		return nil, errEmptyTeamID
+	}
+	fullURL := fmt.Sprintf("%s/teams/%d/members", baseURL, teamID)
+	var members []*User
+	err := c.getAllPages(fullURL, func(blob []byte) error {
+		var page []*User
+		if err := json.Unmarshal(blob, &page); err != nil {
+			return err
+		}
+		members = append(members, page...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+// ListTeamRepos returns the repositories that the
+// team has access to, following pagination.
+func (c *Client) ListTeamRepos(teamID uint64) ([]*Repository, error) {
+	if teamID == 0 {
+		return nil, errEmptyTeamID
+	}
+	fullURL := fmt.Sprintf("%s/teams/%d/repos", baseURL, teamID)
+	var repos []*Repository
+	err := c.getAllPages(fullURL, func(blob []byte) error {
+		var page []*Repository
+		if err := json.Unmarshal(blob, &page); err != nil {
+			return err
+		}
+		repos = append(repos, page...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return repos, nil
+}
+
+// AddTeamMembership adds username to the team or updates their role
+// if they are already a member. role is either RoleMember or RoleMaintainer
+// and if empty, GitHub defaults it to RoleMember.
+func (c *Client) AddTeamMembership(teamID uint64, username, role string) error {
+	if teamID == 0 {
+		return errEmptyTeamID
+	}
+	if username == "" {
+		return errEmptyUsername
+	}
+	switch role {
+	case "", RoleMember, RoleMaintainer:
+	default:
+		return fmt.Errorf("invalid role %q, expecting either %q or %q", role, RoleMember, RoleMaintainer)
+	}
+
+	blob, err := json.Marshal(&teamMembershipRequest{Role: role})
+	if err != nil {
+		return err
+	}
+	fullURL := fmt.Sprintf("%s/teams/%d/memberships/%s", baseURL, teamID, username)
+	req, err := http.NewRequest("PUT", fullURL, bytes.NewReader(blob))
+	if err != nil {
+		return err
+	}
+	_, _, err = c.doHTTPReq(req)
+	return err
+}
+
+type teamMembershipRequest struct {
+	Role string `json:"role,omitempty"`
+}
@@ -0,0 +1,114 @@
+// Copyright 2017 orijtech. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gcla
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestListTeamMembers(t *testing.T) {
+	client := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		if g, w := r.URL.Path, "/teams/99/members"; g != w {
+			t.Errorf("path: got %q want %q", g, w)
+		}
+		switch r.URL.Query().Get("page") {
+		case "":
+			w.Header().Set("Link", `<https://api.github.com/teams/99/members?page=2>; rel="next"`)
+			fmt.Fprint(w, `[{"login":"odeke-em","id":1}]`)
+		case "2":
+			fmt.Fprint(w, `[{"login":"rakyll","id":3,"type":"User"}]`)
+		}
+	})
+
+	members, err := client.ListTeamMembers(99)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(members) != 2 {
+		t.Fatalf("got %d members want 2", len(members))
+	}
+	if g, w := members[1].Username, "rakyll"; g != w {
+		t.Errorf("login: got %q want %q", g, w)
+	}
+	if g, w := members[1].Type, TypeUser; g != w {
+		t.Errorf("type: got %q want %q", g, w)
+	}
+}
+
+func TestListTeamRepos(t *testing.T) {
+	client := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		if g, w := r.URL.Path, "/teams/99/repos"; g != w {
+			t.Errorf("path: got %q want %q", g, w)
+		}
+		switch r.URL.Query().Get("page") {
+		case "":
+			w.Header().Set("Link", `<https://api.github.com/teams/99/repos?page=2>; rel="next", <https://api.github.com/teams/99/repos?page=3>; rel="last"`)
+			fmt.Fprint(w, `[{"id":10,"full_name":"orijtech/gcla"}]`)
+		case "2":
+			w.Header().Set("Link", `<https://api.github.com/teams/99/repos?page=3>; rel="next", <https://api.github.com/teams/99/repos?page=3>; rel="last"`)
+			fmt.Fprint(w, `[{"id":11,"full_name":"orijtech/otils"}]`)
+		case "3":
+			fmt.Fprint(w, `[{"id":12,"full_name":"orijtech/uber","private":true}]`)
+		}
+	})
+
+	repos, err := client.ListTeamRepos(99)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, repo := range repos {
+		names = append(names, repo.FullName)
+	}
+	if g, w := fmt.Sprint(names), "[orijtech/gcla orijtech/otils orijtech/uber]"; g != w {
+		t.Errorf("repos: got %s want %s", g, w)
+	}
+	if !repos[2].Private {
+		t.Errorf("expected the last repository to be private")
+	}
+
+	if _, err := client.ListTeamRepos(0); err == nil {
+		t.Errorf("expected an error for a zero team ID")
+	}
+}
+
+func TestAddTeamMembership(t *testing.T) {
+	client := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		if g, w := r.Method, "PUT"; g != w {
+			t.Errorf("method: got %q want %q", g, w)
+		}
+		if g, w := r.URL.Path, "/teams/99/memberships/odeke-em"; g != w {
+			t.Errorf("path: got %q want %q", g, w)
+		}
+		tmr := new(teamMembershipRequest)
+		if err := json.NewDecoder(r.Body).Decode(tmr); err != nil {
+			t.Errorf("decoding body: %v", err)
+		}
+		if g, w := tmr.Role, RoleMaintainer; g != w {
+			t.Errorf("role: got %q want %q", g, w)
+		}
+		fmt.Fprint(w, `{"url":"https://api.github.com/teams/99/memberships/odeke-em","role":"maintainer","state":"active"}`)
+	})
+
+	if err := client.AddTeamMembership(99, "odeke-em", RoleMaintainer); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.AddTeamMembership(99, "odeke-em", RoleAdmin); err == nil {
+		t.Errorf("expected an error for an invalid team role")
+	}
+}
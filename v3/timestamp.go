@@ -0,0 +1,48 @@
+// Copyright 2017 orijtech. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gcla
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"time"
+)
+
+// Timestamp is a time.Time that can be decoded from either an RFC3339
+// string or a number of seconds since the Unix epoch. GitHub mostly sends
+// the former but some webhook fields, notably the repository timestamps
+// and commit timestamps of "push" deliveries, are sent as the latter.
+type Timestamp struct {
+	time.Time
+}
+
+var _ json.Unmarshaler = (*Timestamp)(nil)
+
+func (ts *Timestamp) UnmarshalJSON(b []byte) error {
+	b = bytes.TrimSpace(b)
+	if bytes.Equal(b, []byte("null")) {
+		return nil
+	}
+	if len(b) > 0 && b[0] == '"' {
+		return ts.Time.UnmarshalJSON(b)
+	}
+	secs, err := strconv.ParseInt(string(b), 10, 64)
+	if err != nil {
+		return err
+	}
+	ts.Time = time.Unix(secs, 0).UTC()
+	return nil
+}
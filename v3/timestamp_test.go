@@ -0,0 +1,144 @@
+// Copyright 2017 orijtech. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gcla
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestTimestampUnmarshalJSON(t *testing.T) {
+	want := time.Date(2017, time.August, 21, 18, 30, 5, 0, time.UTC)
+
+	tests := [...]struct {
+		in      string
+		want    time.Time
+		wantErr bool
+	}{
+		0: {in: `"2017-08-21T18:30:05Z"`, want: want},
+		1: {in: `1503340205`, want: want},
+		2: {in: `"2017-08-21T11:30:05-07:00"`, want: want},
+		3: {in: `null`, want: time.Time{}},
+		4: {in: `"yesterday"`, wantErr: true},
+		5: {in: `true`, wantErr: true},
+	}
+
+	for i, tt := range tests {
+		ts := new(Timestamp)
+		err := json.Unmarshal([]byte(tt.in), ts)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("#%d: expected a non-nil error", i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("#%d: unexpected error: %v", i, err)
+			continue
+		}
+		if !ts.Equal(tt.want) {
+			t.Errorf("#%d: got %v want %v", i, ts.Time, tt.want)
+		}
+	}
+}
+
+func TestPushEventTimestamps(t *testing.T) {
+	payload := []byte(`{
+  "ref": "refs/heads/master",
+  "head_commit": {"id": "0d1a26e6", "timestamp": "2017-08-21T11:30:05-07:00"},
+  "repository": {
+    "full_name": "orijtech/gcla",
+    "created_at": 1503340205,
+    "updated_at": "2017-08-21T18:30:05Z",
+    "pushed_at": 1503340205
+  }
+}`)
+
+	got, err := ParseWebhook(EventPush, payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pe := got.(*PushEvent)
+	repo := pe.Repository
+	if !repo.CreatedAt.Equal(repo.UpdatedAt.Time) {
+		t.Errorf("created_at %v and updated_at %v should be the same instant", repo.CreatedAt, repo.UpdatedAt)
+	}
+	if !repo.PushedAt.Equal(pe.HeadCommit.Timestamp.Time) {
+		t.Errorf("pushed_at %v and the head commit's timestamp %v should be the same instant", repo.PushedAt, pe.HeadCommit.Timestamp)
+	}
+}
+
+func TestPushEventTimestampsStrict(t *testing.T) {
+	tests := [...]struct {
+		payload   string
+		wantField string
+	}{
+		// Unix seconds for the repository, RFC3339 for the commits.
+		0: {
+			payload: `{
+  "ref": "refs/heads/master",
+  "repository": {"full_name": "orijtech/gcla", "created_at": 1503340205, "pushed_at": 1503340205},
+  "head_commit": {"id": "0d1a26e67d8f5eaf1f6ba5c57fc3c7d91ac0fd1c", "timestamp": "2017-08-21T18:30:05Z"}
+}`,
+		},
+		1: {
+			payload: `{
+  "ref": "refs/heads/master",
+  "repository": {"full_name": "orijtech/gcla", "created_at": 1503340205, "brand_new_field": 1}
+}`,
+			wantField: "brand_new_field",
+		},
+		2: {
+			payload: `{
+  "ref": "refs/heads/master",
+  "head_commit": {"id": "0d1a26e67d8f5eaf1f6ba5c57fc3c7d91ac0fd1c", "timestamp": "2017-08-21T18:30:05Z", "brand_new_field": 1}
+}`,
+			wantField: "brand_new_field",
+		},
+		3: {
+			payload: `{
+  "ref": "refs/heads/master",
+  "commits": [{"id": "0d1a26e67d8f5eaf1f6ba5c57fc3c7d91ac0fd1c", "timestamp": 1503340205, "brand_new_field": 1}]
+}`,
+			wantField: "brand_new_field",
+		},
+	}
+
+	want := time.Date(2017, time.August, 21, 18, 30, 5, 0, time.UTC)
+	for i, tt := range tests {
+		got, err := ParseWebhook(EventPush, []byte(tt.payload), DisallowUnknownFields())
+		if tt.wantField != "" {
+			ufe, ok := err.(*UnknownFieldError)
+			if !ok {
+				t.Errorf("#%d: got err=%v (%T) want *UnknownFieldError", i, err, err)
+			} else if ufe.Field != tt.wantField {
+				t.Errorf("#%d: field: got %q want %q", i, ufe.Field, tt.wantField)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("#%d: unexpected error: %v", i, err)
+			continue
+		}
+		pe := got.(*PushEvent)
+		if pe.Repository == nil || pe.Repository.CreatedAt == nil || !pe.Repository.CreatedAt.Equal(want) {
+			t.Errorf("#%d: repository created_at: got %#v", i, pe.Repository)
+		}
+		if pe.HeadCommit == nil || pe.HeadCommit.Timestamp == nil || !pe.HeadCommit.Timestamp.Equal(want) {
+			t.Errorf("#%d: head commit timestamp: got %#v", i, pe.HeadCommit)
+		}
+	}
+}
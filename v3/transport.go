@@ -0,0 +1,89 @@
+// Copyright 2017 orijtech. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gcla
+
+import (
+	"net/http"
+	"time"
+)
+
+// Defaults for the transport that a Client uses unless
+// SetHTTPRoundTripper was invoked. Go's own defaults keep
+// only 2 idle connections per host which throttles servers
+// that make many concurrent API calls.
+const (
+	DefaultMaxIdleConnsPerHost = 32
+	DefaultIdleConnTimeout     = 90 * time.Second
+)
+
+type transportConfig struct {
+	maxIdleConnsPerHost int
+	idleConnTimeout     time.Duration
+	disableHTTP2        bool
+}
+
+// WithMaxIdleConnsPerHost sets the maximum number of idle keep-alive
+// connections that the default transport keeps to api.github.com.
+// It defaults to DefaultMaxIdleConnsPerHost.
+func WithMaxIdleConnsPerHost(n int) ClientOption {
+	return func(c *Client) {
+		c.transportCfg.maxIdleConnsPerHost = n
+	}
+}
+
+// WithIdleConnTimeout sets how long the default transport keeps
+// an idle keep-alive connection before closing it.
+// It defaults to DefaultIdleConnTimeout.
+func WithIdleConnTimeout(d time.Duration) ClientOption {
+	return func(c *Client) {
+		c.transportCfg.idleConnTimeout = d
+	}
+}
+
+// WithForceAttemptHTTP2 sets whether the default transport
+// attempts HTTP/2, which it does by default.
+func WithForceAttemptHTTP2(force bool) ClientOption {
+	return func(c *Client) {
+		c.transportCfg.disableHTTP2 = !force
+	}
+}
+
+func (tc transportConfig) newTransport() *http.Transport {
+	tr := http.DefaultTransport.(*http.Transport).Clone()
+	tr.MaxIdleConnsPerHost = DefaultMaxIdleConnsPerHost
+	if tc.maxIdleConnsPerHost > 0 {
+		tr.MaxIdleConnsPerHost = tc.maxIdleConnsPerHost
+	}
+	if tr.MaxIdleConns < tr.MaxIdleConnsPerHost {
+		tr.MaxIdleConns = tr.MaxIdleConnsPerHost
+	}
+	tr.IdleConnTimeout = DefaultIdleConnTimeout
+	if tc.idleConnTimeout > 0 {
+		tr.IdleConnTimeout = tc.idleConnTimeout
+	}
+	tr.ForceAttemptHTTP2 = !tc.disableHTTP2
+	return tr
+}
+
+// defaultTransport lazily creates the transport that is shared by all
+// the requests of c, so that their connections can be reused.
+func (c *Client) defaultTransport() *http.Transport {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.transport == nil {
+		c.transport = c.transportCfg.newTransport()
+	}
+	return c.transport
+}
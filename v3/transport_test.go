@@ -0,0 +1,80 @@
+// Copyright 2017 orijtech. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gcla
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestDefaultTransport(t *testing.T) {
+	tests := [...]struct {
+		opts         []ClientOption
+		wantIdle     int
+		wantTimeout  time.Duration
+		wantAttempt2 bool
+	}{
+		0: {wantIdle: DefaultMaxIdleConnsPerHost, wantTimeout: DefaultIdleConnTimeout, wantAttempt2: true},
+		1: {
+			opts: []ClientOption{
+				WithMaxIdleConnsPerHost(64),
+				WithIdleConnTimeout(30 * time.Second),
+				WithForceAttemptHTTP2(false),
+			},
+			wantIdle:     64,
+			wantTimeout:  30 * time.Second,
+			wantAttempt2: false,
+		},
+	}
+
+	for i, tt := range tests {
+		c := new(Client)
+		for _, opt := range tt.opts {
+			opt(c)
+		}
+		tr, ok := c.httpClient().Transport.(*http.Transport)
+		if !ok {
+			t.Errorf("#%d: got transport %T want *http.Transport", i, c.httpClient().Transport)
+			continue
+		}
+		if g, w := tr.MaxIdleConnsPerHost, tt.wantIdle; g != w {
+			t.Errorf("#%d: MaxIdleConnsPerHost: got %d want %d", i, g, w)
+		}
+		if tr.MaxIdleConns < tr.MaxIdleConnsPerHost {
+			t.Errorf("#%d: MaxIdleConns %d is below MaxIdleConnsPerHost %d", i, tr.MaxIdleConns, tr.MaxIdleConnsPerHost)
+		}
+		if g, w := tr.IdleConnTimeout, tt.wantTimeout; g != w {
+			t.Errorf("#%d: IdleConnTimeout: got %v want %v", i, g, w)
+		}
+		if g, w := tr.ForceAttemptHTTP2, tt.wantAttempt2; g != w {
+			t.Errorf("#%d: ForceAttemptHTTP2: got %v want %v", i, g, w)
+		}
+		// The transport is shared so that connections are reused.
+		if tr2 := c.httpClient().Transport; tr2 != tr {
+			t.Errorf("#%d: expected the same transport across requests", i)
+		}
+		if tr == http.DefaultTransport {
+			t.Errorf("#%d: http.DefaultTransport must not be modified", i)
+		}
+	}
+
+	// An explicitly set RoundTripper takes precedence.
+	c := new(Client)
+	c.SetHTTPRoundTripper(backend(nil))
+	if _, ok := c.httpClient().Transport.(*http.Transport); ok {
+		t.Errorf("expected the RoundTripper that was set")
+	}
+}
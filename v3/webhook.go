@@ -0,0 +1,448 @@
+// Copyright 2017 orijtech. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gcla
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Headers that GitHub sets on every webhook delivery.
+const (
+	HeaderEvent        = "X-GitHub-Event"
+	HeaderDelivery     = "X-GitHub-Delivery"
+	HeaderSignature256 = "X-Hub-Signature-256"
+)
+
+// WebhookOption configures how webhook deliveries are parsed by
+// ParseWebhook and how they are handled by a WebhookHandler.
+type WebhookOption func(*webhookConfig)
+
+type webhookConfig struct {
+	disallowUnknownFields bool
+	secrets               []string
+	allowedEvents         map[Event]bool
+	unsignedEvents        map[Event]bool
+	logger                Logger
+	// event is only set by WithEvent.
+	event Event
+}
+
+func newWebhookConfig(opts ...WebhookOption) *webhookConfig {
+	wc := new(webhookConfig)
+	for _, opt := range opts {
+		opt(wc)
+	}
+	return wc
+}
+
+// DisallowUnknownFields makes parsing fail with an *UnknownFieldError
+// whenever a payload carries a field that its destination struct doesn't
+// declare. By default such fields are silently dropped.
+func DisallowUnknownFields() WebhookOption {
+	return func(wc *webhookConfig) {
+		wc.disallowUnknownFields = true
+	}
+}
+
+// UnknownFieldError is returned when strict parsing encounters
+// a field that the package doesn't yet handle.
+type UnknownFieldError struct {
+	Event Event
+	Field string
+}
+
+func (ufe *UnknownFieldError) Error() string {
+	return fmt.Sprintf("unexpected field %q in %q payload", ufe.Field, ufe.Event)
+}
+
+// eventFactoriesMu guards eventFactories, which RegisterEvent extends.
+var eventFactoriesMu sync.RWMutex
+
+var eventFactories = map[Event]func() interface{}{
+	EventDependabotAlert:              func() interface{} { return new(DependabotAlertEvent) },
+	EventDiscussion:                   func() interface{} { return new(DiscussionEvent) },
+	EventDiscussionComment:            func() interface{} { return new(DiscussionCommentEvent) },
+	EventGitHubAppAuthorization:       func() interface{} { return new(GitHubAppAuthorizationEvent) },
+	EventInstallationTarget:           func() interface{} { return new(InstallationTargetEvent) },
+	EventIssueComment:                 func() interface{} { return new(IssueCommentEvent) },
+	EventIssues:                       func() interface{} { return new(IssuesEvent) },
+	EventMergeGroup:                   func() interface{} { return new(MergeGroupEvent) },
+	EventOrganization:                 func() interface{} { return new(OrganizationEvent) },
+	EventPing:                         func() interface{} { return new(PingEvent) },
+	EventPullRequest:                  func() interface{} { return new(PullRequestEvent) },
+	EventPullRequestReview:            func() interface{} { return new(PullRequestReviewEvent) },
+	EventPullRequestReviewComment:     func() interface{} { return new(PullRequestReviewCommentEvent) },
+	EventPush:                         func() interface{} { return new(PushEvent) },
+	EventRelease:                      func() interface{} { return new(ReleaseEvent) },
+	EventRepository:                   func() interface{} { return new(RepositoryEvent) },
+	EventRepositoryVulnerabilityAlert: func() interface{} { return new(RepositoryVulnerabilityAlertEvent) },
+	EventSecretScanningAlert:          func() interface{} { return new(SecretScanningAlertEvent) },
+	EventStar:                         func() interface{} { return new(StarEvent) },
+	EventStatus:                       func() interface{} { return new(StatusEvent) },
+	EventTeam:                         func() interface{} { return new(TeamEvent) },
+	EventTeamAdd:                      func() interface{} { return new(TeamAddEvent) },
+	EventWatch:                        func() interface{} { return new(WatchEvent) },
+}
+
+// RegisterEvent makes ParseWebhook, WebhookHandler and SupportedEvents
+// handle the event name, for example one that GitHub added after this
+// package was released, by decoding its payloads into the value that
+// factory returns, which must be a pointer. Registering a built-in event
+// replaces its decoder. It panics if name is empty or factory is nil.
+func RegisterEvent(name string, factory func() interface{}) {
+	if name == "" {
+		panic("gcla: RegisterEvent with an empty event name")
+	}
+	if factory == nil {
+		panic("gcla: RegisterEvent with a nil factory for event " + strconv.Quote(name))
+	}
+	eventFactoriesMu.Lock()
+	eventFactories[Event(name)] = factory
+	eventFactoriesMu.Unlock()
+}
+
+func lookupEventFactory(event Event) (func() interface{}, bool) {
+	eventFactoriesMu.RLock()
+	defer eventFactoriesMu.RUnlock()
+	factory, ok := eventFactories[event]
+	return factory, ok
+}
+
+func registeredEvents() []Event {
+	eventFactoriesMu.RLock()
+	defer eventFactoriesMu.RUnlock()
+	events := make([]Event, 0, len(eventFactories))
+	for event := range eventFactories {
+		events = append(events, event)
+	}
+	return events
+}
+
+// UnhandledEventError is returned when parsing an event
+// that has no decoder, see RegisterEvent.
+type UnhandledEventError struct {
+	Event Event
+}
+
+func (uee *UnhandledEventError) Error() string {
+	return fmt.Sprintf("unhandled event %q", uee.Event)
+}
+
+// ParseWebhook decodes payload into the struct that corresponds to event,
+// the value of a delivery's "X-GitHub-Event" header. The returned value is
+// a pointer such as *PushEvent or *PullRequestEvent.
+func ParseWebhook(event Event, payload []byte, opts ...WebhookOption) (interface{}, error) {
+	return newWebhookConfig(opts...).parse(event, payload)
+}
+
+func (wc *webhookConfig) parse(event Event, payload []byte) (interface{}, error) {
+	factory, ok := lookupEventFactory(event)
+	if !ok {
+		return nil, &UnhandledEventError{Event: event}
+	}
+	savPtr := factory()
+	if !wc.disallowUnknownFields {
+		if err := json.Unmarshal(payload, savPtr); err != nil {
+			return nil, err
+		}
+		return savPtr, nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(payload))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(savPtr); err != nil {
+		if field, ok := unknownFieldFromError(err); ok {
+			return nil, &UnknownFieldError{Event: event, Field: field}
+		}
+		return nil, err
+	}
+	// The decoder's strictness doesn't reach the types with their own
+	// UnmarshalJSON, such as Repository or PullRequest, which decode
+	// their fields with a fresh decoder, so check them separately.
+	var generic interface{}
+	if err := json.Unmarshal(payload, &generic); err != nil {
+		return nil, err
+	}
+	if field, ok := findUnknownField(generic, reflect.TypeOf(savPtr)); ok {
+		return nil, &UnknownFieldError{Event: event, Field: field}
+	}
+	return savPtr, nil
+}
+
+// findUnknownField walks v, a JSON value decoded into an interface{},
+// alongside t, the type that it was decoded into, and returns the first
+// key of an object that t, or the types nested in it, doesn't declare.
+// Keys are matched like encoding/json does, case-insensitively, and the
+// keys of each object are visited in sorted order.
+func findUnknownField(v interface{}, t reflect.Type) (string, bool) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch v := v.(type) {
+	case map[string]interface{}:
+		switch t.Kind() {
+		case reflect.Struct:
+			fields := jsonFields(t)
+			keys := make([]string, 0, len(v))
+			for key := range v {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				ft, ok := fields[strings.ToLower(key)]
+				if !ok {
+					return key, true
+				}
+				if field, ok := findUnknownField(v[key], ft); ok {
+					return field, true
+				}
+			}
+		case reflect.Map:
+			for _, value := range v {
+				if field, ok := findUnknownField(value, t.Elem()); ok {
+					return field, true
+				}
+			}
+		}
+	case []interface{}:
+		if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+			for _, elem := range v {
+				if field, ok := findUnknownField(elem, t.Elem()); ok {
+					return field, true
+				}
+			}
+		}
+	}
+	return "", false
+}
+
+// jsonFields returns the types of the fields of the struct type t keyed
+// by their lowercased JSON names, including those of embedded structs.
+func jsonFields(t reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				for embeddedName, embeddedType := range jsonFields(ft) {
+					if _, ok := fields[embeddedName]; !ok {
+						fields[embeddedName] = embeddedType
+					}
+				}
+				continue
+			}
+		}
+		if f.PkgPath != "" {
+			// Unexported fields aren't decoded.
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields[strings.ToLower(name)] = f.Type
+	}
+	return fields
+}
+
+// WithEvent records event, the value of a delivery's "X-GitHub-Event"
+// header, so that the typed parsers such as ParsePushEvent can check
+// that the payload is of the event they decode. ParseWebhook and
+// WebhookHandler ignore it.
+func WithEvent(event Event) WebhookOption {
+	return func(wc *webhookConfig) {
+		wc.event = event
+	}
+}
+
+// EventMismatchError is returned by the typed parsers
+// such as ParsePushEvent when WithEvent names another event.
+type EventMismatchError struct {
+	Want Event
+	Got  Event
+}
+
+func (eme *EventMismatchError) Error() string {
+	return fmt.Sprintf("expecting a %q payload, got %q", eme.Want, eme.Got)
+}
+
+func parseAs(want Event, payload []byte, opts []WebhookOption) (interface{}, error) {
+	wc := newWebhookConfig(opts...)
+	if wc.event != "" && wc.event != want {
+		return nil, &EventMismatchError{Want: want, Got: wc.event}
+	}
+	return wc.parse(want, payload)
+}
+
+// ParsePushEvent is like ParseWebhook for "push" payloads.
+func ParsePushEvent(payload []byte, opts ...WebhookOption) (*PushEvent, error) {
+	v, err := parseAs(EventPush, payload, opts)
+	if err != nil {
+		return nil, err
+	}
+	return v.(*PushEvent), nil
+}
+
+// ParsePullRequestEvent is like ParseWebhook for "pull_request" payloads.
+func ParsePullRequestEvent(payload []byte, opts ...WebhookOption) (*PullRequestEvent, error) {
+	v, err := parseAs(EventPullRequest, payload, opts)
+	if err != nil {
+		return nil, err
+	}
+	return v.(*PullRequestEvent), nil
+}
+
+// ParsePullRequestReviewEvent is like ParseWebhook
+// for "pull_request_review" payloads.
+func ParsePullRequestReviewEvent(payload []byte, opts ...WebhookOption) (*PullRequestReviewEvent, error) {
+	v, err := parseAs(EventPullRequestReview, payload, opts)
+	if err != nil {
+		return nil, err
+	}
+	return v.(*PullRequestReviewEvent), nil
+}
+
+// ParsePullRequestReviewCommentEvent is like ParseWebhook
+// for "pull_request_review_comment" payloads.
+func ParsePullRequestReviewCommentEvent(payload []byte, opts ...WebhookOption) (*PullRequestReviewCommentEvent, error) {
+	v, err := parseAs(EventPullRequestReviewComment, payload, opts)
+	if err != nil {
+		return nil, err
+	}
+	return v.(*PullRequestReviewCommentEvent), nil
+}
+
+// ParseIssueCommentEvent is like ParseWebhook for "issue_comment" payloads.
+func ParseIssueCommentEvent(payload []byte, opts ...WebhookOption) (*IssueCommentEvent, error) {
+	v, err := parseAs(EventIssueComment, payload, opts)
+	if err != nil {
+		return nil, err
+	}
+	return v.(*IssueCommentEvent), nil
+}
+
+// ParseIssuesEvent is like ParseWebhook for "issues" payloads.
+func ParseIssuesEvent(payload []byte, opts ...WebhookOption) (*IssuesEvent, error) {
+	v, err := parseAs(EventIssues, payload, opts)
+	if err != nil {
+		return nil, err
+	}
+	return v.(*IssuesEvent), nil
+}
+
+// ParseReleaseEvent is like ParseWebhook for "release" payloads.
+func ParseReleaseEvent(payload []byte, opts ...WebhookOption) (*ReleaseEvent, error) {
+	v, err := parseAs(EventRelease, payload, opts)
+	if err != nil {
+		return nil, err
+	}
+	return v.(*ReleaseEvent), nil
+}
+
+// ParseStatusEvent is like ParseWebhook for "status" payloads.
+func ParseStatusEvent(payload []byte, opts ...WebhookOption) (*StatusEvent, error) {
+	v, err := parseAs(EventStatus, payload, opts)
+	if err != nil {
+		return nil, err
+	}
+	return v.(*StatusEvent), nil
+}
+
+// ParsePingEvent is like ParseWebhook for "ping" payloads.
+func ParsePingEvent(payload []byte, opts ...WebhookOption) (*PingEvent, error) {
+	v, err := parseAs(EventPing, payload, opts)
+	if err != nil {
+		return nil, err
+	}
+	return v.(*PingEvent), nil
+}
+
+// encoding/json doesn't export a type for unknown field
+// errors so the field has to be recovered from the message.
+const unknownFieldPrefix = "json: unknown field "
+
+func unknownFieldFromError(err error) (string, bool) {
+	msg := err.Error()
+	if !strings.HasPrefix(msg, unknownFieldPrefix) {
+		return "", false
+	}
+	field, err := strconv.Unquote(strings.TrimPrefix(msg, unknownFieldPrefix))
+	if err != nil {
+		return "", false
+	}
+	return field, true
+}
+
+const signaturePrefix = "sha256="
+
+var (
+	errMissingSignature   = errors.New("missing delivery signature")
+	errMalformedSignature = errors.New("malformed delivery signature, expecting \"sha256=<hex digest>\"")
+	errSignatureMismatch  = errors.New("delivery signature does not match the payload")
+	errNoSecrets          = errors.New("expecting at least one secret")
+)
+
+// VerifySignature checks that signature, the value of a delivery's
+// "X-Hub-Signature-256" header, is the HMAC-SHA256 of payload keyed by
+// the hook's secret. While a secret is being rotated, pass both the new
+// and the old secret: the signature is accepted if it matches any of them.
+func VerifySignature(payload []byte, signature string, secrets ...string) error {
+	if signature == "" {
+		return errMissingSignature
+	}
+	if !strings.HasPrefix(signature, signaturePrefix) {
+		return errMalformedSignature
+	}
+	got, err := hex.DecodeString(strings.TrimPrefix(signature, signaturePrefix))
+	if err != nil {
+		return errMalformedSignature
+	}
+	if len(secrets) == 0 {
+		return errNoSecrets
+	}
+	matched := false
+	for _, secret := range secrets {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write(payload)
+		// Every secret is tried, even after a match, so that the time
+		// taken doesn't reveal which of them signed the payload.
+		if hmac.Equal(got, mac.Sum(nil)) {
+			matched = true
+		}
+	}
+	if !matched {
+		return errSignatureMismatch
+	}
+	return nil
+}
@@ -0,0 +1,733 @@
+// Copyright 2017 orijtech. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gcla
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"reflect"
+	"testing"
+)
+
+func TestParseWebhookUnknownFields(t *testing.T) {
+	payload := []byte(`{"ref":"refs/heads/master","head":"abc","brand_new_field":true}`)
+
+	tests := [...]struct {
+		opts      []WebhookOption
+		wantField string
+	}{
+		0: {},
+		1: {opts: []WebhookOption{DisallowUnknownFields()}, wantField: "brand_new_field"},
+	}
+
+	for i, tt := range tests {
+		got, err := ParseWebhook(EventPush, payload, tt.opts...)
+		if tt.wantField != "" {
+			ufe, ok := err.(*UnknownFieldError)
+			if !ok {
+				t.Errorf("#%d: got err=%v (%T) want *UnknownFieldError", i, err, err)
+				continue
+			}
+			if ufe.Field != tt.wantField {
+				t.Errorf("#%d: field: got %q want %q", i, ufe.Field, tt.wantField)
+			}
+			if ufe.Event != EventPush {
+				t.Errorf("#%d: event: got %q want %q", i, ufe.Event, EventPush)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("#%d: unexpected error: %v", i, err)
+			continue
+		}
+		pe, ok := got.(*PushEvent)
+		if !ok {
+			t.Errorf("#%d: got %T want *PushEvent", i, got)
+			continue
+		}
+		if pe.Ref != "refs/heads/master" {
+			t.Errorf("#%d: ref: got %q", i, pe.Ref)
+		}
+	}
+}
+
+func TestParseWebhookUnhandledEvent(t *testing.T) {
+	if _, err := ParseWebhook(Event("not_an_event"), []byte(`{}`)); err == nil {
+		t.Fatal("expected an error for an unhandled event")
+	}
+}
+
+func TestParseInstallationTargetEvent(t *testing.T) {
+	payload := []byte(`{
+  "action": "renamed",
+  "account": {"login": "orijtech-labs", "id": 1001, "type": "Organization"},
+  "changes": {"login": {"from": "orijtech"}, "slug": {"from": "orijtech"}},
+  "target_type": "Organization",
+  "installation": {"id": 42},
+  "sender": {"login": "odeke-em", "id": 7}
+}`)
+
+	got, err := ParseWebhook(EventInstallationTarget, payload, DisallowUnknownFields())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ite, ok := got.(*InstallationTargetEvent)
+	if !ok {
+		t.Fatalf("got %T want *InstallationTargetEvent", got)
+	}
+	if ite.Action != ActionRenamed {
+		t.Errorf("action: got %q want %q", ite.Action, ActionRenamed)
+	}
+	if ite.Account == nil || ite.Account.Username != "orijtech-labs" {
+		t.Errorf("account: got %#v", ite.Account)
+	}
+	if ite.Changes == nil || ite.Changes.Login == nil || ite.Changes.Login.From != "orijtech" {
+		t.Errorf("changes: got %#v", ite.Changes)
+	}
+	if ite.TargetType != TypeOrganization {
+		t.Errorf("target_type: got %q want %q", ite.TargetType, TypeOrganization)
+	}
+	if ite.Installation == nil || ite.Installation.ID != 42 {
+		t.Errorf("installation: got %#v", ite.Installation)
+	}
+}
+
+func TestParseGitHubAppAuthorizationEvent(t *testing.T) {
+	payload := []byte(`{"action":"revoked","sender":{"login":"odeke-em","id":7,"type":"User"}}`)
+
+	got, err := ParseWebhook(EventGitHubAppAuthorization, payload, DisallowUnknownFields())
+	if err != nil {
+		t.Fatal(err)
+	}
+	gae, ok := got.(*GitHubAppAuthorizationEvent)
+	if !ok {
+		t.Fatalf("got %T want *GitHubAppAuthorizationEvent", got)
+	}
+	if gae.Action != ActionRevoked {
+		t.Errorf("action: got %q want %q", gae.Action, ActionRevoked)
+	}
+	if gae.Sender == nil || gae.Sender.Username != "odeke-em" || gae.Sender.ID != 7 {
+		t.Errorf("sender: got %#v", gae.Sender)
+	}
+}
+
+func TestTypedParsers(t *testing.T) {
+	pe, err := ParsePushEvent([]byte(`{"ref": "refs/heads/master", "after": "aa218f56b14c9653891f9e74264a383fa43fefbd"}`), WithEvent(EventPush))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g, w := pe.Ref, "refs/heads/master"; g != w {
+		t.Errorf("ref: got %q want %q", g, w)
+	}
+
+	// Without WithEvent, the payload is trusted to be of the event.
+	pre, err := ParsePullRequestEvent([]byte(`{"action": "opened", "number": 42}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g, w := pre.Number, uint64(42); g != w {
+		t.Errorf("number: got %d want %d", g, w)
+	}
+
+	ping, err := ParsePingEvent([]byte(pingPayload), WithEvent(EventPing), DisallowUnknownFields())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g, w := ping.HookID, uint64(109948940); g != w {
+		t.Errorf("hook_id: got %d want %d", g, w)
+	}
+
+	// Options still apply.
+	_, err = ParsePushEvent([]byte(`{"ref": "refs/heads/master", "not_a_field": 1}`), DisallowUnknownFields())
+	if _, ok := err.(*UnknownFieldError); !ok {
+		t.Errorf("got err=%v (%T) want *UnknownFieldError", err, err)
+	}
+
+	_, err = ParsePushEvent([]byte(`{"action": "opened", "number": 42}`), WithEvent(EventPullRequest))
+	eme, ok := err.(*EventMismatchError)
+	if !ok {
+		t.Fatalf("got err=%v (%T) want *EventMismatchError", err, err)
+	}
+	if eme.Want != EventPush || eme.Got != EventPullRequest {
+		t.Errorf("got %#v", eme)
+	}
+}
+
+func TestVerifySignature(t *testing.T) {
+	payload := []byte(`{"zen":"Keep it logically awesome."}`)
+	signature := "sha256=" + sign(payload, "s3cr3t")
+
+	tests := [...]struct {
+		signature string
+		secrets   []string
+		wantErr   bool
+	}{
+		0: {signature: signature, secrets: []string{"s3cr3t"}},
+		1: {signature: signature, secrets: []string{"wrong"}, wantErr: true},
+		2: {signature: "", secrets: []string{"s3cr3t"}, wantErr: true},
+		3: {signature: "sha1=abcdef", secrets: []string{"s3cr3t"}, wantErr: true},
+		4: {signature: "sha256=not-hex", secrets: []string{"s3cr3t"}, wantErr: true},
+		// While rotating, a delivery signed with the old secret still verifies.
+		5: {signature: signature, secrets: []string{"n3w-s3cr3t", "s3cr3t"}},
+		6: {signature: "sha256=" + sign(payload, "n3w-s3cr3t"), secrets: []string{"n3w-s3cr3t", "s3cr3t"}},
+		7: {signature: signature, secrets: []string{"n3w-s3cr3t", "0ld-s3cr3t"}, wantErr: true},
+		8: {signature: signature, wantErr: true},
+	}
+
+	for i, tt := range tests {
+		err := VerifySignature(payload, tt.signature, tt.secrets...)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("#%d: expected a non-nil error", i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("#%d: unexpected error: %v", i, err)
+		}
+	}
+}
+
+func sign(payload []byte, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestParsePingEvent(t *testing.T) {
+	got, err := ParseWebhook(EventPing, []byte(pingPayload))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pe, ok := got.(*PingEvent)
+	if !ok {
+		t.Fatalf("got %T want *PingEvent", got)
+	}
+	if pe.Repository == nil || pe.Repository.FullName != "orijtech/gcla" {
+		t.Errorf("repository: got %#v", pe.Repository)
+	}
+}
+
+func TestParsePingEventAppHook(t *testing.T) {
+	payload := []byte(`{
+  "zen": "Keep it logically awesome.",
+  "hook_id": 209948940,
+  "hook": {
+    "type": "App",
+    "id": 209948940,
+    "name": "web",
+    "active": true,
+    "events": ["pull_request"],
+    "config": {"content_type": "json", "url": "https://hooks.orijtech.com/gcla"},
+    "app_slug": "gcla-bot",
+    "app_name": "gcla bot",
+    "app_owner": {"login": "orijtech", "id": 25489431, "type": "Organization"}
+  },
+  "sender": {"login": "odeke-em", "id": 1}
+}`)
+	got, err := ParseWebhook(EventPing, payload, DisallowUnknownFields())
+	if err != nil {
+		t.Fatal(err)
+	}
+	hook := got.(*PingEvent).Hook
+	if hook == nil {
+		t.Fatal("expected a non-nil hook")
+	}
+	if g, w := hook.Type, TypeApp; g != w {
+		t.Errorf("type: got %q want %q", g, w)
+	}
+	if g, w := hook.AppSlug, "gcla-bot"; g != w {
+		t.Errorf("app_slug: got %q want %q", g, w)
+	}
+	if g, w := hook.AppName, "gcla bot"; g != w {
+		t.Errorf("app_name: got %q want %q", g, w)
+	}
+	if hook.AppOwner == nil || hook.AppOwner.Username != "orijtech" {
+		t.Errorf("app_owner: got %#v", hook.AppOwner)
+	}
+}
+
+func TestParseOrgHookEvents(t *testing.T) {
+	const orgBlocks = `
+  "organization": {"login": "orijtech", "id": 25489431},
+  "sender": {"login": "odeke-em", "id": 7},
+  "installation": {"id": 42}`
+
+	tests := [...]struct {
+		event   Event
+		payload string
+	}{
+		0: {event: EventTeam, payload: `{"action": "created", "team": {"name": "gophers", "id": 3}, ` + orgBlocks + `}`},
+		1: {event: EventTeamAdd, payload: `{"team": {"name": "gophers", "id": 3}, "repository": {"full_name": "orijtech/gcla"}, ` + orgBlocks + `}`},
+		2: {event: EventOrganization, payload: `{"action": "member_added", "membership": {"state": "active", "role": "member"}, ` + orgBlocks + `}`},
+		3: {event: EventRepository, payload: `{"action": "created", "repository": {"full_name": "orijtech/gcla"}, ` + orgBlocks + `}`},
+	}
+
+	for i, tt := range tests {
+		got, err := ParseWebhook(tt.event, []byte(tt.payload), DisallowUnknownFields())
+		if err != nil {
+			t.Errorf("#%d: %v", i, err)
+			continue
+		}
+		var org *Organization
+		var sender *User
+		var inst *Installation
+		switch ev := got.(type) {
+		case *TeamEvent:
+			org, sender, inst = ev.Organization, ev.Sender, ev.Installation
+		case *TeamAddEvent:
+			org, sender, inst = ev.Organization, ev.Sender, ev.Installation
+		case *OrganizationEvent:
+			org, sender, inst = ev.Organization, ev.Sender, ev.Installation
+			if ev.Membership == nil || ev.Membership.State != StateActive {
+				t.Errorf("#%d: membership: got %#v", i, ev.Membership)
+			}
+		case *RepositoryEvent:
+			org, sender, inst = ev.Organization, ev.Sender, ev.Installation
+		default:
+			t.Errorf("#%d: unexpected type %T", i, got)
+			continue
+		}
+		if org == nil || org.Login != "orijtech" || org.ID != 25489431 {
+			t.Errorf("#%d: organization: got %#v", i, org)
+		}
+		if sender == nil || sender.Username != "odeke-em" {
+			t.Errorf("#%d: sender: got %#v", i, sender)
+		}
+		if inst == nil || inst.ID != 42 {
+			t.Errorf("#%d: installation: got %#v", i, inst)
+		}
+	}
+}
+
+func TestParseReleaseEventChanges(t *testing.T) {
+	payload := []byte(`{
+  "action": "edited",
+  "changes": {"body": {"from": "Initial notes."}, "name": {"from": "v3.0.0-rc1"}},
+  "release": {"id": 17372790, "tag_name": "v3.0.0", "name": "v3.0.0", "body": "Final notes."},
+  "repository": {"id": 35129377, "full_name": "orijtech/gcla"},
+  "sender": {"login": "odeke-em", "id": 7}
+}`)
+
+	got, err := ParseWebhook(EventRelease, payload, DisallowUnknownFields())
+	if err != nil {
+		t.Fatal(err)
+	}
+	re, ok := got.(*ReleaseEvent)
+	if !ok {
+		t.Fatalf("got %T want *ReleaseEvent", got)
+	}
+	if g, w := re.Action, ActionEdited; g != w {
+		t.Errorf("action: got %q want %q", g, w)
+	}
+	if re.Changes == nil {
+		t.Fatal("expected non-nil changes")
+	}
+	if re.Changes.Body == nil || re.Changes.Body.From != "Initial notes." {
+		t.Errorf("body: got %#v", re.Changes.Body)
+	}
+	if re.Changes.Name == nil || re.Changes.Name.From != "v3.0.0-rc1" {
+		t.Errorf("name: got %#v", re.Changes.Name)
+	}
+	if re.Changes.TagName != nil {
+		t.Errorf("unexpected tag_name change %#v", re.Changes.TagName)
+	}
+	if re.Release == nil || re.Release.Body != "Final notes." {
+		t.Errorf("release: got %#v", re.Release)
+	}
+}
+
+func TestParseTeamEventChanges(t *testing.T) {
+	tests := [...]struct {
+		payload         string
+		wantPrivacyFrom Privacy
+		wantPermsFrom   *Permissions
+	}{
+		0: {
+			payload: `{
+  "action": "edited",
+  "changes": {"privacy": {"from": "secret"}},
+  "team": {"name": "gophers", "id": 3, "privacy": "closed"},
+  "organization": {"login": "orijtech", "id": 25489431},
+  "sender": {"login": "odeke-em", "id": 7}
+}`,
+			wantPrivacyFrom: PrivacySecret,
+		},
+		1: {
+			payload: `{
+  "action": "edited",
+  "changes": {"repository": {"permissions": {"from": {"admin": false, "pull": true, "push": false}}}},
+  "team": {"name": "gophers", "id": 3, "privacy": "closed"},
+  "repository": {"id": 35129377, "full_name": "orijtech/gcla"},
+  "organization": {"login": "orijtech", "id": 25489431},
+  "sender": {"login": "odeke-em", "id": 7}
+}`,
+			wantPermsFrom: &Permissions{Pull: true},
+		},
+	}
+
+	for i, tt := range tests {
+		got, err := ParseWebhook(EventTeam, []byte(tt.payload), DisallowUnknownFields())
+		if err != nil {
+			t.Errorf("#%d: %v", i, err)
+			continue
+		}
+		te := got.(*TeamEvent)
+		if g, w := te.Action, ActionEdited; g != w {
+			t.Errorf("#%d: action: got %q want %q", i, g, w)
+		}
+		if te.Team == nil || te.Team.Privacy != PrivacyClosed {
+			t.Errorf("#%d: team: got %#v", i, te.Team)
+		}
+		if te.Changes == nil {
+			t.Errorf("#%d: expected non-nil changes", i)
+			continue
+		}
+		var privacyFrom Privacy
+		if te.Changes.Privacy != nil {
+			privacyFrom = Privacy(te.Changes.Privacy.From)
+		}
+		if g, w := privacyFrom, tt.wantPrivacyFrom; g != w {
+			t.Errorf("#%d: privacy from: got %q want %q", i, g, w)
+		}
+		var permsFrom *Permissions
+		if rc := te.Changes.Repository; rc != nil && rc.Permissions != nil {
+			permsFrom = rc.Permissions.From
+		}
+		if !reflect.DeepEqual(permsFrom, tt.wantPermsFrom) {
+			t.Errorf("#%d: permissions from: got %#v want %#v", i, permsFrom, tt.wantPermsFrom)
+		}
+	}
+}
+
+func TestParseRepositoryEventChanges(t *testing.T) {
+	tests := [...]struct {
+		payload        string
+		wantAction     Action
+		wantNameFrom   string
+		wantOwnerFrom  string
+		wantNilChanges bool
+	}{
+		0: {
+			payload: `{
+  "action": "renamed",
+  "changes": {"repository": {"name": {"from": "gcla-old"}}},
+  "repository": {"id": 35129377, "name": "gcla", "full_name": "orijtech/gcla"},
+  "sender": {"login": "odeke-em", "id": 7}
+}`,
+			wantAction:   ActionRenamed,
+			wantNameFrom: "gcla-old",
+		},
+		1: {
+			payload: `{
+  "action": "transferred",
+  "changes": {"owner": {"from": {"user": {"login": "odeke-em", "id": 7}}}},
+  "repository": {"id": 35129377, "name": "gcla", "full_name": "orijtech/gcla"},
+  "sender": {"login": "odeke-em", "id": 7}
+}`,
+			wantAction:    ActionTransferred,
+			wantOwnerFrom: "odeke-em",
+		},
+		2: {
+			payload: `{
+  "action": "archived",
+  "repository": {"id": 35129377, "name": "gcla", "full_name": "orijtech/gcla", "archived": true},
+  "sender": {"login": "odeke-em", "id": 7}
+}`,
+			wantAction:     ActionArchived,
+			wantNilChanges: true,
+		},
+	}
+
+	for i, tt := range tests {
+		got, err := ParseWebhook(EventRepository, []byte(tt.payload), DisallowUnknownFields())
+		if err != nil {
+			t.Errorf("#%d: %v", i, err)
+			continue
+		}
+		re := got.(*RepositoryEvent)
+		if g, w := re.Action, tt.wantAction; g != w {
+			t.Errorf("#%d: action: got %q want %q", i, g, w)
+		}
+		if tt.wantNilChanges {
+			if re.Changes != nil {
+				t.Errorf("#%d: unexpected changes %#v", i, re.Changes)
+			}
+			continue
+		}
+		if re.Changes == nil {
+			t.Errorf("#%d: expected non-nil changes", i)
+			continue
+		}
+		var nameFrom string
+		if rc := re.Changes.Repository; rc != nil && rc.Name != nil {
+			nameFrom = rc.Name.From
+		}
+		if g, w := nameFrom, tt.wantNameFrom; g != w {
+			t.Errorf("#%d: name from: got %q want %q", i, g, w)
+		}
+		var ownerFrom string
+		if oc := re.Changes.Owner; oc != nil && oc.From != nil && oc.From.User != nil {
+			ownerFrom = oc.From.User.Username
+		}
+		if g, w := ownerFrom, tt.wantOwnerFrom; g != w {
+			t.Errorf("#%d: owner from: got %q want %q", i, g, w)
+		}
+	}
+}
+
+func TestParsePullRequestEventDraftActions(t *testing.T) {
+	tests := [...]struct {
+		payload   string
+		want      Action
+		wantDraft bool
+	}{
+		0: {
+			payload: `{"action": "ready_for_review", "number": 12, "pull_request": {"number": 12, "draft": false}}`,
+			want:    ActionReadyForReview,
+		},
+		1: {
+			payload:   `{"action": "converted_to_draft", "number": 12, "pull_request": {"number": 12, "draft": true}}`,
+			want:      ActionConvertedToDraft,
+			wantDraft: true,
+		},
+	}
+
+	for i, tt := range tests {
+		pre, err := ParsePullRequestEvent([]byte(tt.payload))
+		if err != nil {
+			t.Errorf("#%d: %v", i, err)
+			continue
+		}
+		if g, w := pre.Action, tt.want; g != w {
+			t.Errorf("#%d: action: got %q want %q", i, g, w)
+		}
+		if g, w := pre.PullRequest.IsDraft(), tt.wantDraft; g != w {
+			t.Errorf("#%d: draft: got %v want %v", i, g, w)
+		}
+	}
+}
+
+func TestParsePullRequestEventReviewRequested(t *testing.T) {
+	payload := []byte(`{
+  "action": "review_requested",
+  "number": 12,
+  "pull_request": {
+    "number": 12,
+    "state": "open",
+    "requested_reviewers": [{"login": "odeke-em", "id": 7}, {"login": "jadekler", "id": 8}],
+    "requested_teams": [{"name": "Gophers", "id": 3, "slug": "gophers"}]
+  },
+  "requested_team": {"name": "Gophers", "id": 3, "slug": "gophers"},
+  "repository": {"id": 35129377, "full_name": "orijtech/gcla"},
+  "sender": {"login": "odeke-em", "id": 7}
+}`)
+
+	pre, err := ParsePullRequestEvent(payload, DisallowUnknownFields())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g, w := pre.Action, ActionReviewRequested; g != w {
+		t.Errorf("action: got %q want %q", g, w)
+	}
+	pr := pre.PullRequest
+	if g, w := len(pr.RequestedReviewers), 2; g != w {
+		t.Fatalf("got %d requested reviewers want %d", g, w)
+	}
+	if g, w := pr.RequestedReviewers[1].Username, "jadekler"; g != w {
+		t.Errorf("second reviewer: got %q want %q", g, w)
+	}
+	if g, w := len(pr.RequestedTeams), 1; g != w {
+		t.Fatalf("got %d requested teams want %d", g, w)
+	}
+	if g, w := pr.RequestedTeams[0].Slug, "gophers"; g != w {
+		t.Errorf("team: got %q want %q", g, w)
+	}
+	if pre.RequestedTeam == nil || pre.RequestedTeam.ID != 3 {
+		t.Errorf("requested team: got %#v", pre.RequestedTeam)
+	}
+	if pre.RequestedReviewer != nil {
+		t.Errorf("unexpected requested reviewer %#v", pre.RequestedReviewer)
+	}
+}
+
+type customEvent struct {
+	Action Action `json:"action,omitempty"`
+	Tier   string `json:"tier,omitempty"`
+	Sender *User  `json:"sender,omitempty"`
+}
+
+func TestRegisterEvent(t *testing.T) {
+	const name = "gcla_test_custom"
+	if _, err := ParseWebhook(name, []byte(`{}`)); err == nil {
+		t.Fatalf("expected %q to be unhandled before registering it", name)
+	}
+
+	RegisterEvent(name, func() interface{} { return new(customEvent) })
+	defer func() {
+		eventFactoriesMu.Lock()
+		delete(eventFactories, name)
+		eventFactoriesMu.Unlock()
+	}()
+
+	got, err := ParseWebhook(name, []byte(`{"action": "created", "tier": "gold", "sender": {"login": "odeke-em", "id": 7}}`), DisallowUnknownFields())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ce, ok := got.(*customEvent)
+	if !ok {
+		t.Fatalf("got %T want *customEvent", got)
+	}
+	if ce.Action != ActionCreated || ce.Tier != "gold" || ce.Sender == nil || ce.Sender.Username != "odeke-em" {
+		t.Errorf("unexpected event %#v", ce)
+	}
+
+	found := false
+	for _, event := range new(Client).SupportedEvents() {
+		if event == name {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected %q among the supported events", name)
+	}
+	if err := validateSupported([]Event{name}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestRegisterEventPanics(t *testing.T) {
+	tests := [...]struct {
+		name    string
+		factory func() interface{}
+	}{
+		0: {name: "", factory: func() interface{} { return new(customEvent) }},
+		1: {name: "gcla_test_custom", factory: nil},
+	}
+	for i, tt := range tests {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("#%d: expected a panic", i)
+				}
+			}()
+			RegisterEvent(tt.name, tt.factory)
+		}()
+	}
+}
+
+func TestParseWebhookUnknownFieldsInRepository(t *testing.T) {
+	tests := [...]struct {
+		payload   string
+		wantField string
+	}{
+		0: {
+			payload:   `{"ref": "refs/heads/master", "repository": {"full_name": "orijtech/gcla", "visibility": "public", "brand_new_field": 1}}`,
+			wantField: "brand_new_field",
+		},
+		// Fields of the objects nested in a repository are checked too.
+		1: {
+			payload:   `{"ref": "refs/heads/master", "repository": {"full_name": "orijtech/gcla", "license": {"key": "apache-2.0", "brand_new_field": 1}}}`,
+			wantField: "brand_new_field",
+		},
+		2: {
+			payload: `{"ref": "refs/heads/master", "repository": {"full_name": "orijtech/gcla", "private": true, "license": {"key": "apache-2.0"}}}`,
+		},
+	}
+
+	for i, tt := range tests {
+		got, err := ParseWebhook(EventPush, []byte(tt.payload), DisallowUnknownFields())
+		if tt.wantField == "" {
+			if err != nil {
+				t.Errorf("#%d: unexpected error: %v", i, err)
+				continue
+			}
+			// The normalization of Repository.UnmarshalJSON still applies.
+			if repo := got.(*PushEvent).Repository; repo == nil || repo.Visibility != VisibilityPrivate {
+				t.Errorf("#%d: repository: got %#v", i, repo)
+			}
+			continue
+		}
+		ufe, ok := err.(*UnknownFieldError)
+		if !ok {
+			t.Errorf("#%d: got err=%v (%T) want *UnknownFieldError", i, err, err)
+			continue
+		}
+		if g, w := ufe.Field, tt.wantField; g != w {
+			t.Errorf("#%d: field: got %q want %q", i, g, w)
+		}
+	}
+
+	// Without strict mode, the unknown fields are dropped.
+	if _, err := ParseWebhook(EventPush, []byte(tests[0].payload)); err != nil {
+		t.Errorf("lenient: unexpected error: %v", err)
+	}
+}
+
+func TestParseWebhookUnknownFieldsInPullRequestAndIssue(t *testing.T) {
+	tests := [...]struct {
+		event     Event
+		payload   string
+		wantField string
+	}{
+		0: {
+			event:     EventPullRequest,
+			payload:   `{"action": "opened", "number": 12, "pull_request": {"number": 12, "assignees": [], "brand_new_field": 1}}`,
+			wantField: "brand_new_field",
+		},
+		1: {
+			event:     EventPullRequest,
+			payload:   `{"action": "opened", "number": 12, "pull_request": {"number": 12, "head": {"ref": "feature", "brand_new_field": 1}}}`,
+			wantField: "brand_new_field",
+		},
+		2: {
+			event:     EventIssueComment,
+			payload:   `{"action": "created", "issue": {"number": 7, "assignees": [], "brand_new_field": 1}, "comment": {"id": 1}}`,
+			wantField: "brand_new_field",
+		},
+		3: {
+			event:   EventPullRequest,
+			payload: `{"action": "opened", "number": 12, "pull_request": {"number": 12, "assignees": [{"login": "odeke-em", "id": 7}]}}`,
+		},
+	}
+
+	for i, tt := range tests {
+		got, err := ParseWebhook(tt.event, []byte(tt.payload), DisallowUnknownFields())
+		if tt.wantField == "" {
+			if err != nil {
+				t.Errorf("#%d: unexpected error: %v", i, err)
+				continue
+			}
+			// The normalization of PullRequest.UnmarshalJSON still applies.
+			if pr := got.(*PullRequestEvent).PullRequest; pr.Assignee == nil || pr.Assignee.Username != "odeke-em" {
+				t.Errorf("#%d: assignee: got %#v", i, pr.Assignee)
+			}
+			continue
+		}
+		ufe, ok := err.(*UnknownFieldError)
+		if !ok {
+			t.Errorf("#%d: got err=%v (%T) want *UnknownFieldError", i, err, err)
+			continue
+		}
+		if g, w := ufe.Field, tt.wantField; g != w {
+			t.Errorf("#%d: field: got %q want %q", i, g, w)
+		}
+		if g, w := ufe.Event, tt.event; g != w {
+			t.Errorf("#%d: event: got %q want %q", i, g, w)
+		}
+	}
+}